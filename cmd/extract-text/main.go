@@ -2,14 +2,41 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"time"
 
+	"ortodoxa-gudstjanster/internal/dateutil"
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/scraper"
 )
 
+// parseFromMonth parses the command-line flags and returns the month the
+// printed text should start at. It defaults to the month of now.
+func parseFromMonth(args []string, now time.Time) (time.Month, error) {
+	fs := flag.NewFlagSet("extract-text", flag.ContinueOnError)
+	name := fs.String("from-month", dateutil.MonthName(now.Month()), "Swedish name of the month to start at, e.g. Mars")
+	if err := fs.Parse(args); err != nil {
+		return 0, err
+	}
+	month, ok := dateutil.ParseMonth(*name)
+	if !ok {
+		return 0, fmt.Errorf("-from-month must be a Swedish month name, got %q", *name)
+	}
+	return month, nil
+}
+
 func main() {
+	logging.Setup()
+	month, err := parseFromMonth(os.Args[1:], time.Now())
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(2)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -19,5 +46,5 @@ func main() {
 		os.Exit(1)
 	}
 
-	fmt.Println(text)
+	fmt.Println(scraper.RyskaScheduleTextFromMonth(text, month))
 }
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFromMonth(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		args    []string
+		want    time.Month
+		wantErr bool
+	}{
+		{nil, time.October, false},
+		{[]string{"-from-month", "mars"}, time.March, false},
+		{[]string{"-from-month=December"}, time.December, false},
+		{[]string{"-from-month", "March"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFromMonth(tt.args, now)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseFromMonth(%v) = %v, %v; want %v, error %v", tt.args, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSharpDrop(t *testing.T) {
+	tests := []struct {
+		name          string
+		existingCount int
+		newCount      int
+		ratio         float64
+		want          bool
+	}{
+		{"unchanged", 40, 40, 0.5, false},
+		{"small drop", 40, 30, 0.5, false},
+		{"exactly half", 40, 20, 0.5, false},
+		{"below half", 40, 19, 0.5, true},
+		{"nothing stored", 0, 0, 0.5, false},
+		{"growth", 10, 50, 0.5, false},
+		{"stricter ratio", 40, 30, 0.8, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSharpDrop(tt.existingCount, tt.newCount, tt.ratio); got != tt.want {
+				t.Errorf("isSharpDrop(%d, %d, %v) = %v, want %v", tt.existingCount, tt.newCount, tt.ratio, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCountDropWarning(t *testing.T) {
+	subject, body := buildCountDropWarning("Finska", 40, 15, 0.5, []string{"parsed 15 rows"})
+	if !strings.Contains(subject, "Finska") || !strings.Contains(subject, "15") {
+		t.Errorf("subject = %q, want scraper name and new count", subject)
+	}
+	if !strings.Contains(body, "< 50% of stored count (40)") {
+		t.Errorf("body should state the rule, got:\n%s", body)
+	}
+	if !strings.Contains(body, "parsed 15 rows") {
+		t.Errorf("body should include scraper notes, got:\n%s", body)
+	}
+}
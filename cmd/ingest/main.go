@@ -5,26 +5,42 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"ortodoxa-gudstjanster/internal/email"
 	"ortodoxa-gudstjanster/internal/firestore"
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/notify"
 	"ortodoxa-gudstjanster/internal/scraper"
 	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/tracing"
 	"ortodoxa-gudstjanster/internal/umap"
 	"ortodoxa-gudstjanster/internal/vision"
+	"ortodoxa-gudstjanster/internal/web"
 )
 
 func main() {
+	logger := logging.Setup()
+	onlyFailed := flag.Bool("only-failed", false, "re-run only the scrapers that failed in the last ingest run")
+	flag.Parse()
+
 	ctx := context.Background()
 
+	// Export traces of the scrapers and OpenAI calls (optional)
+	shutdownTracing, err := tracing.Setup("ortodoxa-ingest")
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
 	// Required environment variables
 	projectID := os.Getenv("GCP_PROJECT_ID")
 	if projectID == "" {
@@ -115,56 +131,80 @@ func main() {
 	}
 
 	// Initialize SMTP for alerting (optional)
-	var smtpConfig *email.SMTPConfig
+	var mailer email.Sender
+	alertTo := email.ParseRecipients(os.Getenv("SMTP_TO"))
 	if smtpHost := strings.TrimSpace(os.Getenv("SMTP_HOST")); smtpHost != "" {
-		smtpConfig = &email.SMTPConfig{
+		smtpUser := strings.TrimSpace(os.Getenv("SMTP_USER"))
+		mailer = &email.SMTPSender{
 			Host:     smtpHost,
 			Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
-			User:     strings.TrimSpace(os.Getenv("SMTP_USER")),
+			User:     smtpUser,
 			Password: strings.TrimSpace(os.Getenv("SMTP_PASS")),
-			To:       strings.TrimSpace(os.Getenv("SMTP_TO")),
 		}
-		log.Printf("SMTP configured for alerting: %s -> %s", smtpConfig.User, smtpConfig.To)
+		log.Printf("SMTP configured for alerting: %s -> %s", smtpUser, strings.Join(alertTo, ", "))
 	} else {
 		log.Printf("SMTP not configured (alerts disabled)")
 	}
 
+	// Schedule change notifications (optional): by webhook and/or by email
+	// through the alerting SMTP settings
+	notifier := &notify.Notifier{WebhookURL: strings.TrimSpace(os.Getenv("NOTIFY_WEBHOOK_URL"))}
+	if os.Getenv("NOTIFY_EMAIL") == "1" {
+		notifier.Mail, notifier.MailTo = mailer, alertTo
+	}
+	notifyChanges := notifier.WebhookURL != "" || notifier.Mail != nil
+
+	// Warn (but still replace) when a source's count drops below this share of the stored count
+	dropAlertRatio := defaultDropAlertRatio
+	if v := strings.TrimSpace(os.Getenv("COUNT_DROP_ALERT_RATIO")); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			log.Fatalf("Invalid COUNT_DROP_ALERT_RATIO %q: want a number in (0, 1]", v)
+		}
+		dropAlertRatio = ratio
+	}
+
 	// Initialize scraper registry and register all scrapers
-	registry := scraper.NewRegistry()
-	registry.Register(scraper.NewFinskaScraper(""))
-	gomosScraper := scraper.NewGomosScraper(gcsStore, visionClient)
-	if uploadReader != nil {
-		gomosScraper.SetUploadSource(uploadReader, "st-georgios/")
-	}
-	registry.Register(gomosScraper)
-	registry.Register(scraper.NewHeligaAnnaScraper())
-	registry.Register(scraper.NewRyskaScraper(gcsStore, visionClient))
-	registry.Register(scraper.NewHeligeSergijScraper(gcsStore, visionClient))
-registry.Register(scraper.NewGCalendarScraper())
-	registry.Register(scraper.NewGCalendarManualScraper())
-	registry.Register(scraper.NewUppstandelseScraper())
-	registry.Register(scraper.NewRomanianScraper())
-	registry.Register(scraper.NewSommarlagerScraper(gcsStore, visionClient))
-	if uploadReader != nil {
-		uploadParishes := map[string]scraper.UploadParishInfo{
-			"helige-giorgis": {
-				Name:      "Helige Giorgis",
-				Location:  "Helige Giorgis, Kyrkvägen 27, 182 74 Stocksund",
-				SourceURL: "https://www.facebook.com/share/17oMW5H9UN/?mibextid=wwXIfr",
-				SourceName: "Facebook",
-			},
-		}
-		registry.Register(scraper.NewUploadsScraper(gcsStore, visionClient, uploadReader, gcsUploadBucket, uploadParishes))
+	var definitions []scraper.ParishDefinition
+	if definitionsPath := os.Getenv("PARISH_DEFINITIONS"); definitionsPath != "" {
+		definitions, err = scraper.LoadDefinitions(definitionsPath)
+		if err != nil {
+			log.Fatalf("Failed to load parish definitions: %v", err)
+		}
+		log.Printf("Loaded %d parish definitions from %s", len(definitions), definitionsPath)
 	}
+	registry := scraper.NewDefaultRegistry(scraper.Dependencies{
+		Store:        gcsStore,
+		Vision:       visionClient,
+		UploadReader: uploadReader,
+		UploadBucket: gcsUploadBucket,
+		Definitions:  definitions,
+	})
+	registry.SetLogger(logger)
 
 	// Generate batch ID for this ingestion run
-	batchID := time.Now().UTC().Format("20060102-150405")
+	batchID := time.Now().UTC().Format(firestore.BatchIDLayout)
 	log.Printf("Starting ingestion with batch ID: %s", batchID)
 
 	today := time.Now().Format("2006-01-02")
 
 	// Pass 1: Run scrapers and collect accepted results
 	scrapers := registry.Scrapers()
+	if *onlyFailed {
+		summary, err := fsClient.GetLatestBatchSummary(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load last batch summary: %v", err)
+		}
+		if summary == nil {
+			log.Fatal("-only-failed: no batch summary from a previous run")
+		}
+		scrapers = selectFailedScrapers(scrapers, summary)
+		log.Printf("-only-failed: re-running %d scraper(s) that failed in batch %s", len(scrapers), summary.BatchID)
+		if len(scrapers) == 0 {
+			fmt.Println("Nothing to re-run")
+			return
+		}
+	}
 	var accepted []acceptedResult
 	failedScrapers := 0
 	var scraperErrors []scraperFailure // collected for email alert
@@ -173,7 +213,7 @@ registry.Register(scraper.NewGCalendarScraper())
 		scraperName := s.Name()
 		log.Printf("Running scraper: %s", scraperName)
 
-		services, err := s.Fetch(ctx)
+		services, err := scraper.Fetch(ctx, s)
 
 		// Collect diagnostic notes if the scraper supports them.
 		var fetchNotes []string
@@ -198,7 +238,8 @@ registry.Register(scraper.NewGCalendarScraper())
 					newCount++
 				}
 			}
-			existingCount, err := fsClient.CountFutureServicesForScraper(ctx, scraperName)
+			existing, err := fsClient.GetFutureServicesForScraper(ctx, scraperName)
+			existingCount := len(existing)
 			if err != nil {
 				log.Printf("WARNING: Failed to count existing services for %s: %v", scraperName, err)
 				// Proceed with replacement if we can't count
@@ -210,9 +251,9 @@ registry.Register(scraper.NewGCalendarScraper())
 				gcsPath := saveDiagnostics(gcsStore, scraperName, services)
 
 				// Send alert email if SMTP is configured
-				if smtpConfig != nil {
+				if mailer != nil {
 					subject, body := buildCountDecreaseAlert(scraperName, existingCount, newCount, gcsBucket, gcsPath, services, fetchNotes)
-					if err := smtpConfig.Send(subject, body); err != nil {
+					if err := mailer.Send(subject, body, alertTo); err != nil {
 						log.Printf("ERROR: Failed to send alert email for %s: %v", scraperName, err)
 					} else {
 						log.Printf("Alert email sent for %s", scraperName)
@@ -220,9 +261,21 @@ registry.Register(scraper.NewGCalendarScraper())
 				}
 
 				continue
+			} else if isSharpDrop(existingCount, newCount, dropAlertRatio) {
+				log.Printf("WARNING: Scraper %s returned %d future services, below %.0f%% of the %d currently stored. Replacing anyway.",
+					scraperName, newCount, dropAlertRatio*100, existingCount)
+
+				if mailer != nil {
+					subject, body := buildCountDropWarning(scraperName, existingCount, newCount, dropAlertRatio, fetchNotes)
+					if err := mailer.Send(subject, body, alertTo); err != nil {
+						log.Printf("ERROR: Failed to send drop warning email for %s: %v", scraperName, err)
+					} else {
+						log.Printf("Drop warning email sent for %s", scraperName)
+					}
+				}
 			}
 
-			accepted = append(accepted, acceptedResult{scraperName: scraperName, services: services})
+			accepted = append(accepted, acceptedResult{scraperName: scraperName, services: services, previous: existing, previousOK: err == nil})
 		}
 	}
 
@@ -272,14 +325,30 @@ registry.Register(scraper.NewGCalendarScraper())
 		if err := fsClient.ReplaceServicesForScraper(ctx, result.scraperName, result.services, batchID); err != nil {
 			log.Printf("ERROR: Failed to store services for %s: %v", result.scraperName, err)
 			failedScrapers++
+			scraperErrors = append(scraperErrors, scraperFailure{name: result.scraperName, err: err})
 			continue
 		}
 		log.Printf("Stored %d services for %s", len(result.services), result.scraperName)
 		totalServices += len(result.services)
+
+		// Without the stored services to compare against, every service would look new
+		if notifyChanges && result.previousOK {
+			if err := notifier.ScheduleChanged(result.scraperName, result.previous, result.services); err != nil {
+				log.Printf("ERROR: Failed to send schedule change notification for %s: %v", result.scraperName, err)
+			}
+		}
+	}
+
+	// Record the events the calendar feed now publishes, so the web server's
+	// cancellation feed can announce the ones that disappeared
+	if bucket := strings.TrimSpace(os.Getenv("PUBLISHED_EVENTS_BUCKET")); bucket != "" {
+		if err := recordPublished(ctx, fsClient, bucket); err != nil {
+			log.Printf("ERROR: Failed to record published events: %v", err)
+		}
 	}
 
 	// Send consolidated alerts
-	if smtpConfig != nil {
+	if mailer != nil {
 		if len(scraperErrors) > 0 {
 			var lines []string
 			for _, f := range scraperErrors {
@@ -289,7 +358,7 @@ registry.Register(scraper.NewGCalendarScraper())
 				}
 			}
 			body := "The following scrapers failed during ingestion:\r\n\r\n" + strings.Join(lines, "\r\n")
-			if err := smtpConfig.Send("Ingestion alert: scrapers failed", body); err != nil {
+			if err := mailer.Send("Ingestion alert: scrapers failed", body, alertTo); err != nil {
 				log.Printf("ERROR: Failed to send scraper failure alert: %v", err)
 			} else {
 				log.Printf("Alert email sent: %d scraper failure(s)", len(scraperErrors))
@@ -304,7 +373,7 @@ registry.Register(scraper.NewGCalendarScraper())
 				"uMap data was available, so these are likely typos or stale slugs.\r\n" +
 				"Falling back to scraper name as Parish for these scrapers.\r\n\r\n" +
 				strings.Join(lines, "\r\n")
-			if err := smtpConfig.Send("Ingestion alert: unknown parish slugs", body); err != nil {
+			if err := mailer.Send("Ingestion alert: unknown parish slugs", body, alertTo); err != nil {
 				log.Printf("ERROR: Failed to send unknown slug alert: %v", err)
 			} else {
 				log.Printf("Alert email sent: %d unknown parish slug(s)", len(unknownSlugs))
@@ -312,18 +381,89 @@ registry.Register(scraper.NewGCalendarScraper())
 		}
 	}
 
+	extracts := vision.ExtractCounts()
+	for _, c := range extracts {
+		log.Printf("Vision extractions for %s: %s=%d", c.Source, c.Result, c.Count)
+	}
+
+	// Record the outcome so a later -only-failed run knows what to retry, and
+	// the server's /metrics can report the extraction counts
+	if err := fsClient.SaveBatchSummary(ctx, buildBatchSummary(batchID, scrapers, scraperErrors, extracts)); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
 	log.Printf("Ingestion complete. Total services: %d, Failed scrapers: %d/%d",
 		totalServices, failedScrapers, len(scrapers))
 
+	// os.Exit skips deferred calls, so flush the traces here
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("WARNING: Failed to flush traces: %v", err)
+	}
 	if failedScrapers > 0 {
 		os.Exit(1)
 	}
 	fmt.Println("Ingestion completed successfully")
 }
 
+// recordPublished records every stored service as published in the
+// published events store in bucket.
+func recordPublished(ctx context.Context, fsClient *firestore.Client, bucket string) error {
+	publishedStore, err := store.NewGCS(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	defer publishedStore.Close()
+
+	services, err := fsClient.GetAllServices(ctx)
+	if err != nil {
+		return err
+	}
+	return web.RecordPublished(publishedStore, services, time.Now())
+}
+
+// selectFailedScrapers returns the scrapers listed as failed in summary.
+func selectFailedScrapers(scrapers []scraper.Scraper, summary *firestore.BatchSummary) []scraper.Scraper {
+	failed := make(map[string]bool, len(summary.Failed))
+	for _, name := range summary.Failed {
+		failed[name] = true
+	}
+	var selected []scraper.Scraper
+	for _, s := range scrapers {
+		if failed[s.Name()] {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// buildBatchSummary lists the scrapers that ran in this batch as failed or
+// succeeded, along with the batch's vision extraction counts. Scrapers whose
+// results were rejected as a count regression did not fail and are listed as
+// succeeded.
+func buildBatchSummary(batchID string, scrapers []scraper.Scraper, failures []scraperFailure, extracts []vision.ExtractCount) firestore.BatchSummary {
+	summary := firestore.BatchSummary{BatchID: batchID}
+	for _, c := range extracts {
+		summary.Extracts = append(summary.Extracts, firestore.ExtractCount{Source: c.Source, Result: c.Result, Count: int64(c.Count)})
+	}
+	failed := make(map[string]bool, len(failures))
+	for _, f := range failures {
+		failed[f.name] = true
+	}
+	for _, s := range scrapers {
+		if failed[s.Name()] {
+			summary.Failed = append(summary.Failed, s.Name())
+		} else {
+			summary.Succeeded = append(summary.Succeeded, s.Name())
+		}
+	}
+	return summary
+}
+
 type acceptedResult struct {
 	scraperName string
 	services    []model.ChurchService
+	previous    []model.ChurchService // future services stored before this run
+	previousOK  bool                  // whether previous could be read
 }
 
 type scraperFailure struct {
@@ -620,6 +760,36 @@ func saveDiagnostics(gcsStore *store.GCSStore, scraperName string, services []mo
 	return path
 }
 
+// defaultDropAlertRatio is the share of the stored future count below which a
+// new count triggers a warning. Drops below 1/3 are rejected outright.
+const defaultDropAlertRatio = 0.5
+
+// isSharpDrop reports whether newCount fell below ratio of existingCount.
+func isSharpDrop(existingCount, newCount int, ratio float64) bool {
+	return existingCount > 0 && float64(newCount) < ratio*float64(existingCount)
+}
+
+// buildCountDropWarning formats the subject and body for a sharp but accepted
+// drop in a source's future service count.
+func buildCountDropWarning(scraperName string, existingCount, newCount int, ratio float64, notes []string) (subject, body string) {
+	subject = fmt.Sprintf("Ingestion warning: %s – %d future events (was %d)", scraperName, newCount, existingCount)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Scraper: %s\r\n", scraperName)
+	fmt.Fprintf(&sb, "Rule: new future count (%d) < %.0f%% of stored count (%d)\r\n", newCount, ratio*100, existingCount)
+	fmt.Fprintf(&sb, "Action: data replaced; the source may be partially broken\r\n")
+
+	if len(notes) > 0 {
+		fmt.Fprintf(&sb, "\r\n")
+		fmt.Fprintf(&sb, "Scraper diagnostics:\r\n")
+		for _, n := range notes {
+			fmt.Fprintf(&sb, "  - %s\r\n", n)
+		}
+	}
+
+	return subject, sb.String()
+}
+
 // buildCountDecreaseAlert formats the subject and body for a service count regression alert.
 func buildCountDecreaseAlert(scraperName string, existingCount, newCount int, gcsBucket, gcsPath string, services []model.ChurchService, notes []string) (subject, body string) {
 	today := time.Now().Format("2006-01-02")
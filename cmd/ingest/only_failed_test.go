@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/firestore"
+	"ortodoxa-gudstjanster/internal/scraper"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+func TestSelectFailedScrapers(t *testing.T) {
+	scrapers := []scraper.Scraper{
+		scraper.NewFinskaScraper(""),
+		scraper.NewHeligaAnnaScraper(),
+		scraper.NewRomanianScraper(),
+	}
+	failedName := scrapers[1].Name()
+	summary := &firestore.BatchSummary{
+		BatchID:   "20260308-040000",
+		Succeeded: []string{scrapers[0].Name(), scrapers[2].Name()},
+		Failed:    []string{failedName, "Removed Scraper"},
+	}
+
+	selected := selectFailedScrapers(scrapers, summary)
+	if len(selected) != 1 || selected[0].Name() != failedName {
+		var names []string
+		for _, s := range selected {
+			names = append(names, s.Name())
+		}
+		t.Errorf("selected %v, want only %s", names, failedName)
+	}
+
+	if selected := selectFailedScrapers(scrapers, &firestore.BatchSummary{BatchID: "20260308-040000"}); len(selected) != 0 {
+		t.Errorf("with no failures, selected %d scrapers, want 0", len(selected))
+	}
+}
+
+func TestBuildBatchSummary(t *testing.T) {
+	scrapers := []scraper.Scraper{scraper.NewFinskaScraper(""), scraper.NewHeligaAnnaScraper()}
+	failures := []scraperFailure{{name: scrapers[1].Name(), err: errors.New("timeout")}}
+
+	extracts := []vision.ExtractCount{{Source: "Ryska", Result: vision.ExtractEmpty, Count: 2}}
+
+	summary := buildBatchSummary("20260308-040000", scrapers, failures, extracts)
+	if len(summary.Succeeded) != 1 || summary.Succeeded[0] != scrapers[0].Name() {
+		t.Errorf("Succeeded = %v", summary.Succeeded)
+	}
+	if len(summary.Failed) != 1 || summary.Failed[0] != scrapers[1].Name() {
+		t.Errorf("Failed = %v", summary.Failed)
+	}
+	if want := []firestore.ExtractCount{{Source: "Ryska", Result: "empty", Count: 2}}; !reflect.DeepEqual(summary.Extracts, want) {
+		t.Errorf("Extracts = %+v, want %+v", summary.Extracts, want)
+	}
+}
@@ -2,18 +2,46 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/model"
 	"ortodoxa-gudstjanster/internal/scraper"
 	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/vision"
+	"ortodoxa-gudstjanster/internal/web"
 )
 
+// parseFormat parses the command-line flags and returns the output format.
+func parseFormat(args []string) (string, error) {
+	fs := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	format := fs.String("format", "json", "output format: json, ics or csv")
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	switch *format {
+	case "json", "ics", "csv":
+		return *format, nil
+	}
+	return "", fmt.Errorf("-format must be json, ics or csv, got %q", *format)
+}
+
 func main() {
+	logging.Setup()
+	format, err := parseFormat(os.Args[1:])
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(2)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
@@ -62,8 +90,41 @@ func main() {
 		all = append(all, services...)
 	}
 
+	if err := writeServices(os.Stdout, all, format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// csvHeader is the header row of the CSV output.
+var csvHeader = []string{"source", "date", "day_of_week", "service_name", "time", "location", "occasion"}
+
+// writeServices writes services as indented JSON, as an ICS feed (format
+// "ics") or as CSV with a header row (format "csv").
+func writeServices(w io.Writer, services []model.ChurchService, format string) error {
+	switch format {
+	case "ics":
+		_, err := io.WriteString(w, web.GenerateICS(services))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, s := range services {
+			cw.Write([]string{s.Source, s.Date, s.DayOfWeek, s.ServiceName, deref(s.Time), deref(s.Location), deref(s.Occasion)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
 
-	enc := json.NewEncoder(os.Stdout)
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	enc.Encode(all)
+	return enc.Encode(services)
+}
+
+// deref returns *p, or "" if p is nil.
+func deref(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func ptr(s string) *string { return &s }
+
+var testServices = []model.ChurchService{
+	{Source: "Finska Ortodoxa Församlingen", Date: "2026-03-01", DayOfWeek: "Söndag", ServiceName: "Liturgi", Time: ptr("10:00"), Location: ptr("Stockholm, Kyrka")},
+	{Source: "Heliga Anna", Date: "2026-03-07", DayOfWeek: "Lördag", ServiceName: "Vesper", Occasion: ptr("Ortodoxins söndag")},
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{nil, "json", false},
+		{[]string{"-format", "ics"}, "ics", false},
+		{[]string{"-format=csv"}, "csv", false},
+		{[]string{"-format", "xml"}, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFormat(tt.args)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseFormat(%v) = %q, %v; want %q, error %v", tt.args, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteServicesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeServices(&buf, testServices, "json"); err != nil {
+		t.Fatalf("writeServices: %v", err)
+	}
+	var got []model.ChurchService
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 || got[1].ServiceName != "Vesper" {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestWriteServicesICS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeServices(&buf, testServices, "ics"); err != nil {
+		t.Fatalf("writeServices: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("output is not a VCALENDAR:\n%s", out)
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("got %d events, want 2", n)
+	}
+}
+
+func TestWriteServicesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeServices(&buf, testServices, "csv"); err != nil {
+		t.Fatalf("writeServices: %v", err)
+	}
+	want := "source,date,day_of_week,service_name,time,location,occasion\n" +
+		"Finska Ortodoxa Församlingen,2026-03-01,Söndag,Liturgi,10:00,\"Stockholm, Kyrka\",\n" +
+		"Heliga Anna,2026-03-07,Lördag,Vesper,,,Ortodoxins söndag\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV output:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -5,15 +5,21 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"ortodoxa-gudstjanster/internal/email"
 	"ortodoxa-gudstjanster/internal/firestore"
+	"ortodoxa-gudstjanster/internal/logging"
+	"ortodoxa-gudstjanster/internal/scraper"
+	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/umap"
 	"ortodoxa-gudstjanster/internal/web"
 )
 
 func main() {
+	logger := logging.Setup()
 	ctx := context.Background()
 
 	port := os.Getenv("PORT")
@@ -31,6 +37,10 @@ func main() {
 		firestoreCollection = "services"
 	}
 
+	if err := web.ValidateTemplates(); err != nil {
+		log.Fatalf("Invalid embedded templates: %v", err)
+	}
+
 	// Initialize Firestore client
 	fsClient, err := firestore.New(ctx, projectID, firestoreCollection)
 	if err != nil {
@@ -67,19 +77,74 @@ func main() {
 		web.SetParishes(p)
 	})
 
+	if v := os.Getenv("SERVICE_DURATIONS"); v != "" {
+		if err := web.SetServiceDurations(v); err != nil {
+			log.Fatalf("Invalid SERVICE_DURATIONS: %v", err)
+		}
+	}
+
 	// Initialize HTTP handlers
-	handler := web.New(fsClient)
+	webOpts := []web.Option{web.WithLogger(logger)}
+	if v := strings.TrimSpace(os.Getenv("TRUSTED_PROXIES")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid TRUSTED_PROXIES %q: want a non-negative integer", v)
+		}
+		webOpts = append(webOpts, web.WithTrustedProxies(n))
+	}
+	if v := strings.TrimSpace(os.Getenv("FEEDBACK_RATE_LIMIT")); v != "" {
+		count, window, _ := strings.Cut(v, "/")
+		n, err := strconv.Atoi(count)
+		d, derr := time.ParseDuration(window)
+		if err != nil || derr != nil || n <= 0 || d <= 0 {
+			log.Fatalf("Invalid FEEDBACK_RATE_LIMIT %q: want submissions per window, e.g. 3/1h", v)
+		}
+		webOpts = append(webOpts, web.WithFeedbackRateLimit(n, d))
+	}
+	handler := web.New(fsClient, webOpts...)
 	handler.SetParishReloader(fsClient)
+	handler.SetIngestReader(fsClient)
+	if adminToken := strings.TrimSpace(os.Getenv("ADMIN_TOKEN")); adminToken != "" {
+		handler.SetAdminToken(adminToken)
+	} else {
+		log.Printf("ADMIN_TOKEN not set (admin endpoints disabled)")
+	}
+
+	// Remember published calendar events for the cancellation feed (optional)
+	if bucket := strings.TrimSpace(os.Getenv("PUBLISHED_EVENTS_BUCKET")); bucket != "" {
+		publishedStore, err := store.NewGCS(ctx, bucket)
+		if err != nil {
+			log.Fatalf("Failed to initialize published events store: %v", err)
+		}
+		defer publishedStore.Close()
+		handler.SetPublishedStore(publishedStore)
+		log.Printf("Published events store: GCS bucket %s", bucket)
+	}
+
+	// Describe the scrapers at /sources, from the same registry and
+	// environment as the ingest job. They are never fetched here, so they get
+	// no store or vision client.
+	var definitions []scraper.ParishDefinition
+	if definitionsPath := os.Getenv("PARISH_DEFINITIONS"); definitionsPath != "" {
+		definitions, err = scraper.LoadDefinitions(definitionsPath)
+		if err != nil {
+			log.Fatalf("Failed to load parish definitions: %v", err)
+		}
+	}
+	sources := scraper.NewDefaultRegistry(scraper.Dependencies{
+		UploadBucket: os.Getenv("GCS_UPLOAD_BUCKET"),
+		Definitions:  definitions,
+	})
+	handler.SetSources(sources.Sources())
 
 	// Configure SMTP if environment variables are set
 	if smtpHost := strings.TrimSpace(os.Getenv("SMTP_HOST")); smtpHost != "" {
-		handler.SetSMTP(&email.SMTPConfig{
+		handler.SetMail(&email.SMTPSender{
 			Host:     smtpHost,
 			Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
 			User:     strings.TrimSpace(os.Getenv("SMTP_USER")),
 			Password: strings.TrimSpace(os.Getenv("SMTP_PASS")),
-			To:       strings.TrimSpace(os.Getenv("SMTP_TO")),
-		})
+		}, email.ParseRecipients(os.Getenv("SMTP_TO")))
 		log.Printf("SMTP configured: %s -> %s", os.Getenv("SMTP_USER"), os.Getenv("SMTP_TO"))
 	} else {
 		log.Printf("SMTP not configured (feedback emails disabled)")
@@ -90,7 +155,7 @@ func main() {
 
 	log.Printf("Server starting on port %s", port)
 
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
+	if err := http.ListenAndServe(":"+port, web.RequestID(mux)); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -10,10 +10,12 @@ import (
 	"os"
 	"time"
 
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/srpska"
 )
 
 func main() {
+	logging.Setup()
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
@@ -1,23 +1,66 @@
 // Part 3: Generate calendar events from structured recurring schedule JSON.
 // Reads schedule JSON from stdin, outputs calendar events JSON to stdout.
 //
-// Usage: cat schedule.json | go run ./cmd/srpska-generate
+// With -format ics the schedule is rendered as an iCalendar feed instead,
+// with one weekly recurring event per service.
+//
+// Usage: cat schedule.json | go run ./cmd/srpska-generate [-weeks N] [-format json|ics]
 // Or:    go run ./cmd/srpska-schedule | go run ./cmd/srpska-generate
 // Or:    go run ./cmd/srpska-fetch | go run ./cmd/srpska-parse | go run ./cmd/srpska-generate
 package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/srpska"
+	"ortodoxa-gudstjanster/internal/web"
 )
 
-const defaultWeeks = 26
+const maxWeeks = 104
+
+type options struct {
+	weeks  int
+	format string
+}
+
+// parseFlags parses the command-line arguments (without the program name).
+// -weeks defaults to SRPSKA_WEEKS, or srpska.DefaultWeeks if that is unset.
+func parseFlags(args []string) (options, error) {
+	var opts options
+	defaultWeeks, err := srpska.WeeksFromEnv()
+	if err != nil {
+		return opts, err
+	}
+	fs := flag.NewFlagSet("srpska-generate", flag.ContinueOnError)
+	fs.IntVar(&opts.weeks, "weeks", defaultWeeks, "number of weeks of events to generate (default from SRPSKA_WEEKS)")
+	fs.StringVar(&opts.format, "format", "json", "output format: json or ics")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.weeks <= 0 || opts.weeks > maxWeeks {
+		return opts, fmt.Errorf("-weeks must be between 1 and %d, got %d", maxWeeks, opts.weeks)
+	}
+	if opts.format != "json" && opts.format != "ics" {
+		return opts, fmt.Errorf("-format must be json or ics, got %q", opts.format)
+	}
+	return opts, nil
+}
 
 func main() {
+	logging.Setup()
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(2)
+	}
+
 	// Read schedule JSON from stdin
 	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -31,13 +74,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Generate events for 8 weeks
-	events := srpska.GenerateEvents(&schedule, defaultWeeks, nil)
-
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(events); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+	if err := writeSchedule(os.Stdout, &schedule, opts.weeks, opts.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// writeSchedule writes weeks of events for schedule as indented JSON, or as an
+// ICS feed when format is "ics". The feed has one event per recurring service,
+// with an RRULE, rather than one per occurrence.
+func writeSchedule(w io.Writer, schedule *srpska.RecurringSchedule, weeks int, format string) error {
+	if format == "ics" {
+		_, err := io.WriteString(w, web.GenerateICS(srpska.RecurringServices(schedule, weeks, nil)))
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(srpska.GenerateEvents(schedule, weeks, nil))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/srpska"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantWeeks int
+		wantErr   bool
+	}{
+		{"default", nil, srpska.DefaultWeeks, false},
+		{"explicit", []string{"-weeks", "8"}, 8, false},
+		{"upper bound", []string{"-weeks=104"}, 104, false},
+		{"zero", []string{"-weeks", "0"}, 0, true},
+		{"negative", []string{"-weeks", "-3"}, 0, true},
+		{"too many", []string{"-weeks", "105"}, 0, true},
+		{"not a number", []string{"-weeks", "many"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !tt.wantErr && opts.weeks != tt.wantWeeks {
+				t.Errorf("weeks = %d, want %d", opts.weeks, tt.wantWeeks)
+			}
+		})
+	}
+}
+
+func TestParseFlagsWeeksFromEnv(t *testing.T) {
+	t.Setenv("SRPSKA_WEEKS", "8")
+	if opts, err := parseFlags(nil); err != nil || opts.weeks != 8 {
+		t.Errorf("with SRPSKA_WEEKS=8, parseFlags(nil) = %+v, %v", opts, err)
+	}
+	if opts, err := parseFlags([]string{"-weeks", "4"}); err != nil || opts.weeks != 4 {
+		t.Errorf("-weeks should override SRPSKA_WEEKS, got %+v, %v", opts, err)
+	}
+
+	t.Setenv("SRPSKA_WEEKS", "0")
+	if _, err := parseFlags(nil); err == nil {
+		t.Error("expected error for SRPSKA_WEEKS=0")
+	}
+}
+
+func TestParseFlagsFormat(t *testing.T) {
+	opts, err := parseFlags([]string{"-format", "ics"})
+	if err != nil || opts.format != "ics" {
+		t.Errorf("parseFlags(-format ics) = %+v, %v", opts, err)
+	}
+	if _, err := parseFlags([]string{"-format", "xml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestWriteScheduleICS(t *testing.T) {
+	schedule := &srpska.RecurringSchedule{
+		Services: []srpska.RecurringService{
+			{Name: "Aftongudstjänst", Days: []string{"lördag"}, Time: "17:00"},
+			{Name: "Helig Liturgi", Days: []string{"söndag"}, Time: "09:30"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSchedule(&buf, schedule, 8, "ics"); err != nil {
+		t.Fatalf("writeSchedule: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("output is not a VCALENDAR:\n%s", out)
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != len(schedule.Services) {
+		t.Errorf("got %d events, want one per recurring service (%d)", n, len(schedule.Services))
+	}
+	for _, want := range []string{
+		"RRULE:FREQ=WEEKLY;BYDAY=SA;UNTIL=",
+		"RRULE:FREQ=WEEKLY;BYDAY=SU;UNTIL=",
+		"T093000\r\n",
+		"LOCATION:Bägerstavägen 68",
+		"Källa: " + srpska.CalendarURL,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
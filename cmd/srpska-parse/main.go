@@ -11,10 +11,13 @@ import (
 	"io"
 	"os"
 
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/srpska"
 )
 
 func main() {
+	logging.Setup()
+
 	// Read raw table text from stdin
 	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
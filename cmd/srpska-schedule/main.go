@@ -3,6 +3,9 @@
 //
 // Usage: CHROME_PATH=/path/to/chromium go run ./cmd/srpska-schedule
 //
+// Without a working Chrome, the schedule is read from the page's static
+// HTML over plain HTTP instead.
+//
 // Equivalent to: go run ./cmd/srpska-fetch | go run ./cmd/srpska-parse
 package main
 
@@ -13,24 +16,20 @@ import (
 	"os"
 	"time"
 
-	"ortodoxa-gudstjanster/internal/srpska"
+	"ortodoxa-gudstjanster/internal/logging"
+	"ortodoxa-gudstjanster/internal/scraper"
 )
 
 func main() {
+	logging.Setup()
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	// Part 1: Fetch raw table text
-	page, err := srpska.FetchPageContent(ctx)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Part 2: Parse into structured schedule
-	schedule, err := srpska.ParseScheduleTable(page.TableText)
+	// Fetch and parse the schedule, falling back to plain HTTP if Chrome is
+	// unavailable
+	schedule, err := scraper.FetchSrpskaSchedule(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error fetching schedule: %v\n", err)
 		os.Exit(1)
 	}
 
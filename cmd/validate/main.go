@@ -0,0 +1,49 @@
+// Validate scraped services before ingesting them.
+// Reads the JSON array printed by cmd/scrape from stdin, reports every
+// service that fails model.Validate and exits non-zero if any did.
+//
+// Usage: go run ./cmd/scrape | go run ./cmd/validate
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"ortodoxa-gudstjanster/internal/logging"
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func main() {
+	logging.Setup()
+
+	var services []model.ChurchService
+	if err := json.NewDecoder(os.Stdin).Decode(&services); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing JSON: %v\n", err)
+		os.Exit(2)
+	}
+
+	if invalid := report(os.Stdout, services); invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+// report writes the problems with each invalid service to w, followed by a
+// summary line, and returns the number of invalid services.
+func report(w io.Writer, services []model.ChurchService) int {
+	invalid := 0
+	for i, s := range services {
+		errs := model.Validate(s)
+		if len(errs) == 0 {
+			continue
+		}
+		invalid++
+		fmt.Fprintf(w, "#%d %s %s %q:\n", i, s.Source, s.Date, s.ServiceName)
+		for _, err := range errs {
+			fmt.Fprintf(w, "  - %v\n", err)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d services invalid\n", invalid, len(services))
+	return invalid
+}
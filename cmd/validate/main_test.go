@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func TestReport(t *testing.T) {
+	services := []model.ChurchService{
+		{Source: "A", Date: "2026-03-08", DayOfWeek: "Söndag", ServiceName: "Helig Liturgi"},
+		{Source: "B", Date: "2026-3-8", DayOfWeek: "Söndag", ServiceName: ""},
+	}
+
+	var out strings.Builder
+	if invalid := report(&out, services); invalid != 1 {
+		t.Errorf("report() = %d invalid, want 1", invalid)
+	}
+	want := "#1 B 2026-3-8 \"\":\n" +
+		"  - invalid date format: \"2026-3-8\"\n" +
+		"  - service name is empty\n" +
+		"1 of 2 services invalid\n"
+	if out.String() != want {
+		t.Errorf("report output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if invalid := report(&out, services[:1]); invalid != 0 || out.String() != "0 of 1 services invalid\n" {
+		t.Errorf("valid input: %d invalid, output %q", invalid, out.String())
+	}
+}
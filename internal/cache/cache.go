@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"ortodoxa-gudstjanster/internal/model"
@@ -16,11 +17,29 @@ type Entry struct {
 	FetchedAt time.Time             `json:"fetched_at"`
 }
 
-// Cache provides disk-based caching for scraped services.
+// Cache provides disk-based caching for scraped services, with an in-process
+// memory tier in front of the disk so repeated reads skip file I/O and JSON
+// decoding. The disk remains the durable store so restarts stay warm.
 type Cache struct {
 	dir string
 	ttl time.Duration
 	mu  sync.RWMutex
+
+	memMu  sync.RWMutex
+	memory map[string]Entry // scraper name → entry
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	expirations atomic.Uint64
+}
+
+// Stats is a snapshot of cache effectiveness counters.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64 // includes expirations
+	Expirations uint64 // lookups that found an entry older than the TTL
+	// LastFetch maps scraper name to the fetch time of its entry held in memory.
+	LastFetch map[string]time.Time
 }
 
 // New creates a new disk-based cache.
@@ -29,32 +48,95 @@ func New(cacheDir string, ttl time.Duration) (*Cache, error) {
 		return nil, err
 	}
 	return &Cache{
-		dir: cacheDir,
-		ttl: ttl,
+		dir:    cacheDir,
+		ttl:    ttl,
+		memory: make(map[string]Entry),
 	}, nil
 }
 
-// Get retrieves cached services for a scraper if they exist and aren't expired.
+// Get retrieves cached services for a scraper if they exist and aren't
+// expired. The returned slice is a copy, so callers may modify it.
 func (c *Cache) Get(scraperName string) ([]model.ChurchService, bool) {
+	c.memMu.RLock()
+	entry, ok := c.memory[scraperName]
+	c.memMu.RUnlock()
+	if ok {
+		if time.Since(entry.FetchedAt) <= c.ttl {
+			c.hits.Add(1)
+			return cloneServices(entry.Services), true
+		}
+		c.forget(scraperName)
+	}
+
+	// Hold c.mu from reading the disk until the entry is in memory, so an
+	// Invalidate cannot run in between and be undone by remember.
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	path := c.filePath(scraperName)
-	data, err := os.ReadFile(path)
-	if err != nil {
+	entry, ok = c.readDisk(scraperName)
+	if !ok {
+		c.misses.Add(1)
 		return nil, false
 	}
+	if time.Since(entry.FetchedAt) > c.ttl {
+		c.misses.Add(1)
+		c.expirations.Add(1)
+		return nil, false
+	}
+
+	c.remember(scraperName, entry)
+	c.hits.Add(1)
+	return cloneServices(entry.Services), true
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *Cache) Stats() Stats {
+	c.memMu.RLock()
+	lastFetch := make(map[string]time.Time, len(c.memory))
+	for name, entry := range c.memory {
+		lastFetch[name] = entry.FetchedAt
+	}
+	c.memMu.RUnlock()
+
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Expirations: c.expirations.Load(),
+		LastFetch:   lastFetch,
+	}
+}
+
+// readDisk reads and decodes the on-disk entry for a scraper. The caller
+// holds c.mu.
+func (c *Cache) readDisk(scraperName string) (Entry, bool) {
+	data, err := os.ReadFile(c.filePath(scraperName))
+	if err != nil {
+		return Entry{}, false
+	}
 
 	var entry Entry
 	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, false
+		return Entry{}, false
 	}
+	return entry, true
+}
 
-	if time.Since(entry.FetchedAt) > c.ttl {
-		return nil, false
-	}
+func (c *Cache) remember(scraperName string, entry Entry) {
+	c.memMu.Lock()
+	c.memory[scraperName] = entry
+	c.memMu.Unlock()
+}
+
+// cloneServices copies a slice so entries held in memory are not shared
+// with callers.
+func cloneServices(services []model.ChurchService) []model.ChurchService {
+	return append([]model.ChurchService(nil), services...)
+}
 
-	return entry.Services, true
+func (c *Cache) forget(scraperName string) {
+	c.memMu.Lock()
+	delete(c.memory, scraperName)
+	c.memMu.Unlock()
 }
 
 // Set stores services in the cache.
@@ -63,7 +145,7 @@ func (c *Cache) Set(scraperName string, services []model.ChurchService) error {
 	defer c.mu.Unlock()
 
 	entry := Entry{
-		Services:  services,
+		Services:  cloneServices(services),
 		FetchedAt: time.Now(),
 	}
 
@@ -72,7 +154,11 @@ func (c *Cache) Set(scraperName string, services []model.ChurchService) error {
 		return err
 	}
 
-	return os.WriteFile(c.filePath(scraperName), data, 0644)
+	if err := os.WriteFile(c.filePath(scraperName), data, 0644); err != nil {
+		return err
+	}
+	c.remember(scraperName, entry)
+	return nil
 }
 
 // Invalidate removes a specific scraper's cache.
@@ -80,6 +166,7 @@ func (c *Cache) Invalidate(scraperName string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.forget(scraperName)
 	path := c.filePath(scraperName)
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return err
@@ -92,6 +179,10 @@ func (c *Cache) InvalidateAll() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.memMu.Lock()
+	c.memory = make(map[string]Entry)
+	c.memMu.Unlock()
+
 	entries, err := os.ReadDir(c.dir)
 	if err != nil {
 		return err
@@ -158,3 +158,129 @@ func TestCacheFilePathSanitization(t *testing.T) {
 		t.Errorf("expected 1 service, got %d", len(got))
 	}
 }
+
+func TestCacheGetServedFromMemory(t *testing.T) {
+	dir := tempCacheDir(t)
+	c, err := New(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	services := []model.ChurchService{
+		{Source: "Test", Date: "2026-03-08", ServiceName: "Liturgi"},
+	}
+	if err := c.Set("test-scraper", services); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, ok := c.Get("test-scraper"); !ok {
+		t.Fatal("first Get returned false")
+	}
+
+	// Remove the backing file; the memory tier should still serve the entry.
+	if err := os.Remove(c.filePath("test-scraper")); err != nil {
+		t.Fatalf("removing cache file: %v", err)
+	}
+
+	got, ok := c.Get("test-scraper")
+	if !ok {
+		t.Fatal("second Get should be served from memory")
+	}
+	if len(got) != 1 || got[0].ServiceName != "Liturgi" {
+		t.Errorf("Get returned unexpected data: %v", got)
+	}
+}
+
+func TestCacheGetWarmsMemoryFromDisk(t *testing.T) {
+	dir := tempCacheDir(t)
+	services := []model.ChurchService{
+		{Source: "Test", Date: "2026-03-08", ServiceName: "Liturgi"},
+	}
+
+	// Populate the disk with one instance, then read it with a fresh one (simulates a restart).
+	first, err := New(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := first.Set("test-scraper", services); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	c, err := New(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, ok := c.Get("test-scraper"); !ok {
+		t.Fatal("Get should read the entry from disk")
+	}
+	os.Remove(c.filePath("test-scraper"))
+	if _, ok := c.Get("test-scraper"); !ok {
+		t.Error("entry read from disk should be kept in memory")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	c, err := New(tempCacheDir(t), time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, ok := c.Get("test-scraper"); ok {
+		t.Fatal("Get should miss on empty cache")
+	}
+	if st := c.Stats(); st.Misses != 1 || st.Hits != 0 {
+		t.Errorf("after miss: hits=%d misses=%d, want 0 and 1", st.Hits, st.Misses)
+	}
+
+	services := []model.ChurchService{
+		{Source: "Test", Date: "2026-03-08", ServiceName: "Liturgi"},
+	}
+	if err := c.Set("test-scraper", services); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, ok := c.Get("test-scraper"); !ok {
+		t.Fatal("Get should hit after Set")
+	}
+
+	st := c.Stats()
+	if st.Hits != 1 || st.Misses != 1 {
+		t.Errorf("after hit: hits=%d misses=%d, want 1 and 1", st.Hits, st.Misses)
+	}
+	if _, ok := st.LastFetch["test-scraper"]; !ok {
+		t.Error("LastFetch should include test-scraper")
+	}
+}
+
+func TestCacheStatsExpirations(t *testing.T) {
+	c, err := New(tempCacheDir(t), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	c.Set("test-scraper", []model.ChurchService{{Source: "Test", Date: "2026-03-08", ServiceName: "Liturgi"}})
+	time.Sleep(15 * time.Millisecond)
+	c.Get("test-scraper")
+
+	if st := c.Stats(); st.Expirations != 1 || st.Misses != 1 {
+		t.Errorf("expirations=%d misses=%d, want 1 and 1", st.Expirations, st.Misses)
+	}
+}
+
+func TestCacheGetReturnsCopy(t *testing.T) {
+	c, err := New(tempCacheDir(t), time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	services := []model.ChurchService{{Source: "Test", ServiceName: "Liturgi"}}
+	if err := c.Set("test-scraper", services); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	services[0].ServiceName = "changed by the caller of Set"
+
+	got, _ := c.Get("test-scraper")
+	got[0].ServiceName = "changed by the caller of Get"
+
+	got, _ = c.Get("test-scraper")
+	if got[0].ServiceName != "Liturgi" {
+		t.Errorf("ServiceName = %q, want the cached entry unchanged", got[0].ServiceName)
+	}
+}
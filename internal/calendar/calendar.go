@@ -0,0 +1,164 @@
+// Package calendar computes the movable and fixed dates of the Orthodox
+// church year: Pascha, the great feasts and fasting periods derived from it,
+// and the saints commemorated on fixed dates.
+//
+// Fixed dates follow the Revised Julian calendar used by most parishes in
+// Sweden, which coincides with the Gregorian calendar until 2800. Parishes on
+// the old (Julian) calendar keep them julianLag days later; see FeastOnJulian.
+// Pascha is always computed on the Julian calendar.
+package calendar
+
+import (
+	"sort"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+// Period is a named span of consecutive days, with both ends inclusive.
+type Period struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// julianLag is how many days the Julian calendar lags the Gregorian in
+// 1900–2099.
+const julianLag = 13
+
+// Pascha returns the date of Orthodox Easter for the given year, expressed
+// as a Gregorian date in UTC. Valid for 1900–2099.
+func Pascha(year int) time.Time {
+	// Meeus' Julian algorithm
+	a := year % 4
+	b := year % 7
+	c := year % 19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+
+	julian := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return julian.AddDate(0, 0, julianLag)
+}
+
+// Feast is a single-day feast of the church year.
+type Feast struct {
+	Name string
+	Date time.Time
+}
+
+// Feasts returns Pascha and the twelve great feasts of the given year in
+// chronological order.
+func Feasts(year int) []Feast {
+	return feasts(year, 0)
+}
+
+// feasts returns the feasts of the given year with the fixed feasts moved
+// lag days later. The movable feasts depend only on Pascha.
+func feasts(year, lag int) []Feast {
+	pascha := Pascha(year)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day+lag, 0, 0, 0, 0, time.UTC)
+	}
+
+	feasts := []Feast{
+		{Name: "Theofania", Date: date(time.January, 6)},
+		{Name: "Herrens möte i templet", Date: date(time.February, 2)},
+		{Name: "Bebådelsen", Date: date(time.March, 25)},
+		{Name: "Palmsöndagen", Date: pascha.AddDate(0, 0, -7)},
+		{Name: "Påsk", Date: pascha},
+		{Name: "Kristi himmelsfärd", Date: pascha.AddDate(0, 0, 39)},
+		{Name: "Pingst", Date: pascha.AddDate(0, 0, 49)},
+		{Name: "Kristi förklaring", Date: date(time.August, 6)},
+		{Name: "Guds moders insomnande", Date: date(time.August, 15)},
+		{Name: "Guds moders födelse", Date: date(time.September, 8)},
+		{Name: "Korsets upphöjelse", Date: date(time.September, 14)},
+		{Name: "Guds moders tempelgång", Date: date(time.November, 21)},
+		{Name: "Kristi födelse", Date: date(time.December, 25)},
+	}
+
+	// The Annunciation can fall in Holy Week or on Pascha itself.
+	sort.SliceStable(feasts, func(i, j int) bool { return feasts[i].Date.Before(feasts[j].Date) })
+	return feasts
+}
+
+// FeastOn returns the name of the great feast on the given date, if any.
+func FeastOn(date time.Time) (string, bool) {
+	return feastOn(date, 0)
+}
+
+// FeastOnJulian is FeastOn for parishes on the old calendar, where the
+// fixed feasts fall julianLag days later: the Nativity on January 7.
+func FeastOnJulian(date time.Time) (string, bool) {
+	return feastOn(date, julianLag)
+}
+
+// feastOn looks in the previous year too, whose lagged Nativity falls in
+// January.
+func feastOn(date time.Time, lag int) (string, bool) {
+	day := date.Format("2006-01-02")
+	for _, year := range []int{date.Year() - 1, date.Year()} {
+		for _, f := range feasts(year, lag) {
+			if f.Date.Format("2006-01-02") == day {
+				return f.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Fasts returns the multi-day fasting periods of the given year in
+// chronological order.
+func Fasts(year int) []Period {
+	pascha := Pascha(year)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	fasts := []Period{
+		// Clean Monday through Holy Saturday
+		{Name: "Stora fastan", Start: pascha.AddDate(0, 0, -48), End: pascha.AddDate(0, 0, -1)},
+		// Monday after All Saints' Sunday through the eve of Sts. Peter and Paul
+		{Name: "Apostlafastan", Start: pascha.AddDate(0, 0, 57), End: date(time.June, 28)},
+		{Name: "Insomnandets fasta", Start: date(time.August, 1), End: date(time.August, 14)},
+		{Name: "Julfastan", Start: date(time.November, 15), End: date(time.December, 24)},
+	}
+
+	// The Apostles' fast disappears in years with a late Pascha.
+	var result []Period
+	for _, f := range fasts {
+		if !f.End.Before(f.Start) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// FastServices returns the fasting periods that overlap [from, to] as
+// all-day, multi-day services with ServiceType model.ServiceTypeFast.
+func FastServices(from, to time.Time) []model.ChurchService {
+	fromDate := from.Format("2006-01-02")
+	toDate := to.Format("2006-01-02")
+
+	var services []model.ChurchService
+	for year := from.Year(); year <= to.Year(); year++ {
+		for _, f := range Fasts(year) {
+			start := f.Start.Format("2006-01-02")
+			end := f.End.Format("2006-01-02")
+			if end < fromDate || start > toDate {
+				continue
+			}
+			services = append(services, model.ChurchService{
+				Date:        start,
+				EndDate:     end,
+				DayOfWeek:   model.SwedishWeekday(f.Start.Weekday()),
+				ServiceName: f.Name,
+				ServiceType: model.ServiceTypeFast,
+			})
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Date < services[j].Date })
+	return services
+}
@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func TestPascha(t *testing.T) {
+	tests := []struct {
+		year int
+		want string
+	}{
+		{2024, "2024-05-05"},
+		{2025, "2025-04-20"},
+		{2026, "2026-04-12"},
+		{2027, "2027-05-02"},
+	}
+
+	for _, tt := range tests {
+		if got := Pascha(tt.year).Format("2006-01-02"); got != tt.want {
+			t.Errorf("Pascha(%d) = %s, want %s", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestFeasts2026(t *testing.T) {
+	want := map[string]string{
+		"Palmsöndagen":       "2026-04-05",
+		"Påsk":               "2026-04-12",
+		"Kristi himmelsfärd": "2026-05-21",
+		"Pingst":             "2026-05-31",
+		"Kristi födelse":     "2026-12-25",
+	}
+
+	feasts := Feasts(2026)
+	if len(feasts) != 13 {
+		t.Errorf("got %d feasts, want 13", len(feasts))
+	}
+	for i, f := range feasts {
+		if i > 0 && f.Date.Before(feasts[i-1].Date) {
+			t.Errorf("feasts not in order: %s before %s", feasts[i-1].Name, f.Name)
+		}
+		if date, ok := want[f.Name]; ok && f.Date.Format("2006-01-02") != date {
+			t.Errorf("%s = %s, want %s", f.Name, f.Date.Format("2006-01-02"), date)
+		}
+	}
+}
+
+func TestFeastOn(t *testing.T) {
+	tests := []struct {
+		date     string
+		wantName string
+		wantOK   bool
+	}{
+		{"2026-08-15", "Guds moders insomnande", true},
+		{"2026-05-31", "Pingst", true},
+		{"2026-05-30", "", false},
+		{"2027-05-02", "Påsk", true},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		name, ok := FeastOn(date)
+		if name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("FeastOn(%s) = %q, %v; want %q, %v", tt.date, name, ok, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestFeastOnJulian(t *testing.T) {
+	tests := []struct {
+		date     string
+		wantName string
+		wantOK   bool
+	}{
+		{"2026-08-28", "Guds moders insomnande", true},
+		{"2026-08-15", "", false},
+		{"2027-01-07", "Kristi födelse", true},
+		{"2026-12-25", "", false},
+		{"2026-01-19", "Theofania", true},
+		{"2026-05-31", "Pingst", true},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		name, ok := FeastOnJulian(date)
+		if name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("FeastOnJulian(%s) = %q, %v; want %q, %v", tt.date, name, ok, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestFastsGreatLent2026(t *testing.T) {
+	fasts := Fasts(2026)
+	if len(fasts) == 0 || fasts[0].Name != "Stora fastan" {
+		t.Fatalf("first fast = %v, want Stora fastan", fasts)
+	}
+	lent := fasts[0]
+	if got := lent.Start.Format("2006-01-02"); got != "2026-02-23" {
+		t.Errorf("Great Lent starts %s, want 2026-02-23", got)
+	}
+	if got := lent.End.Format("2006-01-02"); got != "2026-04-11" {
+		t.Errorf("Great Lent ends %s, want 2026-04-11", got)
+	}
+}
+
+func TestFastsSkipsEmptyApostlesFast(t *testing.T) {
+	// Pascha 2043 is May 3, so the Monday after All Saints falls on June 29.
+	for _, f := range Fasts(2043) {
+		if f.Name == "Apostlafastan" {
+			t.Errorf("Apostlafastan should be skipped in 2043, got %s–%s", f.Start.Format("2006-01-02"), f.End.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestFastServicesMultiDayAllDay(t *testing.T) {
+	from := time.Date(2026, time.August, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC)
+
+	services := FastServices(from, to)
+	if len(services) != 1 {
+		t.Fatalf("got %d services, want 1: %v", len(services), services)
+	}
+	svc := services[0]
+	if svc.ServiceName != "Insomnandets fasta" {
+		t.Errorf("ServiceName = %q, want %q", svc.ServiceName, "Insomnandets fasta")
+	}
+	if svc.Date != "2026-08-01" || svc.EndDate != "2026-08-14" {
+		t.Errorf("span = %s–%s, want 2026-08-01–2026-08-14", svc.Date, svc.EndDate)
+	}
+	if svc.ServiceType != model.ServiceTypeFast {
+		t.Errorf("ServiceType = %q, want %q", svc.ServiceType, model.ServiceTypeFast)
+	}
+	if svc.Time != nil || svc.StartTime != nil {
+		t.Error("fast should be an all-day event without time")
+	}
+}
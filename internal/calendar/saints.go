@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+//go:embed saints.json
+var saintsJSON []byte
+
+// saints maps a fixed date (MM-DD) to the main commemoration of that day.
+var saints map[string]string
+
+func init() {
+	if err := json.Unmarshal(saintsJSON, &saints); err != nil {
+		panic(fmt.Sprintf("calendar: parsing saints.json: %v", err))
+	}
+}
+
+// Commemoration returns the main saint or feast commemorated on the given
+// date, or "" if the bundled calendar has none for that day.
+func Commemoration(date time.Time) string {
+	return saints[date.Format("01-02")]
+}
+
+// CommemorationServices returns one all-day entry per day in [from, to]
+// that has a commemoration, with ServiceType model.ServiceTypeCommemoration.
+func CommemorationServices(from, to time.Time) []model.ChurchService {
+	var services []model.ChurchService
+
+	current := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	for !current.After(end) {
+		if name := Commemoration(current); name != "" {
+			services = append(services, model.ChurchService{
+				Date:        current.Format("2006-01-02"),
+				DayOfWeek:   model.SwedishWeekday(current.Weekday()),
+				ServiceName: name,
+				ServiceType: model.ServiceTypeCommemoration,
+			})
+		}
+		current = current.AddDate(0, 0, 1)
+	}
+
+	return services
+}
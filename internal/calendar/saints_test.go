@@ -0,0 +1,49 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func TestCommemoration(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2026-04-23", "Helige Georgios, storsegrare"},
+		{"2026-12-06", "Helige Nikolaos"},
+		{"2026-08-15", "Guds moders avsomnande"},
+		{"2026-02-03", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tt.date)
+			if got := Commemoration(date); got != tt.want {
+				t.Errorf("Commemoration(%s) = %q, want %q", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommemorationServices(t *testing.T) {
+	from := time.Date(2026, time.December, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.December, 7, 0, 0, 0, 0, time.UTC)
+
+	services := CommemorationServices(from, to)
+	if len(services) != 1 {
+		t.Fatalf("got %d services, want 1: %v", len(services), services)
+	}
+	svc := services[0]
+	if svc.Date != "2026-12-06" || svc.ServiceName != "Helige Nikolaos" {
+		t.Errorf("got %s %q, want 2026-12-06 %q", svc.Date, svc.ServiceName, "Helige Nikolaos")
+	}
+	if svc.ServiceType != model.ServiceTypeCommemoration {
+		t.Errorf("ServiceType = %q, want %q", svc.ServiceType, model.ServiceTypeCommemoration)
+	}
+	if svc.Time != nil || svc.EndDate != "" {
+		t.Error("commemoration should be a single all-day event")
+	}
+}
@@ -0,0 +1,62 @@
+// Package dateutil recognizes Swedish day and month names as they appear in
+// parish texts and OCR output, and translates day names for non-Swedish readers.
+package dateutil
+
+import (
+	"strings"
+	"time"
+)
+
+// DayPattern is a regular expression fragment (without capture groups)
+// matching a Swedish day name case-insensitively. It tolerates missing
+// diacritics ("Sondag") and common abbreviations ("sön", "lör", "tors"), and
+// matches whole words only, so "ons" in "Konsert" or "tis" in "Kristi" is not
+// a day. Use ParseWeekday on the matched text to get the weekday.
+//
+// RE2's \b only knows ASCII letters. Every day name starts and ends with
+// one, so the boundaries hold, except that a name directly after å, ä or ö
+// ("Påsön") still matches.
+const DayPattern = `(?i:\b(?:m[aå]n(?:dag)?|tis(?:dag)?|ons(?:dag)?|tors?(?:dag)?|fre(?:dag)?|l[oö]r(?:dag)?|s[oö]n(?:dag)?)\b)`
+
+// dayAliases maps diacritic-free, lowercase day names and abbreviations to weekdays.
+var dayAliases = map[string]time.Weekday{
+	"man": time.Monday, "mandag": time.Monday,
+	"tis": time.Tuesday, "tisdag": time.Tuesday,
+	"ons": time.Wednesday, "onsdag": time.Wednesday,
+	"tor": time.Thursday, "tors": time.Thursday, "torsdag": time.Thursday,
+	"fre": time.Friday, "fredag": time.Friday,
+	"lor": time.Saturday, "lordag": time.Saturday,
+	"son": time.Sunday, "sondag": time.Sunday,
+}
+
+var foldDiacritics = strings.NewReplacer("å", "a", "ä", "a", "ö", "o")
+
+// ParseWeekday returns the weekday named by a Swedish day name or
+// abbreviation, e.g. "Söndag", "Sondag" or "sön.".
+func ParseWeekday(name string) (time.Weekday, bool) {
+	key := strings.TrimSuffix(strings.TrimSpace(strings.ToLower(name)), ".")
+	day, ok := dayAliases[foldDiacritics.Replace(key)]
+	return day, ok
+}
+
+// weekdayNames holds capitalized weekday names, indexed by time.Weekday, for
+// the languages LocalizeWeekday supports besides Swedish.
+var weekdayNames = map[string][7]string{
+	"en": {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+// LocalizeWeekday translates a Swedish day name (as stored in
+// ChurchService.DayOfWeek) to the language with the given code, e.g.
+// LocalizeWeekday("Söndag", "en") returns "Sunday". The name is returned
+// unchanged for Swedish, unsupported languages and unrecognized names.
+func LocalizeWeekday(swedishName, lang string) string {
+	names, ok := weekdayNames[strings.ToLower(lang)]
+	if !ok {
+		return swedishName
+	}
+	day, ok := ParseWeekday(swedishName)
+	if !ok {
+		return swedishName
+	}
+	return names[day]
+}
@@ -0,0 +1,101 @@
+package dateutil
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   time.Weekday
+		wantOK bool
+	}{
+		{"söndag", time.Sunday, true},
+		{"Söndag", time.Sunday, true},
+		{"Sondag", time.Sunday, true},
+		{"sön", time.Sunday, true},
+		{"sön.", time.Sunday, true},
+		{"SÖN", time.Sunday, true},
+		{"Lordag", time.Saturday, true},
+		{"lör", time.Saturday, true},
+		{"Mandag", time.Monday, true},
+		{"mån", time.Monday, true},
+		{"tors", time.Thursday, true},
+		{"fredag", time.Friday, true},
+		{"helgdag", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseWeekday(tt.input)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("ParseWeekday(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDayPatternMatchesParseableNames(t *testing.T) {
+	re := regexp.MustCompile(`^(` + DayPattern + `)\s+\d`)
+	for _, input := range []string{"Söndag 8", "Sondag 8", "sön 8", "LÖRDAG 7", "Lordag 7", "tors 5", "Mån 2"} {
+		m := re.FindStringSubmatch(input)
+		if m == nil {
+			t.Errorf("DayPattern should match %q", input)
+			continue
+		}
+		if _, ok := ParseWeekday(m[1]); !ok {
+			t.Errorf("ParseWeekday(%q) should accept the text matched in %q", m[1], input)
+		}
+	}
+}
+
+func TestDayPatternMatchesWholeWords(t *testing.T) {
+	re := regexp.MustCompile(DayPattern)
+	for _, input := range []string{
+		"Konsert i katedralen",        // ons
+		"Kristi himmelsfärd",          // tis
+		"Frestelsen i öknen",          // fre
+		"Historisk tornvandring",      // tor, tors
+		"Personalmöte kommande månad", // son, man, mån
+	} {
+		if m := re.FindString(input); m != "" {
+			t.Errorf("DayPattern matched %q inside %q", m, input)
+		}
+	}
+
+	for _, tt := range []struct{ input, want string }{
+		{"Vesper, lör. kl 18", "lör"},
+		{"Liturgi (Söndag)", "Söndag"},
+		{"tis/ons", "tis"},
+	} {
+		if got := re.FindString(tt.input); got != tt.want {
+			t.Errorf("DayPattern in %q matched %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizeWeekday(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		want string
+	}{
+		{"Söndag", "en", "Sunday"},
+		{"Måndag", "en", "Monday"},
+		{"Lördag", "EN", "Saturday"},
+		{"torsdag", "en", "Thursday"},
+		{"Söndag", "sv", "Söndag"},
+		{"Söndag", "", "Söndag"},
+		{"Söndag", "xx", "Söndag"},
+		{"Helgdag", "en", "Helgdag"},
+	}
+
+	for _, tt := range tests {
+		if got := LocalizeWeekday(tt.name, tt.lang); got != tt.want {
+			t.Errorf("LocalizeWeekday(%q, %q) = %q, want %q", tt.name, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package dateutil
+
+import (
+	"strings"
+	"time"
+)
+
+// MonthNames holds the capitalized Swedish month names, indexed by
+// time.Month - 1.
+var MonthNames = [12]string{
+	"Januari", "Februari", "Mars", "April", "Maj", "Juni",
+	"Juli", "Augusti", "September", "Oktober", "November", "December",
+}
+
+// MonthName returns the Swedish name of m, e.g. "Mars".
+func MonthName(m time.Month) string {
+	return MonthNames[m-1]
+}
+
+// ParseMonth returns the month named by a Swedish month name in any case,
+// e.g. "mars" or "OKTOBER".
+func ParseMonth(name string) (time.Month, bool) {
+	name = strings.TrimSpace(name)
+	for i, n := range MonthNames {
+		if strings.EqualFold(n, name) {
+			return time.Month(i + 1), true
+		}
+	}
+	return 0, false
+}
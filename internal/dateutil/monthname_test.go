@@ -0,0 +1,36 @@
+package dateutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMonth(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   time.Month
+		wantOK bool
+	}{
+		{"Januari", time.January, true},
+		{"mars", time.March, true},
+		{"OKTOBER", time.October, true},
+		{" maj ", time.May, true},
+		{"March", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseMonth(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseMonth(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	for m := time.January; m <= time.December; m++ {
+		if got, ok := ParseMonth(MonthName(m)); !ok || got != m {
+			t.Errorf("ParseMonth(MonthName(%v)) = %v, %v", m, got, ok)
+		}
+	}
+}
@@ -1,29 +1,131 @@
 package email
 
 import (
+	"bytes"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 )
 
-// SMTPConfig holds SMTP configuration for sending emails.
-type SMTPConfig struct {
+// Sender sends emails.
+type Sender interface {
+	// Send sends a plain-text email.
+	Send(subject, body string, to []string) error
+	// SendMessage sends msg, with an HTML alternative if it has one.
+	SendMessage(msg Message) error
+}
+
+// SMTPSender sends emails through an SMTP server, from the account it logs
+// in as.
+type SMTPSender struct {
 	Host     string
 	Port     string
 	User     string
 	Password string
-	To       string
 }
 
-// Send sends an email with the given subject and body.
-func (c *SMTPConfig) Send(subject, body string) error {
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
-		c.User, c.To, subject, body)
+// Send sends an email with the given subject and body to the recipients.
+func (s *SMTPSender) Send(subject, body string, to []string) error {
+	return s.SendMessage(Message{Subject: subject, Body: body, To: to})
+}
+
+// SendMessage sends msg to its recipients.
+func (s *SMTPSender) SendMessage(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	data, err := buildMessage(s.User, msg)
+	if err != nil {
+		return err
+	}
+	auth := smtp.PlainAuth("", s.User, s.Password, s.Host)
+	addr := s.Host + ":" + s.Port
+	return smtp.SendMail(addr, auth, s.User, msg.To, data)
+}
+
+// Message is an email.
+type Message struct {
+	Subject string
+	Body    string // plain text
+	HTML    string // optional HTML alternative of Body
+	ReplyTo string // optional Reply-To address
+	To      []string
+}
+
+// buildMessage formats msg with its headers: UTF-8 plain text, or
+// multipart/alternative when it has an HTML version. A non-ASCII subject is
+// encoded per RFC 2047.
+func buildMessage(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	if msg.ReplyTo != "" {
+		fmt.Fprintf(&buf, "Reply-To: %s\r\n", msg.ReplyTo)
+	}
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+		buf.WriteString("\r\n")
+		buf.WriteString(NormalizeNewlines(msg.Body))
+		return buf.Bytes(), nil
+	}
 
-	auth := smtp.PlainAuth("", c.User, c.Password, c.Host)
-	addr := c.Host + ":" + c.Port
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Body},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating %s part: %w", part.contentType, err)
+		}
+		w.Write([]byte(NormalizeNewlines(part.content)))
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("closing multipart body: %w", err)
+	}
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n", mw.Boundary())
+	buf.WriteString("\r\n")
+	buf.Write(parts.Bytes())
+	return buf.Bytes(), nil
+}
+
+// NoopSender records emails instead of sending them, for tests.
+type NoopSender struct {
+	Sent []Message
+}
+
+// Send records the email.
+func (s *NoopSender) Send(subject, body string, to []string) error {
+	return s.SendMessage(Message{Subject: subject, Body: body, To: to})
+}
+
+// SendMessage records msg.
+func (s *NoopSender) SendMessage(msg Message) error {
+	s.Sent = append(s.Sent, msg)
+	return nil
+}
 
-	return smtp.SendMail(addr, auth, c.User, []string{c.To}, []byte(msg))
+// ParseRecipients splits a comma-separated list of addresses, as in SMTP_TO.
+func ParseRecipients(list string) []string {
+	var to []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	return to
 }
 
 // NormalizeNewlines replaces bare \n and \r with \r\n for SMTP compliance.
@@ -1,6 +1,14 @@
 package email
 
-import "testing"
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
 
 func TestNormalizeNewlines(t *testing.T) {
 	tests := []struct {
@@ -25,3 +33,107 @@ func TestNormalizeNewlines(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildMessage(t *testing.T) {
+	data, err := buildMessage("bot@example.com", Message{
+		Subject: "Feedback: Lägg till församling",
+		Body:    "Rad ett\nRad två",
+		To:      []string{"a@example.com", "b@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+	msg := string(data)
+
+	header, body, ok := strings.Cut(msg, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no blank line between headers and body:\n%q", msg)
+	}
+	for _, want := range []string{
+		"From: bot@example.com",
+		"To: a@example.com, b@example.com",
+		"Subject: =?utf-8?q?Feedback:_L=C3=A4gg_till_f=C3=B6rsamling?=",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	} {
+		if !strings.Contains(header+"\r\n", want+"\r\n") {
+			t.Errorf("headers lack %q:\n%s", want, header)
+		}
+	}
+	if body != "Rad ett\r\nRad två" {
+		t.Errorf("body = %q, want CRLF line endings", body)
+	}
+	if strings.Contains(header, "Reply-To") {
+		t.Errorf("no Reply-To header without a reply address:\n%s", header)
+	}
+
+	ascii, _ := buildMessage("bot@example.com", Message{Subject: "Ingestion alert", To: []string{"a@example.com"}})
+	if !strings.Contains(string(ascii), "Subject: Ingestion alert\r\n") {
+		t.Errorf("an ASCII subject should not be encoded:\n%s", ascii)
+	}
+}
+
+func TestBuildMessageMultipart(t *testing.T) {
+	data, err := buildMessage("bot@example.com", Message{
+		Subject: "Feedback",
+		Body:    "Hej\nvärlden",
+		HTML:    "<p>Hej<br>världen</p>",
+		ReplyTo: "user@example.com",
+		To:      []string{"a@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	if got := msg.Header.Get("Reply-To"); got != "user@example.com" {
+		t.Errorf("Reply-To = %q, want user@example.com", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", msg.Header.Get("Content-Type"), err)
+	}
+
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Hej\r\nvärlden"},
+		{"text/html; charset=utf-8", "<p>Hej<br>världen</p>"},
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for i, w := range want {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		body, _ := io.ReadAll(part)
+		if got := part.Header.Get("Content-Type"); got != w.contentType || string(body) != w.body {
+			t.Errorf("part %d = %s %q, want %s %q", i, got, body, w.contentType, w.body)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("want exactly two parts, got err %v", err)
+	}
+}
+
+func TestNoopSender(t *testing.T) {
+	var s Sender = &NoopSender{}
+	if err := s.Send("Hej", "Text", []string{"a@example.com"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sent := s.(*NoopSender).Sent
+	if len(sent) != 1 || sent[0].Subject != "Hej" || sent[0].Body != "Text" || sent[0].To[0] != "a@example.com" {
+		t.Errorf("sent = %+v", sent)
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	got := ParseRecipients(" a@example.com, ,b@example.com ")
+	if strings.Join(got, "|") != "a@example.com|b@example.com" {
+		t.Errorf("ParseRecipients = %q", got)
+	}
+	if ParseRecipients("") != nil {
+		t.Error("an empty list should give no recipients")
+	}
+}
@@ -14,25 +14,70 @@ import (
 	"ortodoxa-gudstjanster/internal/umap"
 )
 
-const batchSize = 250 // Stay well under Firestore's 500 operation limit
+const (
+	defaultBatchSize = 250 // Stay well under Firestore's 500 operation limit
+	maxBatchSize     = 500 // Firestore's limit on writes per batch commit
+)
 
 // Client wraps the Firestore client for church service operations.
 type Client struct {
 	client              *firestore.Client
 	collection          string
 	onParishesReloaded  func([]umap.Parish)
+	batchSize           int
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBatchSize sets the number of writes per batch commit (default 250,
+// at most 500). Larger batches mean fewer round-trips for bulk backfills.
+func WithBatchSize(n int) Option {
+	return func(c *Client) {
+		c.batchSize = n
+	}
 }
 
 // New creates a new Firestore client.
-func New(ctx context.Context, projectID, collection string) (*Client, error) {
+func New(ctx context.Context, projectID, collection string, opts ...Option) (*Client, error) {
+	c := &Client{
+		collection: collection,
+		batchSize:  defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := validateBatchSize(c.batchSize); err != nil {
+		return nil, err
+	}
+
 	client, err := firestore.NewClient(ctx, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("creating firestore client: %w", err)
 	}
-	return &Client{
-		client:     client,
-		collection: collection,
-	}, nil
+	c.client = client
+	return c, nil
+}
+
+func validateBatchSize(n int) error {
+	if n < 1 || n > maxBatchSize {
+		return fmt.Errorf("batch size must be between 1 and %d, got %d", maxBatchSize, n)
+	}
+	return nil
+}
+
+// forEachBatch calls fn with consecutive chunks of at most size services.
+func forEachBatch(services []model.ChurchService, size int, fn func([]model.ChurchService) error) error {
+	for i := 0; i < len(services); i += size {
+		end := i + size
+		if end > len(services) {
+			end = len(services)
+		}
+		if err := fn(services[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Close closes the Firestore client.
@@ -40,25 +85,21 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
-// ReplaceServicesForScraper atomically replaces all services for a scraper.
-// It deletes all existing documents for the scraper, then writes the new ones.
+// ReplaceServicesForScraper replaces all services for a scraper. It writes
+// the new documents tagged with batchID first, then prunes the scraper's
+// documents from earlier batches, so a crash part-way leaves the old and new
+// services side by side rather than none at all.
 func (c *Client) ReplaceServicesForScraper(ctx context.Context, scraperName string, services []model.ChurchService, batchID string) error {
-	coll := c.client.Collection(c.collection)
-
-	// First, delete all existing documents for this scraper
-	if err := c.deleteServicesForScraper(ctx, scraperName); err != nil {
-		return fmt.Errorf("deleting existing services: %w", err)
+	if err := validateBatchSize(c.batchSize); err != nil {
+		return err
 	}
+	coll := c.client.Collection(c.collection)
 
-	// Then, write new documents in batches
-	for i := 0; i < len(services); i += batchSize {
-		end := i + batchSize
-		if end > len(services) {
-			end = len(services)
-		}
+	// First, write new documents in batches
+	err := forEachBatch(services, c.batchSize, func(chunk []model.ChurchService) error {
 		batch := c.client.Batch()
 
-		for _, svc := range services[i:end] {
+		for _, svc := range chunk {
 			docID := generateDocID(svc)
 			doc := coll.Doc(docID)
 			batch.Set(doc, serviceToMap(svc, scraperName, batchID))
@@ -67,24 +108,56 @@ func (c *Client) ReplaceServicesForScraper(ctx context.Context, scraperName stri
 		if _, err := batch.Commit(ctx); err != nil {
 			return fmt.Errorf("committing batch: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	// Then, remove whatever the new batch did not overwrite
+	if err := c.PruneOldBatches(ctx, scraperName, batchID); err != nil {
+		return fmt.Errorf("pruning old services: %w", err)
+	}
 	return nil
 }
 
-// deleteServicesForScraper deletes all documents for a given scraper.
-// It first deletes by scraper_name (new docs), then cleans up legacy docs
-// where source matches but scraper_name is absent.
-func (c *Client) deleteServicesForScraper(ctx context.Context, scraperName string) error {
+// PruneOldBatches deletes the documents for a scraper whose batch_id differs
+// from keepBatchID. It also cleans up legacy docs where source matches but
+// scraper_name is absent.
+func (c *Client) PruneOldBatches(ctx context.Context, scraperName, keepBatchID string) error {
 	coll := c.client.Collection(c.collection)
 
-	// Delete docs with scraper_name == scraperName
-	if err := c.deleteDocs(ctx, coll.Where("scraper_name", "==", scraperName)); err != nil {
-		return err
+	// Delete docs with scraper_name == scraperName from other batches
+	var stale []*firestore.DocumentRef
+	iter := coll.Where("scraper_name", "==", scraperName).Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iterating documents: %w", err)
+		}
+		if batchID, _ := doc.Data()["batch_id"].(string); batchID != keepBatchID {
+			stale = append(stale, doc.Ref)
+		}
+	}
+	for i := 0; i < len(stale); i += c.batchSize {
+		end := i + c.batchSize
+		if end > len(stale) {
+			end = len(stale)
+		}
+		batch := c.client.Batch()
+		for _, ref := range stale[i:end] {
+			batch.Delete(ref)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return fmt.Errorf("committing delete batch: %w", err)
+		}
 	}
 
 	// Legacy cleanup: delete docs where source == scraperName and scraper_name is absent
-	iter := coll.Where("source", "==", scraperName).Documents(ctx)
+	iter = coll.Where("source", "==", scraperName).Documents(ctx)
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
@@ -106,7 +179,7 @@ func (c *Client) deleteServicesForScraper(ctx context.Context, scraperName strin
 // deleteDocs deletes all documents matching a query in batches.
 func (c *Client) deleteDocs(ctx context.Context, query firestore.Query) error {
 	for {
-		iter := query.Limit(batchSize).Documents(ctx)
+		iter := query.Limit(c.batchSize).Documents(ctx)
 		batch := c.client.Batch()
 		numDeleted := 0
 
@@ -130,24 +203,100 @@ func (c *Client) deleteDocs(ctx context.Context, query firestore.Query) error {
 			return fmt.Errorf("committing delete batch: %w", err)
 		}
 
-		if numDeleted < batchSize {
+		if numDeleted < c.batchSize {
 			return nil
 		}
 	}
 }
 
-// GetAllServices retrieves all services from Firestore.
+// pageSize is the number of documents GetAllServices reads per query.
+const pageSize = 500
+
+// GetAllServices retrieves all services from Firestore, reading the
+// collection one page at a time.
 func (c *Client) GetAllServices(ctx context.Context) ([]model.ChurchService, error) {
 	var services []model.ChurchService
 
-	iter := c.client.Collection(c.collection).Documents(ctx)
+	cursor := ""
+	for {
+		page, next, err := c.GetServicesPage(ctx, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, page...)
+		if next == "" {
+			return services, nil
+		}
+		cursor = next
+	}
+}
+
+// GetServicesPage retrieves up to pageSize services ordered by document ID,
+// starting after the document ID startAfter (or from the beginning if empty).
+// nextCursor is the value to pass as startAfter for the following page, or
+// empty when there are no more documents.
+func (c *Client) GetServicesPage(ctx context.Context, pageSize int, startAfter string) (services []model.ChurchService, nextCursor string, err error) {
+	if pageSize <= 0 {
+		return nil, "", fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	query := c.client.Collection(c.collection).OrderBy(firestore.DocumentID, firestore.Asc)
+	if startAfter != "" {
+		query = query.StartAfter(startAfter)
+	}
+
+	iter := query.Limit(pageSize).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("iterating documents: %w", err)
+		}
+
+		svc, err := mapToService(doc.Data())
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing document %s: %w", doc.Ref.ID, err)
+		}
+		svc.ID = doc.Ref.ID
+		services = append(services, svc)
+	}
+
+	if len(services) == pageSize {
+		nextCursor = services[len(services)-1].ID
+	}
+	return services, nextCursor, nil
+}
+
+// GetServices retrieves services ordered by date, optionally filtered by
+// source and by an inclusive date range (YYYY-MM-DD). Empty arguments leave
+// that filter out. Filtering on both source and date needs the
+// services_source_date composite index (terraform/firestore.tf).
+func (c *Client) GetServices(ctx context.Context, source, from, to string) ([]model.ChurchService, error) {
+	query := c.client.Collection(c.collection).Query
+	if source != "" {
+		query = query.Where("source", "==", source)
+	}
+	if from != "" {
+		query = query.Where("date", ">=", from)
+	}
+	if to != "" {
+		query = query.Where("date", "<=", to)
+	}
+	query = query.OrderBy("date", firestore.Asc)
+
+	var services []model.ChurchService
+	iter := query.Documents(ctx)
+	defer iter.Stop()
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("iterating documents: %w", err)
+			return nil, fmt.Errorf("querying services for source %q from %q to %q: %w", source, from, to, err)
 		}
 
 		svc, err := mapToService(doc.Data())
@@ -195,28 +344,33 @@ func (c *Client) CountServicesForScraper(ctx context.Context, scraperName string
 	return count, nil
 }
 
-// CountFutureServicesForScraper returns the number of stored services for a
-// given scraper with date >= today (YYYY-MM-DD).
-func (c *Client) CountFutureServicesForScraper(ctx context.Context, scraperName string) (int, error) {
+// GetFutureServicesForScraper returns the services from today on that the
+// given scraper produced.
+func (c *Client) GetFutureServicesForScraper(ctx context.Context, scraperName string) ([]model.ChurchService, error) {
 	today := time.Now().Format("2006-01-02")
-	query := c.client.Collection(c.collection).
+	iter := c.client.Collection(c.collection).
 		Where("scraper_name", "==", scraperName).
-		Where("date", ">=", today)
-	count := 0
+		Where("date", ">=", today).
+		Documents(ctx)
+	defer iter.Stop()
 
-	iter := query.Documents(ctx)
+	var services []model.ChurchService
 	for {
-		_, err := iter.Next()
+		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return 0, fmt.Errorf("counting future documents for scraper %s: %w", scraperName, err)
+			return nil, fmt.Errorf("querying future services for scraper %s: %w", scraperName, err)
 		}
-		count++
+		svc, err := mapToService(doc.Data())
+		if err != nil {
+			return nil, fmt.Errorf("parsing document %s: %w", doc.Ref.ID, err)
+		}
+		svc.ID = doc.Ref.ID
+		services = append(services, svc)
 	}
-
-	return count, nil
+	return services, nil
 }
 
 // GetLatestBatchID returns the most recent batch_id from the collection.
@@ -238,6 +392,46 @@ func (c *Client) GetLatestBatchID(ctx context.Context) (string, error) {
 	return batchID, nil
 }
 
+// BatchIDLayout is the time layout of batch IDs written by the ingest job:
+// the UTC time the batch started.
+const BatchIDLayout = "20060102-150405"
+
+// GetLastIngestTime returns the time of the most recent ingest batch, and the
+// most recent batch per source, parsed from the documents' batch_id (UTC).
+// Documents with a missing or malformed batch_id are ignored. The zero time
+// is returned if no document has a batch_id.
+func (c *Client) GetLastIngestTime(ctx context.Context) (time.Time, map[string]time.Time, error) {
+	var latest time.Time
+	perSource := make(map[string]time.Time)
+
+	iter := c.client.Collection(c.collection).Select("source", "batch_id").Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("iterating documents: %w", err)
+		}
+
+		batchID, _ := doc.Data()["batch_id"].(string)
+		t, err := time.Parse(BatchIDLayout, batchID)
+		if err != nil {
+			continue
+		}
+		if t.After(latest) {
+			latest = t
+		}
+		source, _ := doc.Data()["source"].(string)
+		if t.After(perSource[source]) {
+			perSource[source] = t
+		}
+	}
+
+	return latest, perSource, nil
+}
+
 // generateDocID creates a unique document ID based on service fields.
 func generateDocID(svc model.ChurchService) string {
 	timeStr := ""
@@ -296,6 +490,9 @@ func serviceToMap(svc model.ChurchService, scraperName string, batchID string) m
 	if svc.EndTime != nil {
 		m["end_time"] = svc.EndTime.Format(time.RFC3339)
 	}
+	if svc.Uncertain {
+		m["uncertain"] = true
+	}
 	return m
 }
 
@@ -367,6 +564,9 @@ func mapToService(m map[string]interface{}) (model.ChurchService, error) {
 			svc.EndTime = &t
 		}
 	}
+	if v, ok := m["uncertain"].(bool); ok {
+		svc.Uncertain = v
+	}
 
 	return svc, nil
 }
@@ -383,8 +583,8 @@ func (c *Client) SaveParishes(ctx context.Context, parishes []umap.Parish) error
 	}
 
 	// Write new
-	for i := 0; i < len(parishes); i += batchSize {
-		end := i + batchSize
+	for i := 0; i < len(parishes); i += c.batchSize {
+		end := i + c.batchSize
 		if end > len(parishes) {
 			end = len(parishes)
 		}
@@ -438,3 +638,53 @@ func (c *Client) GetParishes(ctx context.Context) ([]umap.Parish, error) {
 	}
 	return parishes, nil
 }
+
+const batchCollection = "batches"
+
+// BatchSummary records which scrapers succeeded and failed in one ingest run,
+// and how its vision extractions went.
+type BatchSummary struct {
+	BatchID   string         `firestore:"batch_id"`
+	Succeeded []string       `firestore:"succeeded"`
+	Failed    []string       `firestore:"failed"`
+	Extracts  []ExtractCount `firestore:"extracts"`
+}
+
+// ExtractCount is the number of vision extractions in one ingest run for a
+// source and result (success, error or empty).
+type ExtractCount struct {
+	Source string `firestore:"source"`
+	Result string `firestore:"result"`
+	Count  int64  `firestore:"count"`
+}
+
+// SaveBatchSummary stores the summary of an ingest run, keyed by batch ID.
+func (c *Client) SaveBatchSummary(ctx context.Context, summary BatchSummary) error {
+	if _, err := c.client.Collection(batchCollection).Doc(summary.BatchID).Set(ctx, summary); err != nil {
+		return fmt.Errorf("saving batch summary %s: %w", summary.BatchID, err)
+	}
+	return nil
+}
+
+// GetLatestBatchSummary returns the summary of the most recent ingest run, or
+// nil if none has been saved.
+func (c *Client) GetLatestBatchSummary(ctx context.Context) (*BatchSummary, error) {
+	iter := c.client.Collection(batchCollection).
+		OrderBy("batch_id", firestore.Desc).
+		Limit(1).
+		Documents(ctx)
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying latest batch summary: %w", err)
+	}
+
+	var summary BatchSummary
+	if err := doc.DataTo(&summary); err != nil {
+		return nil, fmt.Errorf("parsing batch summary %s: %w", doc.Ref.ID, err)
+	}
+	return &summary, nil
+}
@@ -0,0 +1,238 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+// newEmulatorClient returns a client for a fresh collection in the Firestore
+// emulator, skipping the test unless FIRESTORE_EMULATOR_HOST is set.
+func newEmulatorClient(t *testing.T) *Client {
+	t.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set")
+	}
+	collection := fmt.Sprintf("%s-%d", strings.ReplaceAll(t.Name(), "/", "-"), time.Now().UnixNano())
+	c, err := New(context.Background(), "test-project", collection)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestGetServicesPageEmulator(t *testing.T) {
+	c := newEmulatorClient(t)
+	ctx := context.Background()
+
+	const total = 600
+	services := make([]model.ChurchService, total)
+	for i := range services {
+		services[i] = model.ChurchService{
+			Source:      "Test Parish",
+			Date:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i).Format("2006-01-02"),
+			ServiceName: fmt.Sprintf("Liturgi %d", i),
+		}
+	}
+	if err := c.ReplaceServicesForScraper(ctx, "Test Parish", services, "batch-1"); err != nil {
+		t.Fatalf("ReplaceServicesForScraper: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var pageSizes []int
+	cursor := ""
+	for {
+		page, next, err := c.GetServicesPage(ctx, 250, cursor)
+		if err != nil {
+			t.Fatalf("GetServicesPage(%q): %v", cursor, err)
+		}
+		pageSizes = append(pageSizes, len(page))
+		for _, svc := range page {
+			if seen[svc.ID] {
+				t.Fatalf("document %s returned twice", svc.ID)
+			}
+			seen[svc.ID] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if fmt.Sprint(pageSizes) != "[250 250 100]" {
+		t.Errorf("page sizes = %v, want [250 250 100]", pageSizes)
+	}
+	if len(seen) != total {
+		t.Errorf("paged through %d documents, want %d", len(seen), total)
+	}
+
+	all, err := c.GetAllServices(ctx)
+	if err != nil {
+		t.Fatalf("GetAllServices: %v", err)
+	}
+	if len(all) != total {
+		t.Errorf("GetAllServices returned %d services, want %d", len(all), total)
+	}
+}
+
+func TestGetServicesEmulator(t *testing.T) {
+	c := newEmulatorClient(t)
+	ctx := context.Background()
+
+	write := func(source string, dates ...string) {
+		var services []model.ChurchService
+		for _, d := range dates {
+			services = append(services, model.ChurchService{Source: source, Date: d, ServiceName: "Liturgi"})
+		}
+		if err := c.ReplaceServicesForScraper(ctx, source, services, "batch-1"); err != nil {
+			t.Fatalf("ReplaceServicesForScraper(%s): %v", source, err)
+		}
+	}
+	write("A", "2026-03-01", "2026-03-08", "2026-03-15")
+	write("B", "2026-03-02", "2026-03-09")
+
+	tests := []struct {
+		name        string
+		source      string
+		from, to    string
+		wantSources string
+		wantDates   string
+	}{
+		{"source only", "A", "", "", "AAA", "2026-03-01,2026-03-08,2026-03-15"},
+		{"range only", "", "2026-03-02", "2026-03-09", "BAB", "2026-03-02,2026-03-08,2026-03-09"},
+		{"source and range", "A", "2026-03-05", "2026-03-31", "AA", "2026-03-08,2026-03-15"},
+		{"no filters", "", "", "", "ABABA", "2026-03-01,2026-03-02,2026-03-08,2026-03-09,2026-03-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services, err := c.GetServices(ctx, tt.source, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetServices: %v", err)
+			}
+			var sources string
+			var dates []string
+			for _, svc := range services {
+				sources += svc.Source
+				dates = append(dates, svc.Date)
+			}
+			if sources != tt.wantSources || strings.Join(dates, ",") != tt.wantDates {
+				t.Errorf("got sources %q dates %v, want %q %s", sources, dates, tt.wantSources, tt.wantDates)
+			}
+		})
+	}
+}
+
+func TestReplaceServicesPrunesOldBatchEmulator(t *testing.T) {
+	c := newEmulatorClient(t)
+	ctx := context.Background()
+
+	old := []model.ChurchService{
+		{Source: "A", Date: "2026-03-01", ServiceName: "Liturgi"},
+		{Source: "A", Date: "2026-03-08", ServiceName: "Liturgi"},
+	}
+	if err := c.ReplaceServicesForScraper(ctx, "A", old, "20260301-000000"); err != nil {
+		t.Fatalf("seeding old batch: %v", err)
+	}
+	other := []model.ChurchService{{Source: "B", Date: "2026-03-01", ServiceName: "Vesper"}}
+	if err := c.ReplaceServicesForScraper(ctx, "B", other, "20260301-000000"); err != nil {
+		t.Fatalf("seeding other scraper: %v", err)
+	}
+
+	// The 2026-03-08 service is in both batches; 2026-03-01 is dropped.
+	current := []model.ChurchService{
+		{Source: "A", Date: "2026-03-08", ServiceName: "Liturgi"},
+		{Source: "A", Date: "2026-03-15", ServiceName: "Liturgi"},
+	}
+	if err := c.ReplaceServicesForScraper(ctx, "A", current, "20260308-000000"); err != nil {
+		t.Fatalf("ingesting new batch: %v", err)
+	}
+
+	services, err := c.GetServices(ctx, "A", "", "")
+	if err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+	var dates []string
+	for _, svc := range services {
+		dates = append(dates, svc.Date)
+	}
+	if strings.Join(dates, ",") != "2026-03-08,2026-03-15" {
+		t.Errorf("scraper A dates = %v, want only the new batch", dates)
+	}
+
+	if others, err := c.GetServices(ctx, "B", "", ""); err != nil || len(others) != 1 {
+		t.Errorf("other scraper's services = %v, %v; want untouched", others, err)
+	}
+}
+
+func TestGetLastIngestTimeEmulator(t *testing.T) {
+	c := newEmulatorClient(t)
+	ctx := context.Background()
+
+	// Two batches per source, written directly so neither is pruned
+	seed := []struct {
+		source  string
+		date    string
+		batchID string
+	}{
+		{"A", "2026-03-01", "20260301-040000"},
+		{"A", "2026-03-08", "20260308-040000"},
+		{"B", "2026-03-01", "20260301-040000"},
+		{"B", "2026-03-02", "20260302-040000"},
+		{"C", "2026-03-03", "not-a-batch"},
+	}
+	for _, s := range seed {
+		svc := model.ChurchService{Source: s.source, Date: s.date, ServiceName: "Liturgi"}
+		doc := c.client.Collection(c.collection).Doc(generateDocID(svc))
+		if _, err := doc.Set(ctx, serviceToMap(svc, s.source, s.batchID)); err != nil {
+			t.Fatalf("seeding %s: %v", s.batchID, err)
+		}
+	}
+
+	latest, perSource, err := c.GetLastIngestTime(ctx)
+	if err != nil {
+		t.Fatalf("GetLastIngestTime: %v", err)
+	}
+
+	if want := time.Date(2026, 3, 8, 4, 0, 0, 0, time.UTC); !latest.Equal(want) {
+		t.Errorf("latest = %v, want %v", latest, want)
+	}
+	if got := perSource["A"]; !got.Equal(time.Date(2026, 3, 8, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("perSource[A] = %v", got)
+	}
+	if got := perSource["B"]; !got.Equal(time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("perSource[B] = %v", got)
+	}
+	if _, ok := perSource["C"]; ok {
+		t.Error("source with a malformed batch_id should be left out")
+	}
+}
+
+func TestBatchSummaryEmulator(t *testing.T) {
+	c := newEmulatorClient(t)
+	ctx := context.Background()
+
+	// The batches collection is shared, so only the relative order is checked
+	for _, s := range []BatchSummary{
+		{BatchID: "99990301-040000", Succeeded: []string{"A"}, Failed: []string{"B"}},
+		{BatchID: "99990308-040000", Succeeded: []string{"A", "B"}},
+	} {
+		if err := c.SaveBatchSummary(ctx, s); err != nil {
+			t.Fatalf("SaveBatchSummary(%s): %v", s.BatchID, err)
+		}
+	}
+
+	summary, err := c.GetLatestBatchSummary(ctx)
+	if err != nil {
+		t.Fatalf("GetLatestBatchSummary: %v", err)
+	}
+	if summary == nil || summary.BatchID != "99990308-040000" || len(summary.Failed) != 0 {
+		t.Errorf("latest summary = %+v, want batch 99990308-040000 with no failures", summary)
+	}
+}
@@ -1,6 +1,8 @@
 package firestore
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -71,6 +73,7 @@ func TestServiceToMapAndBack(t *testing.T) {
 		EventLanguage:  &el,
 		StartTime:      &startTime,
 		EndTime:        &endTime,
+		Uncertain:      true,
 	}
 
 	m := serviceToMap(original, "test-scraper", "batch-001")
@@ -126,6 +129,9 @@ func TestServiceToMapAndBack(t *testing.T) {
 	if roundtrip.EventLanguage == nil || *roundtrip.EventLanguage != el {
 		t.Errorf("EventLanguage = %v, want %q", roundtrip.EventLanguage, el)
 	}
+	if !roundtrip.Uncertain {
+		t.Error("Uncertain flag was lost")
+	}
 }
 
 func TestMapToServiceParishFallback(t *testing.T) {
@@ -225,3 +231,62 @@ func TestServiceToMapOmitsEmptyOptionals(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateBatchSize(t *testing.T) {
+	tests := []struct {
+		size    int
+		wantErr bool
+	}{
+		{1, false},
+		{250, false},
+		{500, false},
+		{0, true},
+		{-1, true},
+		{501, true},
+	}
+	for _, tt := range tests {
+		if err := validateBatchSize(tt.size); (err != nil) != tt.wantErr {
+			t.Errorf("validateBatchSize(%d) error = %v, wantErr %v", tt.size, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewRejectsInvalidBatchSize(t *testing.T) {
+	if _, err := New(context.Background(), "test-project", "services", WithBatchSize(600)); err == nil {
+		t.Error("New should reject a batch size above 500")
+	}
+}
+
+func TestReplaceServicesRejectsInvalidBatchSize(t *testing.T) {
+	c := &Client{collection: "services", batchSize: 0}
+	if err := c.ReplaceServicesForScraper(context.Background(), "Test", nil, "batch"); err == nil {
+		t.Error("ReplaceServicesForScraper should reject a zero batch size")
+	}
+}
+
+func TestForEachBatch(t *testing.T) {
+	services := make([]model.ChurchService, 600)
+
+	tests := []struct {
+		size        int
+		wantCommits []int
+	}{
+		{250, []int{250, 250, 100}},
+		{500, []int{500, 100}},
+		{600, []int{600}},
+		{100, []int{100, 100, 100, 100, 100, 100}},
+	}
+	for _, tt := range tests {
+		var commits []int
+		err := forEachBatch(services, tt.size, func(chunk []model.ChurchService) error {
+			commits = append(commits, len(chunk))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("forEachBatch: %v", err)
+		}
+		if fmt.Sprint(commits) != fmt.Sprint(tt.wantCommits) {
+			t.Errorf("batch size %d: commits = %v, want %v", tt.size, commits, tt.wantCommits)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package ical holds the iCalendar (RFC 5545) text helpers shared by the
+// calendar feeds and the command-line exporters.
+package ical
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Escape escapes text for use in an iCalendar TEXT property value.
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, ";", "\\;")
+	s = strings.ReplaceAll(s, ",", "\\,")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
+
+// clockPattern matches a time of day at the start of a string: "9:00",
+// "18:00", "8.15" or "1800".
+var clockPattern = regexp.MustCompile(`^(?:(\d{1,2})[:.](\d{2})|(\d{2})(\d{2}))`)
+
+// rangeSeparator splits a time range such as "18:00 - 20:00" or "18.00–20.00".
+var rangeSeparator = regexp.MustCompile(`\s*[-–—]\s*`)
+
+// approxMarker matches the "kl." prefix and "ca"/"cirka" tokens that surround
+// times in parish texts, e.g. "kl. 9:00" or "1800 - ca 2000".
+var approxMarker = regexp.MustCompile(`(?i)^kl\.?\s*|\b(?:ca|cirka)\b\.?\s*`)
+
+// ParseStartTime extracts the start time from a time string and returns it in HHMMSS format.
+// Handles formats like "9:00", "18:00", "8.15", "1800", "kl. 9:00",
+// "18:00 - 20:00", "1800 - ca 2000", etc. It returns "" if no valid start
+// time can be found, so that the service is shown as an all-day event.
+func ParseStartTime(timeStr string) string {
+	start, _ := ParseTimeRange(timeStr)
+	return start
+}
+
+// ParseTimeRange is like ParseStartTime but also returns the end time, in
+// HHMMSS format, if timeStr is a range. end is "" for a single time or an
+// unparseable end.
+func ParseTimeRange(timeStr string) (start, end string) {
+	parts := rangeSeparator.Split(strings.TrimSpace(timeStr), 2)
+	start = parseClock(parts[0])
+	if start != "" && len(parts) == 2 {
+		end = parseClock(parts[1])
+	}
+	return start, end
+}
+
+// parseClock parses a single time of day, ignoring "kl." and "ca" markers.
+func parseClock(s string) string {
+	s = strings.TrimSpace(approxMarker.ReplaceAllString(strings.TrimSpace(s), ""))
+
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	hour, minute := m[1], m[2]
+	if hour == "" {
+		hour, minute = m[3], m[4]
+	}
+	hh, _ := strconv.Atoi(hour)
+	mm, _ := strconv.Atoi(minute)
+	if hh > 23 || mm > 59 {
+		return ""
+	}
+	return fmt.Sprintf("%02d%02d00", hh, mm)
+}
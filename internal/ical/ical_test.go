@@ -0,0 +1,98 @@
+package ical
+
+import "testing"
+
+func TestParseStartTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"18:00", "180000"},
+		{"9:30", "093000"},
+		{"9:00", "090000"},
+		{"8.15", "081500"},
+		{"18.00 - 20.00", "180000"},
+		{"18:00 - 20:00", "180000"},
+		{"18:00 – 20:00", "180000"},
+		{"1800", "180000"},
+		{"1800 - ca 2000", "180000"},
+		{"08:30", "083000"},
+		{"", ""},
+		{"TBD", ""},
+		{"kväll", ""},
+		{"18", ""},
+		{"1:5", ""},
+		// Invalid values should be rejected
+		{"99:99", ""},
+		{"25:00", ""},
+		{"12:60", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseStartTime(tt.input)
+			if got != tt.want {
+				t.Errorf("ParseStartTime(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"simple text", "simple text"},
+		{"semi;colon", "semi\\;colon"},
+		{"com,ma", "com\\,ma"},
+		{"new\nline", "new\\nline"},
+		{"back\\slash", "back\\\\slash"},
+		{"all;of,them\nhere\\now", "all\\;of\\,them\\nhere\\\\now"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Escape(tt.input)
+			if got != tt.want {
+				t.Errorf("Escape(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantStart string
+		wantEnd   string
+	}{
+		// Finska: free text after "Tid:"
+		{"18:00 - 20:00", "180000", "200000"},
+		{"1800 - ca 2000", "180000", "200000"},
+		{"kl. 18.00", "180000", ""},
+		{"ca 17:30", "173000", ""},
+		{"cirka 17.30 – 19.00", "173000", "190000"},
+		// Heliga Anna: "kl. 9.00" in the page text, normalized to "9:00"
+		{"kl. 9.00", "090000", ""},
+		{"kl 9:00", "090000", ""},
+		{"9:00", "090000", ""},
+		// Gomos: OCR output
+		{"10:00", "100000", ""},
+		{"17.00-18.30", "170000", "183000"},
+		{"Kl. 10:00 - ca. 12:00", "100000", "120000"},
+		// Unparseable parts
+		{"18:00 - sent", "180000", ""},
+		{"efter liturgin - 13:00", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			start, end := ParseTimeRange(tt.input)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("ParseTimeRange(%q) = %q, %q; want %q, %q", tt.input, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
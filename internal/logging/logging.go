@@ -0,0 +1,84 @@
+// Package logging configures structured JSON logging for the commands.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ParseLevel parses a LOG_LEVEL value: "debug", "info", "warn" (or
+// "warning") or "error", in any case. An empty value means info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// New returns a logger that writes records at or above level to w as JSON lines.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Setup configures logging for a command. It logs JSON to stderr at the
+// level named by LOG_LEVEL, makes that logger slog's default and routes the
+// standard log package through it, so that older log.Printf calls are
+// structured too. It returns the logger for passing to packages that take one.
+func Setup() *slog.Logger {
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	logger := New(os.Stderr, level)
+	slog.SetDefault(logger)
+	Redirect(logger)
+	if err != nil {
+		logger.Warn("ignoring LOG_LEVEL, using info", "err", err)
+	}
+	return logger
+}
+
+// Redirect sends output of the standard log package to logger, one record
+// per line. Lines starting with "WARNING:" or "ERROR:" (in any case) are
+// logged at those levels without the prefix, everything else at info.
+func Redirect(logger *slog.Logger) {
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(legacyWriter{logger})
+}
+
+type legacyWriter struct {
+	logger *slog.Logger
+}
+
+func (w legacyWriter) Write(p []byte) (int, error) {
+	level, msg := splitLevel(strings.TrimRight(string(p), "\n"))
+	w.logger.Log(context.Background(), level, msg)
+	return len(p), nil
+}
+
+// splitLevel strips a level prefix such as "WARNING: " from msg.
+func splitLevel(msg string) (slog.Level, string) {
+	prefixes := []struct {
+		prefix string
+		level  slog.Level
+	}{
+		{"warning:", slog.LevelWarn},
+		{"error:", slog.LevelError},
+	}
+	for _, p := range prefixes {
+		if len(msg) >= len(p.prefix) && strings.EqualFold(msg[:len(p.prefix)], p.prefix) {
+			return p.level, strings.TrimSpace(msg[len(p.prefix):])
+		}
+	}
+	return slog.LevelInfo, msg
+}
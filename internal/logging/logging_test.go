@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{" Warning ", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.LstdFlags)
+
+	var buf bytes.Buffer
+	Redirect(New(&buf, slog.LevelWarn))
+
+	log.Printf("Parishes reloaded: %d parishes", 12) // below the level
+	log.Printf("WARNING: caching services for %s: %v", "A", "disk full")
+	log.Printf("error: fetching services: timeout")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	want := []struct{ level, msg string }{
+		{"WARN", "caching services for A: disk full"},
+		{"ERROR", "fetching services: timeout"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d:\n%s", len(records), len(want), buf.String())
+	}
+	for i, w := range want {
+		if records[i]["level"] != w.level || records[i]["msg"] != w.msg {
+			t.Errorf("record %d = %v, want level %s msg %q", i, records[i], w.level, w.msg)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecurringSpec describes a service held every week on fixed weekdays at a
+// fixed time, e.g. "Helig Liturgi every Sunday at 09:30".
+type RecurringSpec struct {
+	ServiceName string
+	Weekdays    []time.Weekday
+	Time        string // HH:MM
+	// Holidays lists additional dates (YYYY-MM-DD) on which the service is
+	// held regardless of weekday. Optional; used for "Sundays and holidays"
+	// schedules when the caller has a holiday calendar.
+	Holidays []string
+}
+
+// RecurrenceRule marks a service as the first of a weekly series, so calendar
+// feeds can emit one event with an RRULE instead of one event per week.
+type RecurrenceRule struct {
+	Weekdays []time.Weekday `json:"weekdays"`
+	Until    string         `json:"until"`             // last day (YYYY-MM-DD, inclusive) of the series
+	ExDates  []string       `json:"exdates,omitempty"` // dates (YYYY-MM-DD) on which the service is not held
+}
+
+// ExpandRecurring expands recurring specs into dated services for every day
+// in [from, to), evaluated in loc. Services are ordered by date, then by spec
+// order. StartTime is set in loc, so wall-clock times are kept across DST changes.
+func ExpandRecurring(specs []RecurringSpec, from, to time.Time, loc *time.Location) []ChurchService {
+	var services []ChurchService
+
+	from = from.In(loc)
+	to = to.In(loc)
+	current := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+
+	for current.Before(end) {
+		dateStr := current.Format("2006-01-02")
+		weekday := current.Weekday()
+
+		for _, spec := range specs {
+			if !spec.occursOn(dateStr, weekday) {
+				continue
+			}
+
+			timeStr := spec.Time
+			svc := ChurchService{
+				Date:        dateStr,
+				DayOfWeek:   SwedishWeekday(weekday),
+				ServiceName: spec.ServiceName,
+				Time:        &timeStr,
+			}
+			var h, m int
+			if _, err := fmt.Sscanf(spec.Time, "%d:%d", &h, &m); err == nil {
+				start := time.Date(current.Year(), current.Month(), current.Day(), h, m, 0, 0, loc)
+				svc.StartTime = &start
+			}
+			services = append(services, svc)
+		}
+
+		current = current.AddDate(0, 0, 1)
+	}
+
+	return services
+}
+
+func (s RecurringSpec) occursOn(date string, weekday time.Weekday) bool {
+	for _, wd := range s.Weekdays {
+		if wd == weekday {
+			return true
+		}
+	}
+	for _, h := range s.Holidays {
+		if h == date {
+			return true
+		}
+	}
+	return false
+}
+
+// SwedishWeekday returns the capitalized Swedish name of a weekday, e.g. "Söndag".
+func SwedishWeekday(day time.Weekday) string {
+	switch day {
+	case time.Monday:
+		return "Måndag"
+	case time.Tuesday:
+		return "Tisdag"
+	case time.Wednesday:
+		return "Onsdag"
+	case time.Thursday:
+		return "Torsdag"
+	case time.Friday:
+		return "Fredag"
+	case time.Saturday:
+		return "Lördag"
+	case time.Sunday:
+		return "Söndag"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func mustStockholm(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	return loc
+}
+
+func TestExpandRecurringWeekdaysAcrossMonth(t *testing.T) {
+	loc := mustStockholm(t)
+	specs := []RecurringSpec{
+		{ServiceName: "Helig Liturgi", Weekdays: []time.Weekday{time.Sunday}, Time: "09:30"},
+		{ServiceName: "Aftongudstjänst", Weekdays: []time.Weekday{time.Saturday}, Time: "17:00"},
+	}
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, loc)
+	to := time.Date(2026, time.April, 1, 0, 0, 0, 0, loc)
+
+	services := ExpandRecurring(specs, from, to, loc)
+
+	// March 2026: Sundays 1, 8, 15, 22, 29; Saturdays 7, 14, 21, 28
+	want := []string{
+		"2026-03-01 Helig Liturgi",
+		"2026-03-07 Aftongudstjänst",
+		"2026-03-08 Helig Liturgi",
+		"2026-03-14 Aftongudstjänst",
+		"2026-03-15 Helig Liturgi",
+		"2026-03-21 Aftongudstjänst",
+		"2026-03-22 Helig Liturgi",
+		"2026-03-28 Aftongudstjänst",
+		"2026-03-29 Helig Liturgi",
+	}
+	if len(services) != len(want) {
+		t.Fatalf("got %d services, want %d: %v", len(services), len(want), services)
+	}
+	for i, svc := range services {
+		if got := svc.Date + " " + svc.ServiceName; got != want[i] {
+			t.Errorf("services[%d] = %q, want %q", i, got, want[i])
+		}
+		wantDay := "Söndag"
+		if svc.ServiceName == "Aftongudstjänst" {
+			wantDay = "Lördag"
+		}
+		if svc.DayOfWeek != wantDay {
+			t.Errorf("services[%d].DayOfWeek = %q, want %q", i, svc.DayOfWeek, wantDay)
+		}
+	}
+}
+
+func TestExpandRecurringDSTBoundary(t *testing.T) {
+	loc := mustStockholm(t)
+	specs := []RecurringSpec{
+		{ServiceName: "Helig Liturgi", Weekdays: []time.Weekday{time.Sunday}, Time: "10:00"},
+	}
+	// DST starts in Sweden on 2026-03-29 (CET → CEST).
+	from := time.Date(2026, time.March, 22, 0, 0, 0, 0, loc)
+	to := time.Date(2026, time.April, 6, 0, 0, 0, 0, loc)
+
+	services := ExpandRecurring(specs, from, to, loc)
+	if len(services) != 3 {
+		t.Fatalf("got %d services, want 3", len(services))
+	}
+
+	wantOffsets := []int{3600, 7200, 7200}
+	for i, svc := range services {
+		if svc.StartTime == nil {
+			t.Fatalf("services[%d].StartTime is nil", i)
+		}
+		if h, m := svc.StartTime.Hour(), svc.StartTime.Minute(); h != 10 || m != 0 {
+			t.Errorf("services[%d] starts at %02d:%02d, want 10:00", i, h, m)
+		}
+		if _, offset := svc.StartTime.Zone(); offset != wantOffsets[i] {
+			t.Errorf("services[%d] (%s) offset = %d, want %d", i, svc.Date, offset, wantOffsets[i])
+		}
+	}
+}
+
+func TestExpandRecurringHolidays(t *testing.T) {
+	loc := mustStockholm(t)
+	specs := []RecurringSpec{
+		{ServiceName: "Helig Liturgi", Weekdays: []time.Weekday{time.Sunday}, Time: "09:30", Holidays: []string{"2026-01-06"}},
+	}
+	from := time.Date(2026, time.January, 5, 0, 0, 0, 0, loc)
+	to := time.Date(2026, time.January, 12, 0, 0, 0, 0, loc)
+
+	services := ExpandRecurring(specs, from, to, loc)
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2: %v", len(services), services)
+	}
+	if services[0].Date != "2026-01-06" || services[0].DayOfWeek != "Tisdag" {
+		t.Errorf("services[0] = %s %s, want 2026-01-06 Tisdag", services[0].Date, services[0].DayOfWeek)
+	}
+	if services[1].Date != "2026-01-11" {
+		t.Errorf("services[1].Date = %s, want 2026-01-11", services[1].Date)
+	}
+}
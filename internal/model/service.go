@@ -2,6 +2,14 @@ package model
 
 import "time"
 
+// Service types for calendar entries that are not services.
+const (
+	// ServiceTypeFast marks a fasting period.
+	ServiceTypeFast = "fast"
+	// ServiceTypeCommemoration marks the saint or feast commemorated on a day.
+	ServiceTypeCommemoration = "commemoration"
+)
+
 // ChurchService represents a single church service event.
 type ChurchService struct {
 	ID          string     `json:"id,omitempty"`
@@ -10,9 +18,11 @@ type ChurchService struct {
 	Source      string     `json:"source"`
 	SourceURL   string     `json:"source_url,omitempty"`
 	Date        string     `json:"date"`
+	EndDate     string     `json:"end_date,omitempty"` // last day (inclusive) of a multi-day all-day entry
 	DayOfWeek   string     `json:"day_of_week"`
 	ServiceName string     `json:"service_name"`
 	Title       string     `json:"title,omitempty"`
+	ServiceType string     `json:"service_type,omitempty"` // empty for regular services
 	Location    *string    `json:"location"`
 	Time        *string    `json:"time"`
 	StartTime   *time.Time `json:"start_time,omitempty"`
@@ -22,4 +32,19 @@ type ChurchService struct {
 	Language       *string    `json:"language,omitempty"`
 	ParishLanguage *string    `json:"parish_language,omitempty"`
 	EventLanguage  *string    `json:"event_language,omitempty"`
+	// Recurring is set when the service repeats weekly from Date; nil for
+	// one-off services. It is not stored.
+	Recurring *RecurrenceRule `json:"recurring,omitempty"`
+	// Uncertain marks services whose date or time the OCR model reported
+	// as guessed.
+	Uncertain bool `json:"uncertain,omitempty"`
+}
+
+// DisplayName returns the human-readable name of the service: the short
+// generated title if one is set, otherwise the full service name.
+func (s ChurchService) DisplayName() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.ServiceName
 }
@@ -0,0 +1,17 @@
+package model
+
+import "testing"
+
+func TestDisplayNamePrefersTitle(t *testing.T) {
+	s := ChurchService{ServiceName: "Helig Liturgi på svenska", Title: "Liturgi"}
+	if got := s.DisplayName(); got != "Liturgi" {
+		t.Errorf("DisplayName() = %q, want %q", got, "Liturgi")
+	}
+}
+
+func TestDisplayNameFallsBackToServiceName(t *testing.T) {
+	s := ChurchService{ServiceName: "Helig Liturgi"}
+	if got := s.DisplayName(); got != "Helig Liturgi" {
+		t.Errorf("DisplayName() = %q, want %q", got, "Helig Liturgi")
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+// SourceMetadata describes a parish schedule source independently of its
+// services: where the parish worships, in which languages, and where the
+// schedule is published.
+type SourceMetadata struct {
+	Name      string   `json:"name"`
+	Location  string   `json:"location,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	SourceURL string   `json:"source_url,omitempty"`
+	// Lat and Lng locate Location; both are zero when unknown.
+	Lat float64 `json:"lat,omitempty"`
+	Lng float64 `json:"lng,omitempty"`
+}
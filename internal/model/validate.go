@@ -0,0 +1,38 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/ical"
+)
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Validate checks the fields every scraped service must have: a real
+// YYYY-MM-DD date, a day of week, a service name and, if set, a time the
+// calendar feed can read a start time from (see ical.ParseStartTime). It
+// returns one error per problem, or nil.
+func Validate(s ChurchService) []error {
+	var errs []error
+
+	if !datePattern.MatchString(s.Date) {
+		errs = append(errs, fmt.Errorf("invalid date format: %q", s.Date))
+	} else if _, err := time.Parse("2006-01-02", s.Date); err != nil {
+		errs = append(errs, fmt.Errorf("date out of range: %q", s.Date))
+	}
+	if strings.TrimSpace(s.DayOfWeek) == "" {
+		errs = append(errs, errors.New("day of week is empty"))
+	}
+	if strings.TrimSpace(s.ServiceName) == "" {
+		errs = append(errs, errors.New("service name is empty"))
+	}
+	if s.Time != nil && *s.Time != "" && ical.ParseStartTime(*s.Time) == "" {
+		errs = append(errs, fmt.Errorf("time doesn't look like a time: %q", *s.Time))
+	}
+
+	return errs
+}
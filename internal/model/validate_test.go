@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	valid := ChurchService{Date: "2026-03-08", DayOfWeek: "Söndag", ServiceName: "Helig Liturgi", Time: str("10:00")}
+
+	tests := []struct {
+		name   string
+		modify func(*ChurchService)
+		want   []string // substrings of the expected errors, in order
+	}{
+		{"valid", func(*ChurchService) {}, nil},
+		{"time range", func(s *ChurchService) { s.Time = str("9:30 - 11:00") }, nil},
+		{"no time", func(s *ChurchService) { s.Time = nil }, nil},
+		{"dotted time", func(s *ChurchService) { s.Time = str("8.15") }, nil},
+		{"time without separator", func(s *ChurchService) { s.Time = str("1800") }, nil},
+		{"time with kl.", func(s *ChurchService) { s.Time = str("kl. 9:00") }, nil},
+		{"approximate range", func(s *ChurchService) { s.Time = str("1800 - ca 2000") }, nil},
+		{"bad date format", func(s *ChurchService) { s.Date = "8 mars 2026" }, []string{"invalid date format"}},
+		{"impossible date", func(s *ChurchService) { s.Date = "2026-02-30" }, []string{"date out of range"}},
+		{"empty day of week", func(s *ChurchService) { s.DayOfWeek = "" }, []string{"day of week is empty"}},
+		{"blank service name", func(s *ChurchService) { s.ServiceName = "  " }, []string{"service name is empty"}},
+		{"bad time", func(s *ChurchService) { s.Time = str("kväll") }, []string{"time doesn't look like a time"}},
+		{"several problems", func(s *ChurchService) { *s = ChurchService{Date: "2026-13-01"} },
+			[]string{"date out of range", "day of week is empty", "service name is empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			errs := Validate(s)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d errors", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d = %q, want it to mention %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
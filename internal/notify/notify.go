@@ -0,0 +1,210 @@
+// Package notify reports changes in a source's schedule between ingest runs
+// by email and/or webhook.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/email"
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+// Change is a service that kept its date and name but moved in time or place.
+type Change struct {
+	Old model.ChurchService `json:"old"`
+	New model.ChurchService `json:"new"`
+}
+
+// Diff is the difference between two versions of a source's schedule.
+type Diff struct {
+	Added   []model.ChurchService `json:"added"`
+	Removed []model.ChurchService `json:"removed"`
+	Changed []Change              `json:"changed"`
+}
+
+// Empty reports whether the schedules are the same.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffServices compares the services from today on. Services are matched by
+// date and name; a matched service has changed if its time or location
+// differs. Other fields, such as OCR'd notes, are ignored.
+func DiffServices(old, new []model.ChurchService, today string) Diff {
+	oldByKey := indexServices(old, today)
+	newByKey := indexServices(new, today)
+
+	var d Diff
+	for key, o := range oldByKey {
+		n, ok := newByKey[key]
+		if !ok {
+			d.Removed = append(d.Removed, o)
+		} else if deref(o.Time) != deref(n.Time) || deref(o.Location) != deref(n.Location) {
+			d.Changed = append(d.Changed, Change{Old: o, New: n})
+		}
+	}
+	for key, n := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			d.Added = append(d.Added, n)
+		}
+	}
+
+	sortServices(d.Added)
+	sortServices(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool { return serviceLess(d.Changed[i].New, d.Changed[j].New) })
+	return d
+}
+
+// indexServices keys the services from today on by date and name. Repeated
+// names on the same date are numbered in time order.
+func indexServices(services []model.ChurchService, today string) map[string]model.ChurchService {
+	sorted := make([]model.ChurchService, 0, len(services))
+	for _, s := range services {
+		if s.Date >= today {
+			sorted = append(sorted, s)
+		}
+	}
+	sortServices(sorted)
+
+	index := make(map[string]model.ChurchService, len(sorted))
+	for _, s := range sorted {
+		base := s.Date + "|" + strings.ToLower(strings.TrimSpace(s.ServiceName))
+		key := base
+		for n := 2; ; n++ {
+			if _, taken := index[key]; !taken {
+				break
+			}
+			key = fmt.Sprintf("%s#%d", base, n)
+		}
+		index[key] = s
+	}
+	return index
+}
+
+func sortServices(services []model.ChurchService) {
+	sort.Slice(services, func(i, j int) bool { return serviceLess(services[i], services[j]) })
+}
+
+func serviceLess(a, b model.ChurchService) bool {
+	if a.Date != b.Date {
+		return a.Date < b.Date
+	}
+	if deref(a.Time) != deref(b.Time) {
+		return deref(a.Time) < deref(b.Time)
+	}
+	return a.ServiceName < b.ServiceName
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Notifier sends schedule change notifications. Each transport is optional;
+// with neither configured, notifications are dropped.
+type Notifier struct {
+	Mail       email.Sender
+	MailTo     []string
+	WebhookURL string
+	Client     *http.Client // defaults to a client with a 10s timeout
+}
+
+// webhookPayload is the JSON body POSTed to the webhook.
+type webhookPayload struct {
+	Source string `json:"source"`
+	Diff
+}
+
+// ScheduleChanged notifies about the differences between the old and new
+// services of a source, if there are any from today on.
+func (n *Notifier) ScheduleChanged(source string, old, new []model.ChurchService) error {
+	d := DiffServices(old, new, time.Now().Format("2006-01-02"))
+	if d.Empty() {
+		return nil
+	}
+
+	var errs []error
+	if n.Mail != nil {
+		subject := fmt.Sprintf("Schedule changed: %s", source)
+		if err := n.Mail.Send(subject, FormatDiff(d), n.MailTo); err != nil {
+			errs = append(errs, fmt.Errorf("sending email: %w", err))
+		}
+	}
+	if n.WebhookURL != "" {
+		if err := n.postWebhook(webhookPayload{Source: source, Diff: d}); err != nil {
+			errs = append(errs, fmt.Errorf("posting webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) postWebhook(payload webhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(n.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FormatDiff renders a diff as plain text, one line per service.
+func FormatDiff(d Diff) string {
+	var sb strings.Builder
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "%s:\n", title)
+		for _, l := range lines {
+			fmt.Fprintf(&sb, "- %s\n", l)
+		}
+		sb.WriteString("\n")
+	}
+
+	var added, removed, changed []string
+	for _, s := range d.Added {
+		added = append(added, describe(s))
+	}
+	for _, s := range d.Removed {
+		removed = append(removed, describe(s))
+	}
+	for _, c := range d.Changed {
+		changed = append(changed, fmt.Sprintf("%s → %s", describe(c.Old), describe(c.New)))
+	}
+	section("Added", added)
+	section("Removed", removed)
+	section("Changed", changed)
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func describe(s model.ChurchService) string {
+	desc := s.Date
+	if t := deref(s.Time); t != "" {
+		desc += " " + t
+	}
+	desc += " " + s.ServiceName
+	if loc := deref(s.Location); loc != "" {
+		desc += " (" + loc + ")"
+	}
+	return desc
+}
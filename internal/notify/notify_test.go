@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func ptr(s string) *string { return &s }
+
+func svc(date, name, time string) model.ChurchService {
+	return model.ChurchService{Date: date, ServiceName: name, Time: ptr(time)}
+}
+
+func TestDiffServices(t *testing.T) {
+	tests := []struct {
+		name        string
+		old, new    []model.ChurchService
+		wantAdded   string
+		wantRemoved string
+		wantChanged string
+	}{
+		{
+			name: "unchanged",
+			old:  []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+			new:  []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+		},
+		{
+			name:      "added",
+			old:       []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+			new:       []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00"), svc("2026-03-07", "Vesper", "17:00")},
+			wantAdded: "2026-03-07 17:00 Vesper",
+		},
+		{
+			name:        "removed",
+			old:         []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00"), svc("2026-03-15", "Liturgi", "10:00")},
+			new:         []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+			wantRemoved: "2026-03-15 10:00 Liturgi",
+		},
+		{
+			name:        "time changed",
+			old:         []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+			new:         []model.ChurchService{svc("2026-03-08", "Liturgi", "09:30")},
+			wantChanged: "2026-03-08 10:00 Liturgi → 2026-03-08 09:30 Liturgi",
+		},
+		{
+			name: "location changed",
+			old:  []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+			new: []model.ChurchService{{Date: "2026-03-08", ServiceName: "Liturgi", Time: ptr("10:00"),
+				Location: ptr("Kapellet")}},
+			wantChanged: "2026-03-08 10:00 Liturgi → 2026-03-08 10:00 Liturgi (Kapellet)",
+		},
+		{
+			name: "notes are ignored",
+			old:  []model.ChurchService{{Date: "2026-03-08", ServiceName: "Liturgi", Notes: ptr("Bikt 9:30")}},
+			new:  []model.ChurchService{{Date: "2026-03-08", ServiceName: "liturgi ", Notes: ptr("Bikt 09:30")}},
+		},
+		{
+			name:        "repeated names on a day",
+			old:         []model.ChurchService{svc("2026-03-08", "Liturgi", "08:00"), svc("2026-03-08", "Liturgi", "10:00")},
+			new:         []model.ChurchService{svc("2026-03-08", "Liturgi", "08:00")},
+			wantRemoved: "2026-03-08 10:00 Liturgi",
+		},
+		{
+			name: "past services are ignored",
+			old:  []model.ChurchService{svc("2026-03-01", "Liturgi", "10:00"), svc("2026-03-08", "Liturgi", "10:00")},
+			new:  []model.ChurchService{svc("2026-03-08", "Liturgi", "10:00")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffServices(tt.old, tt.new, "2026-03-05")
+
+			var added, removed, changed []string
+			for _, s := range d.Added {
+				added = append(added, describe(s))
+			}
+			for _, s := range d.Removed {
+				removed = append(removed, describe(s))
+			}
+			for _, c := range d.Changed {
+				changed = append(changed, describe(c.Old)+" → "+describe(c.New))
+			}
+			if got := strings.Join(added, "; "); got != tt.wantAdded {
+				t.Errorf("added = %q, want %q", got, tt.wantAdded)
+			}
+			if got := strings.Join(removed, "; "); got != tt.wantRemoved {
+				t.Errorf("removed = %q, want %q", got, tt.wantRemoved)
+			}
+			if got := strings.Join(changed, "; "); got != tt.wantChanged {
+				t.Errorf("changed = %q, want %q", got, tt.wantChanged)
+			}
+			if want := tt.wantAdded == "" && tt.wantRemoved == "" && tt.wantChanged == ""; d.Empty() != want {
+				t.Errorf("Empty() = %v, want %v", d.Empty(), want)
+			}
+		})
+	}
+}
+
+func TestScheduleChangedWebhook(t *testing.T) {
+	var requests []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding webhook body: %v", err)
+		}
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	n := &Notifier{WebhookURL: srv.URL}
+	old := []model.ChurchService{svc("2999-01-01", "Liturgi", "10:00")}
+
+	if err := n.ScheduleChanged("Finska", old, old); err != nil {
+		t.Fatalf("ScheduleChanged: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("an unchanged schedule should not be posted, got %d requests", len(requests))
+	}
+
+	if err := n.ScheduleChanged("Finska", old, []model.ChurchService{svc("2999-01-01", "Liturgi", "11:00")}); err != nil {
+		t.Fatalf("ScheduleChanged: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d webhook requests, want 1", len(requests))
+	}
+	if got := string(requests[0]["source"]); got != `"Finska"` {
+		t.Errorf("source = %s, want \"Finska\"", got)
+	}
+	if !strings.Contains(string(requests[0]["changed"]), `"11:00"`) {
+		t.Errorf("changed = %s, want the new time", requests[0]["changed"])
+	}
+
+	srv.Config.Handler = http.NotFoundHandler()
+	if err := n.ScheduleChanged("Finska", old, nil); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want the webhook's 404", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package schedule parses weekly service schedules that parishes publish as
+// tables, whether the table text comes from a rendered page (chromedp) or
+// from static HTML.
+package schedule
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"ortodoxa-gudstjanster/internal/dateutil"
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+// Schedule is a weekly recurring schedule.
+type Schedule struct {
+	Services []Service `json:"services"`
+}
+
+// Service is a service held at the same time on the given days every week.
+type Service struct {
+	Name string   `json:"name"`
+	Days []string `json:"days"` // lowercase Swedish day names, e.g. "söndag"
+	Time string   `json:"time"` // HH:MM
+}
+
+// Format describes how a table lays out its entries.
+type Format struct {
+	// Layout describes an entry for error messages, e.g. "Name - days: HH:MM".
+	Layout string
+	// Entry matches one entry on a line, which may hold several. It must
+	// have the named groups name, days, hour and minute.
+	Entry *regexp.Regexp
+	// ParseDays returns the lowercase Swedish day names in the days text.
+	ParseDays func(string) []string
+	// TranslateName maps a service name to its Swedish name; nil keeps it.
+	TranslateName func(string) string
+	// NormalizeName is the key under which Dedupe compares names; nil
+	// compares them case-insensitively.
+	NormalizeName func(string) string
+}
+
+// timePattern tells lines with a time apart from headings.
+var timePattern = regexp.MustCompile(`\d{1,2}[:.]\d{2}`)
+
+// Parse reads the entries of a schedule table given as text, one row per
+// line with cells separated by whitespace. Lines without a time, such as
+// headings, and footnotes starting with "*" are skipped. Entries without any
+// recognized day are dropped, and repeated entries are removed.
+func Parse(text string, f Format) (*Schedule, error) {
+	schedule := &Schedule{Services: []Service{}}
+	lines := strings.Split(text, "\n")
+
+	// Counts for the error message when nothing parses, so a changed page
+	// layout can be told apart from an empty or failed fetch.
+	var timedLines, matchedLines int
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || !timePattern.MatchString(line) || strings.HasPrefix(line, "*") {
+			continue
+		}
+		timedLines++
+
+		line = strings.ReplaceAll(line, "\t", " ")
+		matches := f.Entry.FindAllStringSubmatch(line, -1)
+		if len(matches) > 0 {
+			matchedLines++
+		}
+		for _, m := range matches {
+			name := strings.TrimSpace(m[f.Entry.SubexpIndex("name")])
+			daysStr := strings.TrimSpace(m[f.Entry.SubexpIndex("days")])
+			hour := m[f.Entry.SubexpIndex("hour")]
+			if len(hour) == 1 {
+				hour = "0" + hour
+			}
+			timeStr := hour + ":" + m[f.Entry.SubexpIndex("minute")]
+
+			if f.TranslateName != nil {
+				name = f.TranslateName(name)
+			}
+			days := f.ParseDays(daysStr)
+			if len(days) == 0 {
+				slog.Warn("schedule: skipping service without recognized days", "name", name, "time", timeStr, "days", daysStr)
+				continue
+			}
+			if name != "" {
+				schedule.Services = append(schedule.Services, Service{Name: name, Days: days, Time: timeStr})
+			}
+		}
+	}
+
+	schedule.Services = Dedupe(schedule.Services, f.NormalizeName)
+
+	if len(schedule.Services) == 0 {
+		return nil, fmt.Errorf("could not parse any services from table text "+
+			"(%d lines, %d with a time, %d in %q form but without recognized days): %q",
+			len(lines), timedLines, matchedLines, f.Layout, text)
+	}
+	return schedule, nil
+}
+
+// Dedupe drops services that repeat an earlier one with the same normalized
+// name, time and set of days, keeping the first. A nil normalize compares
+// names case-insensitively.
+func Dedupe(services []Service, normalize func(string) string) []Service {
+	if normalize == nil {
+		normalize = strings.ToLower
+	}
+	seen := make(map[string]bool)
+	var result []Service
+	for _, svc := range services {
+		days := append([]string(nil), svc.Days...)
+		sort.Strings(days)
+		key := normalize(svc.Name) + "|" + svc.Time + "|" + strings.Join(days, ",")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, svc)
+	}
+	return result
+}
+
+// TableSeparator separates the text of consecutive tables in TableText, so
+// that a page with several tables (e.g. regular and feast services) parses as
+// one schedule.
+const TableSeparator = "\n\n"
+
+// TableText returns the rows of every HTML table matching selector as text
+// for Parse: one line per row, with cells separated by tabs and tables
+// separated by TableSeparator.
+func TableText(r io.Reader, selector string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return "", fmt.Errorf("parsing HTML: %w", err)
+	}
+	tables := doc.Find(selector)
+	if tables.Length() == 0 {
+		return "", fmt.Errorf("no table matching %q", selector)
+	}
+
+	var texts []string
+	tables.Each(func(_ int, table *goquery.Selection) {
+		var lines []string
+		table.Find("tr").Each(func(_ int, row *goquery.Selection) {
+			var cells []string
+			row.Find("th, td").Each(func(_ int, cell *goquery.Selection) {
+				cells = append(cells, strings.Join(strings.Fields(cell.Text()), " "))
+			})
+			lines = append(lines, strings.Join(cells, "\t"))
+		})
+		texts = append(texts, strings.Join(lines, "\n"))
+	})
+	return strings.Join(texts, TableSeparator), nil
+}
+
+// SwedishDays returns the lowercase Swedish names of the days named in s,
+// e.g. "Lördag och sön" gives lördag and söndag.
+func SwedishDays(s string) []string {
+	var days []string
+	for _, word := range swedishDayWord.FindAllString(s, -1) {
+		if day, ok := dateutil.ParseWeekday(word); ok {
+			days = append(days, strings.ToLower(model.SwedishWeekday(day)))
+		}
+	}
+	return days
+}
+
+var swedishDayWord = regexp.MustCompile(`\b` + dateutil.DayPattern + `\b`)
+
+// SwedishFormat is the "Day(s) HH:MM Name" layout used by Swedish-language
+// parish tables, e.g. "Söndag 10:00 Helig Liturgi" or "Lör, sön 17.00 Vesper".
+var SwedishFormat = Format{
+	Layout: "Days HH:MM Name",
+	Entry: regexp.MustCompile(`(?P<days>` + dateutil.DayPattern + `(?:\s*(?:,|och|&)\s*` + dateutil.DayPattern + `)*)\.?\s+` +
+		`(?:kl\.?\s*)?(?P<hour>\d{1,2})[:.](?P<minute>\d{2})\s+(?P<name>.+)`),
+	ParseDays: SwedishDays,
+}
@@ -0,0 +1,101 @@
+package schedule
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseSwedishFormat(t *testing.T) {
+	text := "Gudstjänster\n" +
+		"Söndag\t10:00\tHelig Liturgi\n" +
+		"Lör, sön 17.00 Vesper\n" +
+		"Onsdag och fredag kl. 8:30 Morgonbön\n" +
+		"* Ändringar kan förekomma 12:00\n" +
+		"Söndag 10:00 Helig liturgi\n"
+
+	got, err := Parse(text, SwedishFormat)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Service{
+		{Name: "Helig Liturgi", Days: []string{"söndag"}, Time: "10:00"},
+		{Name: "Vesper", Days: []string{"lördag", "söndag"}, Time: "17:00"},
+		{Name: "Morgonbön", Days: []string{"onsdag", "fredag"}, Time: "08:30"},
+	}
+	if !reflect.DeepEqual(got.Services, want) {
+		t.Errorf("services = %+v, want %+v", got.Services, want)
+	}
+}
+
+// dashFormat is the "Name - days: HH:MM" layout, with Latin-script days.
+var dashFormat = Format{
+	Layout: "Name - days: HH:MM",
+	Entry:  regexp.MustCompile(`(?P<name>\S.*?)\s*[-–]\s*(?P<days>.+?):\s*(?P<hour>\d{1,2}):(?P<minute>\d{2})`),
+	ParseDays: func(s string) []string {
+		if strings.Contains(strings.ToLower(s), "nedelja") {
+			return []string{"söndag"}
+		}
+		return nil
+	},
+	TranslateName: func(s string) string { return strings.ToUpper(s) },
+}
+
+func TestParseDashFormat(t *testing.T) {
+	got, err := Parse("Jutrenje - nedelja:\t8:00 Liturgija - nedelja: 9:30", dashFormat)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Service{
+		{Name: "JUTRENJE", Days: []string{"söndag"}, Time: "08:00"},
+		{Name: "LITURGIJA", Days: []string{"söndag"}, Time: "09:30"},
+	}
+	if !reflect.DeepEqual(got.Services, want) {
+		t.Errorf("services = %+v, want %+v", got.Services, want)
+	}
+
+	_, err = Parse("Header\nVecernje - subota: 17:00", dashFormat)
+	if err == nil || !strings.Contains(err.Error(), `2 lines, 1 with a time, 1 in "Name - days: HH:MM" form`) {
+		t.Errorf("err = %v, want the line counts and layout", err)
+	}
+}
+
+func TestTableText(t *testing.T) {
+	html := `<html><body>
+<table class="other"><tr><td>Ignored</td></tr></table>
+<table class="schedule">
+  <tr><th>Dag</th><th>Tid</th><th>Gudstjänst</th></tr>
+  <tr><td>Söndag</td><td>10:00</td><td>Helig
+      Liturgi</td></tr>
+  <tr><td>Lördag</td><td>17:00</td><td>Vesper</td></tr>
+</table></body></html>`
+
+	text, err := TableText(strings.NewReader(html), "table.schedule")
+	if err != nil {
+		t.Fatalf("TableText: %v", err)
+	}
+	if want := "Dag\tTid\tGudstjänst\nSöndag\t10:00\tHelig Liturgi\nLördag\t17:00\tVesper"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+
+	got, err := Parse(text, SwedishFormat)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got.Services) != 2 || got.Services[1].Name != "Vesper" {
+		t.Errorf("services = %+v", got.Services)
+	}
+
+	all, err := TableText(strings.NewReader(html), "table")
+	if err != nil {
+		t.Fatalf("TableText: %v", err)
+	}
+	if want := "Ignored" + TableSeparator + text; all != want {
+		t.Errorf("all tables = %q, want %q", all, want)
+	}
+
+	if _, err := TableText(strings.NewReader(html), "table.missing"); err == nil {
+		t.Error("want an error for a missing table")
+	}
+}
@@ -0,0 +1,59 @@
+package scraper
+
+import (
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+// Dependencies are what the built-in scrapers fetch with. The web server,
+// which only describes the scrapers at /sources, leaves Store, Vision and
+// UploadReader nil.
+type Dependencies struct {
+	Store        store.Store
+	Vision       *vision.Client
+	UploadReader *store.BucketReader // also gives Gomos its uploaded images
+	UploadBucket string              // registers the uploads scraper if set
+	Definitions  []ParishDefinition
+}
+
+// uploadParishes describes the parishes with folders in the upload bucket,
+// keyed by folder name.
+var uploadParishes = map[string]UploadParishInfo{
+	"helige-giorgis": {
+		Name:       "Helige Giorgis",
+		Location:   "Helige Giorgis, Kyrkvägen 27, 182 74 Stocksund",
+		SourceURL:  "https://www.facebook.com/share/17oMW5H9UN/?mibextid=wwXIfr",
+		SourceName: "Facebook",
+	},
+}
+
+// NewDefaultRegistry returns a registry of every built-in scraper, the
+// uploads scraper if deps.UploadBucket is set, and a scraper per parish
+// definition. The ingest job and the web server both use it, so the sources
+// listed at /sources are the ones ingested. A clock set on the registry also
+// reaches deps.Vision.
+func NewDefaultRegistry(deps Dependencies) *Registry {
+	r := NewRegistry()
+	if deps.Vision != nil {
+		r.shareClock(deps.Vision)
+	}
+	r.Register(NewFinskaScraper(""))
+	gomos := NewGomosScraper(deps.Store, deps.Vision)
+	if deps.UploadReader != nil {
+		gomos.SetUploadSource(deps.UploadReader, "st-georgios/")
+	}
+	r.Register(gomos)
+	r.Register(NewHeligaAnnaScraper())
+	r.Register(NewRyskaScraper(deps.Store, deps.Vision))
+	r.Register(NewHeligeSergijScraper(deps.Store, deps.Vision))
+	r.Register(NewGCalendarScraper())
+	r.Register(NewGCalendarManualScraper())
+	r.Register(NewUppstandelseScraper())
+	r.Register(NewRomanianScraper())
+	r.Register(NewSommarlagerScraper(deps.Store, deps.Vision))
+	if deps.UploadBucket != "" {
+		r.Register(NewUploadsScraper(deps.Store, deps.Vision, deps.UploadReader, deps.UploadBucket, uploadParishes))
+	}
+	r.RegisterDefinitions(deps.Definitions, deps.Store, deps.Vision)
+	return r
+}
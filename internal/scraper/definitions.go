@@ -0,0 +1,329 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+// Definition types supported by LoadDefinitions.
+const (
+	DefinitionTable  = "table"  // HTML table, one service per row
+	DefinitionICS    = "ics"    // ICS feed
+	DefinitionJSONLD = "jsonld" // schema.org Event objects in <script type="application/ld+json">
+	DefinitionPDF    = "pdf"    // PDF with a text layer, read by PDFScheduleScraper
+)
+
+// ParishDefinition describes a simple parish source that can be scraped
+// without writing Go: an HTML table, an ICS feed, JSON-LD events, or a PDF.
+type ParishDefinition struct {
+	Name     string `json:"name"`   // scraper name, also used as Source
+	Parish   string `json:"parish"` // defaults to Name
+	Type     string `json:"type"`   // table, ics, jsonld or pdf
+	URL      string `json:"url"`
+	Location string `json:"location,omitempty"`
+	Language string `json:"language,omitempty"`
+
+	// Table-only settings
+	RowSelector string       `json:"row_selector,omitempty"` // CSS selector for service rows, e.g. "table.schedule tr"
+	Columns     TableColumns `json:"columns,omitempty"`
+	DateFormat  string       `json:"date_format,omitempty"` // Go time layout, defaults to 2006-01-02
+}
+
+// TableColumns maps service fields to zero-based <td> indexes within a row.
+type TableColumns struct {
+	Date    int  `json:"date"`
+	Time    *int `json:"time,omitempty"`
+	Service int  `json:"service"`
+}
+
+// LoadDefinitions reads and validates parish definitions from a JSON file
+// containing an array of ParishDefinition.
+func LoadDefinitions(path string) ([]ParishDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading parish definitions: %w", err)
+	}
+
+	var defs []ParishDefinition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("parsing parish definitions: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i, def := range defs {
+		if err := def.Validate(); err != nil {
+			return nil, fmt.Errorf("parish definition %d: %w", i, err)
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("parish definition %d: duplicate name %q", i, def.Name)
+		}
+		names[def.Name] = true
+	}
+
+	return defs, nil
+}
+
+// Validate checks that a definition has the fields its type requires.
+func (d ParishDefinition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if d.URL == "" {
+		return fmt.Errorf("%s: missing url", d.Name)
+	}
+	switch d.Type {
+	case DefinitionTable:
+		if d.RowSelector == "" {
+			return fmt.Errorf("%s: table definition needs row_selector", d.Name)
+		}
+		if d.Columns.Date < 0 || d.Columns.Service < 0 || (d.Columns.Time != nil && *d.Columns.Time < 0) {
+			return fmt.Errorf("%s: column indexes must not be negative", d.Name)
+		}
+	case DefinitionICS, DefinitionJSONLD, DefinitionPDF:
+	default:
+		return fmt.Errorf("%s: unknown type %q (want table, ics, jsonld or pdf)", d.Name, d.Type)
+	}
+	return nil
+}
+
+// RegisterDefinitions registers a scraper for each parish definition. PDF
+// definitions extract their text with v, caching in s.
+func (r *Registry) RegisterDefinitions(defs []ParishDefinition, s store.Store, v *vision.Client) {
+	for _, def := range defs {
+		if def.Type == DefinitionPDF {
+			d := NewDefinitionScraper(def)
+			pdf := NewPDFScheduleScraper(d.Metadata(), def.URL, s, v)
+			pdf.parish = d.def.Parish
+			r.Register(pdf)
+			continue
+		}
+		r.Register(NewDefinitionScraper(def))
+	}
+}
+
+// DefinitionScraper scrapes a parish described by a ParishDefinition.
+type DefinitionScraper struct {
+	NoteCollector
+	def ParishDefinition
+}
+
+// NewDefinitionScraper creates a scraper for a validated definition.
+func NewDefinitionScraper(def ParishDefinition) *DefinitionScraper {
+	if def.Parish == "" {
+		def.Parish = def.Name
+	}
+	if def.DateFormat == "" {
+		def.DateFormat = "2006-01-02"
+	}
+	return &DefinitionScraper{def: def}
+}
+
+func (s *DefinitionScraper) Name() string {
+	return s.def.Name
+}
+
+func (s *DefinitionScraper) Metadata() model.SourceMetadata {
+	var languages []string
+	for _, lang := range strings.Split(s.def.Language, ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+	return model.SourceMetadata{
+		Name:      s.def.Name,
+		Location:  s.def.Location,
+		Languages: languages,
+		SourceURL: s.def.URL,
+	}
+}
+
+func (s *DefinitionScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
+	s.resetNotes()
+	switch s.def.Type {
+	case DefinitionTable:
+		return s.fetchTable(ctx)
+	case DefinitionICS:
+		return s.fetchICS(ctx)
+	case DefinitionJSONLD:
+		return s.fetchJSONLD(ctx)
+	default:
+		return nil, fmt.Errorf("unknown definition type %q", s.def.Type)
+	}
+}
+
+func (s *DefinitionScraper) fetchTable(ctx context.Context) ([]model.ChurchService, error) {
+	doc, err := fetchDocument(ctx, s.def.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := s.def.Columns
+	var services []model.ChurchService
+	skipped := 0
+	doc.Find(s.def.RowSelector).Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		cell := func(i int) string {
+			return strings.TrimSpace(cells.Eq(i).Text())
+		}
+
+		date, err := time.ParseInLocation(s.def.DateFormat, cell(cols.Date), stockholm)
+		name := cell(cols.Service)
+		if err != nil || name == "" {
+			skipped++
+			return
+		}
+
+		svc := s.newService(date, name)
+		if cols.Time != nil {
+			svc.Time = strPtr(cell(*cols.Time))
+		}
+		services = append(services, svc)
+	})
+
+	s.note("parsed %d rows, %d skipped", len(services), skipped)
+	return services, nil
+}
+
+func (s *DefinitionScraper) fetchICS(ctx context.Context) ([]model.ChurchService, error) {
+	data, err := fetchURL(ctx, s.def.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching ICS feed: %w", err)
+	}
+
+	events, err := ParseAndExpandICS(string(data), stockholm)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ICS feed: %w", err)
+	}
+
+	var services []model.ChurchService
+	for _, ev := range events {
+		if ev.Cancelled {
+			continue
+		}
+		svc := s.newService(ev.Start, ev.Summary)
+		svc.Time = formatTimeRange(ev)
+		svc.Notes = strPtr(ev.Description)
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+// jsonLDEvent is the subset of a schema.org Event used by jsonld definitions.
+type jsonLDEvent struct {
+	Type      any             `json:"@type"`
+	Name      string          `json:"name"`
+	StartDate string          `json:"startDate"`
+	Graph     json.RawMessage `json:"@graph"`
+}
+
+func (s *DefinitionScraper) fetchJSONLD(ctx context.Context) ([]model.ChurchService, error) {
+	doc, err := fetchDocument(ctx, s.def.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []jsonLDEvent
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, script *goquery.Selection) {
+		found, err := parseJSONLDEvents([]byte(script.Text()))
+		if err != nil {
+			s.note("skipping invalid JSON-LD block: %v", err)
+			return
+		}
+		events = append(events, found...)
+	})
+
+	var services []model.ChurchService
+	for _, ev := range events {
+		start, dateOnly, err := parseJSONLDDate(ev.StartDate)
+		if err != nil || ev.Name == "" {
+			continue
+		}
+		svc := s.newService(start, ev.Name)
+		if !dateOnly {
+			svc.Time = strPtr(start.Format("15:04"))
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+// parseJSONLDEvents returns the Event objects in a JSON-LD block, which may
+// be a single object, an array, or an object with an @graph array.
+func parseJSONLDEvents(data []byte) ([]jsonLDEvent, error) {
+	var objects []jsonLDEvent
+	if err := json.Unmarshal(data, &objects); err != nil {
+		var single jsonLDEvent
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, err
+		}
+		objects = []jsonLDEvent{single}
+	}
+
+	var events []jsonLDEvent
+	for _, obj := range objects {
+		if len(obj.Graph) > 0 {
+			nested, err := parseJSONLDEvents(obj.Graph)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, nested...)
+		}
+		if isJSONLDEventType(obj.Type) {
+			events = append(events, obj)
+		}
+	}
+	return events, nil
+}
+
+// isJSONLDEventType reports whether @type (a string or array of strings)
+// names Event or one of its subtypes.
+func isJSONLDEventType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return strings.HasSuffix(v, "Event")
+	case []any:
+		for _, item := range v {
+			if isJSONLDEventType(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseJSONLDDate parses an ISO 8601 date or date-time, reporting whether
+// it was a date only.
+func parseJSONLDDate(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, stockholm); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, stockholm); err == nil {
+			return t.In(stockholm), false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("unrecognized date %q", value)
+}
+
+func (s *DefinitionScraper) newService(start time.Time, name string) model.ChurchService {
+	return model.ChurchService{
+		Parish:         s.def.Parish,
+		Source:         s.def.Name,
+		SourceURL:      s.def.URL,
+		Date:           start.Format("2006-01-02"),
+		DayOfWeek:      model.SwedishWeekday(start.Weekday()),
+		ServiceName:    name,
+		Location:       strPtr(s.def.Location),
+		ParishLanguage: strPtr(s.def.Language),
+	}
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDefinitions(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "parishes.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefinitionsTableScraper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><table class="schedule">
+<tr><th>Datum</th><th>Tid</th><th>Gudstjänst</th></tr>
+<tr><td>2026-03-08</td><td>10:00</td><td>Helig Liturgi</td></tr>
+<tr><td>2026-03-14</td><td>17:00</td><td>Vesper</td></tr>
+</table></body></html>`))
+	}))
+	defer srv.Close()
+
+	path := writeDefinitions(t, fmt.Sprintf(`[{
+		"name": "Test Parish",
+		"type": "table",
+		"url": %q,
+		"location": "Kyrkogatan 1, Stockholm",
+		"language": "Svenska",
+		"row_selector": "table.schedule tr",
+		"columns": {"date": 0, "time": 1, "service": 2}
+	}]`, srv.URL))
+
+	defs, err := LoadDefinitions(path)
+	if err != nil {
+		t.Fatalf("LoadDefinitions: %v", err)
+	}
+
+	registry := NewRegistry()
+	registry.RegisterDefinitions(defs, nil, nil)
+	if len(registry.Scrapers()) != 1 {
+		t.Fatalf("got %d scrapers, want 1", len(registry.Scrapers()))
+	}
+	s := registry.Scrapers()[0]
+	if s.Name() != "Test Parish" {
+		t.Errorf("Name() = %q, want %q", s.Name(), "Test Parish")
+	}
+
+	services, err := s.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2: %v", len(services), services)
+	}
+	svc := services[0]
+	if svc.Parish != "Test Parish" || svc.Date != "2026-03-08" || svc.DayOfWeek != "Söndag" || svc.ServiceName != "Helig Liturgi" {
+		t.Errorf("services[0] = %s %s %s %s", svc.Parish, svc.Date, svc.DayOfWeek, svc.ServiceName)
+	}
+	if svc.Time == nil || *svc.Time != "10:00" {
+		t.Errorf("services[0].Time = %v, want 10:00", svc.Time)
+	}
+	if svc.Location == nil || *svc.Location != "Kyrkogatan 1, Stockholm" {
+		t.Errorf("services[0].Location = %v", svc.Location)
+	}
+}
+
+func TestDefinitionScraperJSONLD(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head>
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+  {"@type": "Organization", "name": "Församlingen"},
+  {"@type": "Event", "name": "Helig Liturgi", "startDate": "2026-03-08T10:00:00+01:00"}
+]}</script>
+<script type="application/ld+json">[{"@type": ["Event"], "name": "Fest", "startDate": "2026-03-25"}]</script>
+</head></html>`))
+	}))
+	defer srv.Close()
+
+	s := NewDefinitionScraper(ParishDefinition{Name: "JSON-LD Parish", Type: DefinitionJSONLD, URL: srv.URL})
+	services, err := s.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2: %v", len(services), services)
+	}
+	if services[0].Time == nil || *services[0].Time != "10:00" {
+		t.Errorf("services[0].Time = %v, want 10:00", services[0].Time)
+	}
+	if services[1].Date != "2026-03-25" || services[1].Time != nil {
+		t.Errorf("services[1] = %s %v, want all-day 2026-03-25", services[1].Date, services[1].Time)
+	}
+}
+
+func TestLoadDefinitionsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing name", `[{"type": "ics", "url": "https://example.com"}]`, "missing name"},
+		{"missing url", `[{"name": "A", "type": "ics"}]`, "missing url"},
+		{"unknown type", `[{"name": "A", "type": "rss", "url": "https://example.com"}]`, "unknown type"},
+		{"table without selector", `[{"name": "A", "type": "table", "url": "https://example.com"}]`, "row_selector"},
+		{"duplicate name", `[{"name": "A", "type": "ics", "url": "https://a"}, {"name": "A", "type": "ics", "url": "https://b"}]`, "duplicate"},
+		{"invalid JSON", `[{`, "parsing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadDefinitions(writeDefinitions(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadDefinitions error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
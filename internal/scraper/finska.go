@@ -1,7 +1,6 @@
 package scraper
 
 import (
-	"context"
 	"regexp"
 	"strings"
 
@@ -14,120 +13,81 @@ const (
 	finskaSourceName = "Helige Nikolai ortodoxa kyrka"
 	finskaParishSlug = "helige-nikolai"
 	finskaDefaultURL = "https://www.ortodox-finsk.se/kalender/"
+	finskaLocation   = "Bellmansgatan 13, 118 47 Stockholm"
 )
 
-// FinskaScraper scrapes the Finnish Orthodox Congregation calendar.
-type FinskaScraper struct {
-	NoteCollector
-	url string
-}
+var (
+	finskaDateRegex     = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})\s*\|`)
+	finskaLocationRegex = regexp.MustCompile(`<strong>\s*Plats:\s*</strong>\s*([^<]+)`)
+	finskaTimeRegex     = regexp.MustCompile(`<strong>\s*Tid:\s*</strong>\s*([^<]+)`)
+)
 
 // NewFinskaScraper creates a new scraper for the Finnish Orthodox Congregation.
-func NewFinskaScraper(url string) *FinskaScraper {
+func NewFinskaScraper(url string) *HTMLScraper {
 	if url == "" {
 		url = finskaDefaultURL
 	}
-	return &FinskaScraper{url: url}
+	return NewHTMLScraper(HTMLScraperConfig{
+		Metadata: model.SourceMetadata{
+			Name:      finskaSourceName,
+			Location:  finskaLocation,
+			Languages: []string{"Finska", "Svenska"},
+			SourceURL: url,
+			Lat:       59.3193,
+			Lng:       18.0660,
+		},
+		ParishSlug:   finskaParishSlug,
+		URL:          url,
+		ItemSelector: "section.calendar div.calendar-item",
+		DateSelector: "div.meta",
+		DatePattern:  finskaDateRegex,
+		NameSelector: "div.calendar-item-content h3",
+		DefaultName:  "Unknown",
+		Customize:    finskaDetails,
+	})
 }
 
-func (s *FinskaScraper) Name() string {
-	return finskaSourceName
-}
+// finskaDetails fills in the location, time, occasion and notes from the
+// details block of a calendar item.
+func finskaDetails(item *goquery.Selection, svc *model.ChurchService) {
+	detailsDiv := item.Find("div.calendar-item-content div").First()
+	detailsHTML, _ := detailsDiv.Html()
 
-func (s *FinskaScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
-	s.resetNotes()
-	doc, err := fetchDocument(ctx, s.url)
-	if err != nil {
-		return nil, err
+	if locMatch := finskaLocationRegex.FindStringSubmatch(detailsHTML); len(locMatch) > 1 {
+		loc := normalizeFinskaLocation(strings.TrimSpace(locMatch[1]))
+		svc.Location = &loc
+	}
+	if timeMatch := finskaTimeRegex.FindStringSubmatch(detailsHTML); len(timeMatch) > 1 {
+		t := strings.TrimSpace(timeMatch[1])
+		svc.Time = &t
 	}
 
-	var services []model.ChurchService
-	dateRegex := regexp.MustCompile(`(\d{4}-\d{2}-\d{2})\s*\|\s*(\S+)`)
-
-	doc.Find("section.calendar div.calendar-item").Each(func(i int, item *goquery.Selection) {
-		meta := item.Find("div.meta").Text()
-		matches := dateRegex.FindStringSubmatch(meta)
-		if len(matches) < 3 {
-			return
-		}
-
-		date := matches[1]
-		dayOfWeek := matches[2]
-
-		contentDiv := item.Find("div.calendar-item-content")
-		serviceName := strings.TrimSpace(contentDiv.Find("h3").Text())
-		if serviceName == "" {
-			serviceName = "Unknown"
-		}
-
-		var location, time, occasion *string
-		var notes []string
-
-		detailsDiv := contentDiv.Find("div").First()
-		detailsHTML, _ := detailsDiv.Html()
-
-		// Extract location
-		locRegex := regexp.MustCompile(`<strong>\s*Plats:\s*</strong>\s*([^<]+)`)
-		if locMatch := locRegex.FindStringSubmatch(detailsHTML); len(locMatch) > 1 {
-			loc := normalizeFinskaLocation(strings.TrimSpace(locMatch[1]))
-			location = &loc
-		}
-
-		// Extract time
-		timeRegex := regexp.MustCompile(`<strong>\s*Tid:\s*</strong>\s*([^<]+)`)
-		if timeMatch := timeRegex.FindStringSubmatch(detailsHTML); len(timeMatch) > 1 {
-			t := strings.TrimSpace(timeMatch[1])
-			time = &t
+	// The occasion is the first <strong> that is not a Plats/Tid label
+	detailsDiv.Find("strong").EachWithBreak(func(_ int, strong *goquery.Selection) bool {
+		text := strings.TrimSpace(strong.Text())
+		if text != "" && text != "Plats:" && text != "Tid:" {
+			svc.Occasion = &text
+			return false
 		}
+		return true
+	})
 
-		// Extract occasion (first strong tag that's not Plats/Tid)
-		detailsDiv.Find("strong").Each(func(j int, strong *goquery.Selection) {
-			if occasion != nil {
-				return
-			}
-			text := strings.TrimSpace(strong.Text())
-			if text != "" && text != "Plats:" && text != "Tid:" {
-				occasion = &text
-			}
-		})
-
-		// Extract notes from <p> tags
-		detailsDiv.Find("p").Each(func(j int, p *goquery.Selection) {
-			text := strings.TrimSpace(p.Text())
-			if text != "" {
-				notes = append(notes, text)
-			}
-		})
-
-		var notesPtr *string
-		if len(notes) > 0 {
-			joined := strings.Join(notes, "\n")
-			notesPtr = &joined
+	var notes []string
+	detailsDiv.Find("p").Each(func(_ int, p *goquery.Selection) {
+		if text := strings.TrimSpace(p.Text()); text != "" {
+			notes = append(notes, text)
 		}
-
-		services = append(services, model.ChurchService{
-			Parish:      "",
-			ParishSlug:  finskaParishSlug,
-			Source:      finskaSourceName,
-			SourceURL:   s.url,
-			Date:        date,
-			DayOfWeek:   dayOfWeek,
-			ServiceName: serviceName,
-			Location:    location,
-			Time:        time,
-			Occasion:    occasion,
-			Notes:       notesPtr,
-		})
 	})
-
-	s.note("found %d services on calendar page", len(services))
-	return services, nil
+	if len(notes) > 0 {
+		joined := strings.Join(notes, "\n")
+		svc.Notes = &joined
+	}
 }
 
 // normalizeFinskaLocation maps known location variants to a canonical address format.
 func normalizeFinskaLocation(loc string) string {
 	if strings.Contains(loc, "Nikolai") || strings.Contains(loc, "Bellmansgatan") {
-		return "Bellmansgatan 13, 118 47 Stockholm"
+		return finskaLocation
 	}
 	return loc
 }
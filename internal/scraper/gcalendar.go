@@ -45,6 +45,15 @@ func (s *GCalendarScraper) Name() string {
 	return gcalendarSourceName
 }
 
+func (s *GCalendarScraper) Metadata() model.SourceMetadata {
+	// The calendar covers several parishes, so locations come per event
+	return model.SourceMetadata{
+		Name:      gcalendarSourceName,
+		Languages: []string{"Svenska", "Grekiska", "Serbiska"},
+		SourceURL: gcalendarSourcePage,
+	}
+}
+
 func (s *GCalendarScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	data, err := fetchURL(ctx, gcalendarURL)
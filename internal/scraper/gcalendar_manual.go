@@ -46,6 +46,14 @@ func (s *GCalendarManualScraper) Name() string {
 	return gcalendarManualSourceName
 }
 
+func (s *GCalendarManualScraper) Metadata() model.SourceMetadata {
+	// Parish, location and language are given per event
+	return model.SourceMetadata{
+		Name:      gcalendarManualSourceName,
+		SourceURL: gcalendarManualSourcePage,
+	}
+}
+
 func (s *GCalendarManualScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	data, err := fetchURL(ctx, gcalendarManualURL)
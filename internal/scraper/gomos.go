@@ -6,12 +6,15 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 
+	"ortodoxa-gudstjanster/internal/dateutil"
 	"ortodoxa-gudstjanster/internal/model"
 	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/vision"
@@ -24,11 +27,19 @@ const (
 	gomosLocation    = "Birger Jarlsgatan 92, 114 20 Stockholm"
 )
 
+// OCR reads raw schedule entries from a schedule image. Besides the structured
+// result it returns the backend's raw response, which is kept for diagnostics.
+// *vision.Client implements it.
+type OCR interface {
+	ExtractScheduleRaw(ctx context.Context, imageData []byte) (*vision.RawScheduleResult, string, error)
+}
+
 // GomosScraper scrapes the St. Georgios Cathedral schedule using OpenAI Vision API.
 type GomosScraper struct {
 	NoteCollector
 	store        store.Store
 	vision       *vision.Client
+	ocr          OCR
 	uploadReader *store.BucketReader
 	uploadPrefix string
 }
@@ -38,9 +49,15 @@ func NewGomosScraper(s store.Store, v *vision.Client) *GomosScraper {
 	return &GomosScraper{
 		store:  s,
 		vision: v,
+		ocr:    v,
 	}
 }
 
+// SetOCR replaces the image OCR backend, which defaults to the vision client.
+func (s *GomosScraper) SetOCR(ocr OCR) {
+	s.ocr = ocr
+}
+
 // SetUploadSource configures a GCS bucket as a fallback image source.
 func (s *GomosScraper) SetUploadSource(reader *store.BucketReader, prefix string) {
 	s.uploadReader = reader
@@ -51,6 +68,17 @@ func (s *GomosScraper) Name() string {
 	return gomosSourceName
 }
 
+func (s *GomosScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      gomosSourceName,
+		Location:  gomosLocation,
+		Languages: []string{"Grekiska", "Svenska"},
+		SourceURL: gomosScheduleURL,
+		Lat:       59.3473,
+		Lng:       18.0588,
+	}
+}
+
 func (s *GomosScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 
@@ -59,7 +87,7 @@ func (s *GomosScraper) Fetch(ctx context.Context) ([]model.ChurchService, error)
 
 	websiteImages, websiteErr := s.fetchWebsiteImages(ctx)
 	if websiteErr != nil {
-		log.Printf("Gomos: website failed: %v", websiteErr)
+		s.log().Warn("website fetch failed", "err", websiteErr)
 		s.note("website fetch failed: %v", websiteErr)
 	} else {
 		s.note("website: fetched %d image(s)", len(websiteImages))
@@ -69,7 +97,7 @@ func (s *GomosScraper) Fetch(ctx context.Context) ([]model.ChurchService, error)
 	if s.uploadReader != nil {
 		bucketImages, bucketErr := s.fetchBucketImages(ctx)
 		if bucketErr != nil {
-			log.Printf("Gomos: bucket failed: %v", bucketErr)
+			s.log().Warn("upload bucket fetch failed", "err", bucketErr)
 			s.note("GCS upload bucket fetch failed: %v", bucketErr)
 		} else {
 			s.note("GCS upload bucket: fetched %d image(s)", len(bucketImages))
@@ -95,7 +123,7 @@ func (s *GomosScraper) Fetch(ctx context.Context) ([]model.ChurchService, error)
 	// If the website failed and all resulting events are past-dated, the backup
 	// data is stale. Return an error so the existing scraper-failure alert fires.
 	if websiteErr != nil && len(deduped) > 0 {
-		today := time.Now().Format("2006-01-02")
+		today := s.clock().Format("2006-01-02")
 		futureCount := 0
 		for _, svc := range deduped {
 			if svc.Date >= today {
@@ -142,7 +170,7 @@ func (s *GomosScraper) processImages(ctx context.Context, images []imageWithData
 	for _, img := range images {
 		res, err := s.ocrImage(ctx, img.data, img.sourceRef)
 		if err != nil {
-			log.Printf("Gomos: OCR failed for %s: %v", img.sourceRef, err)
+			s.log().Warn("OCR failed", "image", img.sourceRef, "err", err)
 			s.note("OCR failed for %s: %v", img.sourceRef, err)
 			continue
 		}
@@ -182,8 +210,13 @@ func (s *GomosScraper) processImages(ctx context.Context, images []imageWithData
 			}
 		}
 
-		log.Printf("Gomos: using %s source for %s (%d entries)", chosen.language, month, len(chosen.entries))
-		allServices = append(allServices, s.convertToServices(chosen.entries, chosen.sourceURL)...)
+		s.log().Info("chose schedule source", "language", chosen.language, "month", month, "entries", len(chosen.entries))
+		services := s.convertToServices(chosen.entries, chosen.sourceURL)
+		if confidence := s.ocrConfidence(chosen.entries); confidence < gomosMinConfidence {
+			s.log().Warn("low OCR confidence, needs manual review", "confidence", confidence, "month", month, "url", chosen.sourceURL)
+			s.note("low OCR confidence %.2f for %s (%s): review manually", confidence, month, chosen.sourceURL)
+		}
+		allServices = append(allServices, services...)
 	}
 
 	return allServices, nil
@@ -201,34 +234,36 @@ func (s *GomosScraper) ocrImage(ctx context.Context, imageData []byte, sourceRef
 	// Check OCR cache for raw (untranslated) result.
 	var raw vision.RawScheduleResult
 	if s.store.GetJSON(cacheKey, &raw) {
-		log.Printf("Gomos: OCR cache hit for %s (checksum %s)", sourceRef, checksum[:12])
+		s.log().Debug("OCR cache hit", "image", sourceRef, "checksum", checksum[:12])
 	} else {
-		log.Printf("Gomos: OCR cache miss for %s (checksum %s), calling API", sourceRef, checksum[:12])
+		s.log().Info("OCR cache miss, calling API", "image", sourceRef, "checksum", checksum[:12])
 
 		var rawResponse string
 		var err error
-		rawPtr, resp, err := s.vision.ExtractScheduleRaw(ctx, imageData)
+		rawPtr, resp, err := s.ocr.ExtractScheduleRaw(ctx, imageData)
 		if err != nil {
+			vision.RecordExtract(gomosSourceName, 0, err)
 			return nil, fmt.Errorf("OCR for %s: %w", sourceRef, err)
 		}
 		raw = *rawPtr
 		rawResponse = resp
+		vision.RecordExtract(gomosSourceName, len(raw.Entries), nil)
 
-		// Persist raw API response for diagnostics
-		if werr := s.store.SetRaw(cacheKey+".response.txt", []byte(rawResponse)); werr != nil {
-			log.Printf("Gomos: failed to persist OCR response: %v", werr)
+		// Persist raw OCR text next to the source image for diagnostics
+		if werr := s.store.SetWithExtension(cacheKey, ".response.txt", []byte(rawResponse)); werr != nil {
+			s.log().Warn("persisting OCR response", "err", werr)
 		}
 
 		// Persist source image
-		imageExt := s.imageExtension(sourceRef)
-		if werr := s.store.SetRaw(cacheKey+imageExt, imageData); werr != nil {
-			log.Printf("Gomos: failed to persist source image: %v", werr)
+		imageExt := s.sniffImageExtension(imageData, sourceRef)
+		if werr := s.store.SetWithExtension(cacheKey, imageExt, imageData); werr != nil {
+			s.log().Warn("persisting source image", "err", werr)
 		}
 
 		// Cache raw result so future runs skip the expensive OCR API call.
 		if data, merr := json.Marshal(raw); merr == nil {
 			if werr := s.store.SetRaw(cacheKey+".json", data); werr != nil {
-				log.Printf("Gomos: failed to cache OCR result: %v", werr)
+				s.log().Warn("caching OCR result", "err", werr)
 			}
 		}
 	}
@@ -247,7 +282,7 @@ func (s *GomosScraper) ocrImage(ctx context.Context, imageData []byte, sourceRef
 		}
 	}
 
-	log.Printf("Gomos: OCR extracted %d entries (%s) for %s", len(entries), raw.Language, sourceRef)
+	s.log().Info("OCR extracted entries", "entries", len(entries), "language", raw.Language, "image", sourceRef)
 	return &ocrCacheEntry{
 		Language: raw.Language,
 		Entries:  entries,
@@ -285,7 +320,7 @@ func (s *GomosScraper) translateEntries(ctx context.Context, entries []vision.Ra
 
 	var cached []vision.ScheduleEntry
 	if s.store.GetJSON(cacheKey, &cached) {
-		log.Printf("Gomos: translate cache hit")
+		s.log().Debug("translate cache hit")
 		return cached, nil
 	}
 
@@ -293,17 +328,23 @@ func (s *GomosScraper) translateEntries(ctx context.Context, entries []vision.Ra
 	if err != nil {
 		return nil, fmt.Errorf("translating entries: %w", err)
 	}
+	// The translation keeps the entry order, but the model may drop flags
+	if len(translated) == len(entries) {
+		for i := range translated {
+			translated[i].Uncertain = translated[i].Uncertain || entries[i].Uncertain
+		}
+	}
 
 	// Persist structured result
 	if data, merr := json.Marshal(translated); merr == nil {
 		if werr := s.store.SetRaw(cacheKey+".json", data); werr != nil {
-			log.Printf("Gomos: failed to cache translated entries: %v", werr)
+			s.log().Warn("caching translated entries", "err", werr)
 		}
 	}
 
 	// Persist raw API response
 	if werr := s.store.SetRaw(cacheKey+".response.txt", []byte(rawResponse)); werr != nil {
-		log.Printf("Gomos: failed to persist translate response: %v", werr)
+		s.log().Warn("persisting translate response", "err", werr)
 	}
 
 	return translated, nil
@@ -334,6 +375,7 @@ func rawEntriesToSwedish(entries []vision.RawScheduleEntry) []vision.ScheduleEnt
 			Occasion:    e.Occasion,
 			Location:    e.Location,
 			Abroad:      e.Abroad,
+			Uncertain:   e.Uncertain,
 		}
 	}
 	return result
@@ -355,7 +397,7 @@ func (s *GomosScraper) fetchWebsiteImages(ctx context.Context) ([]imageWithData,
 	for _, url := range imageURLs {
 		data, err := s.downloadImage(ctx, url)
 		if err != nil {
-			log.Printf("Gomos: failed to download %s: %v", url, err)
+			s.log().Warn("downloading image", "url", url, "err", err)
 			continue
 		}
 		images = append(images, imageWithData{
@@ -384,7 +426,7 @@ func (s *GomosScraper) fetchBucketImages(ctx context.Context) ([]imageWithData,
 
 		imageData, err := s.uploadReader.ReadObject(ctx, name)
 		if err != nil {
-			log.Printf("Gomos: failed to read upload %s: %v", name, err)
+			s.log().Warn("reading upload", "object", name, "err", err)
 			continue
 		}
 
@@ -464,6 +506,98 @@ func (s *GomosScraper) imageExtension(url string) string {
 	return ".jpg"
 }
 
+// sniffImageExtension picks the file extension for a downloaded image from
+// its content, falling back to the URL when the content type is unknown.
+// Uploaded images often have no extension, and the website sometimes serves
+// PNGs under .jpg URLs.
+func (s *GomosScraper) sniffImageExtension(data []byte, url string) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		if ext := s.imageExtension(url); ext == ".jpeg" {
+			return ext
+		}
+		return ".jpg"
+	}
+	return s.imageExtension(url)
+}
+
+// gomosMinConfidence is the OCR confidence below which a schedule is flagged
+// for manual review.
+const gomosMinConfidence = 0.7
+
+// ocrConfidence estimates OCR quality as the fraction of entries that look
+// well-formed: a valid date, an HH:MM time (after normalizeOCRTime), a service
+// name, and a day name that agrees with the date when one is given. Dates are
+// scored as entryDate corrects them, so that an unpadded date or a January
+// schedule read in December is not held against the image.
+func (s *GomosScraper) ocrConfidence(entries []vision.ScheduleEntry) float64 {
+	if len(entries) == 0 {
+		return 0
+	}
+	good := 0
+	for _, e := range entries {
+		date, err := time.Parse("2006-01-02", s.entryDate(e.Date))
+		if err != nil || strings.TrimSpace(e.ServiceName) == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", normalizeOCRTime(e.Time)); err != nil {
+			continue
+		}
+		if weekday, ok := dateutil.ParseWeekday(e.DayOfWeek); ok && weekday != date.Weekday() {
+			continue
+		}
+		good++
+	}
+	return float64(good) / float64(len(entries))
+}
+
+// gomosPastMonths is how far back a schedule date may lie before it is
+// assumed to belong to next year. It is wider than HeligaAnna's window so
+// that older uploaded schedules still read as past and trip the stale check.
+const gomosPastMonths = 6
+
+// entryDate normalizes an OCR'd date to zero-padded YYYY-MM-DD and corrects
+// its year. The OCR prompt fills in the current year when the schedule doesn't
+// state one, which is wrong for e.g. a January schedule read in December.
+// Dates with another year keep it; unparseable dates are returned unchanged.
+func (s *GomosScraper) entryDate(date string) string {
+	now := s.clock()
+	// "2006-1-2" accepts both padded and unpadded month and day
+	t, err := time.Parse("2006-1-2", strings.TrimSpace(date))
+	if err != nil {
+		return date
+	}
+	year := t.Year()
+	if year == now.Year() {
+		year = inferYear(now, t.Month(), t.Day(), gomosPastMonths)
+	}
+	return fmt.Sprintf("%d-%02d-%02d", year, t.Month(), t.Day())
+}
+
+// ocrTimePattern matches an hour and minutes separated by a colon, dot or
+// space, after OCR letter confusions have been corrected.
+var ocrTimePattern = regexp.MustCompile(`^(\d{1,2})\s*[.: ]\s*(\d{2})$`)
+
+// ocrDigitFixes corrects letters that OCR commonly reads in place of digits.
+var ocrDigitFixes = strings.NewReplacer("O", "0", "o", "0", "I", "1", "l", "1", "|", "1")
+
+// normalizeOCRTime turns OCR-garbled times such as "9 00", "9.OO" or "I8:00"
+// into HH:MM. Values that don't look like a single time are returned unchanged.
+func normalizeOCRTime(t string) string {
+	m := ocrTimePattern.FindStringSubmatch(ocrDigitFixes.Replace(strings.TrimSpace(t)))
+	if m == nil {
+		return t
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	if hour > 23 || minute > 59 {
+		return t
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
 func (s *GomosScraper) convertToServices(entries []vision.ScheduleEntry, sourceURL string) []model.ChurchService {
 	var services []model.ChurchService
 
@@ -479,7 +613,19 @@ func (s *GomosScraper) convertToServices(entries []vision.ScheduleEntry, sourceU
 			}
 			location = entry.Location
 		}
-		time := entry.Time
+
+		// The weekday follows the final date, whose year entryDate may have
+		// changed. For unparseable dates, keep the OCR'd day name, which
+		// sometimes lacks diacritics ("Sondag") or is abbreviated.
+		date := s.entryDate(entry.Date)
+		dayOfWeek := entry.DayOfWeek
+		if d, err := time.Parse("2006-01-02", date); err == nil {
+			dayOfWeek = model.SwedishWeekday(d.Weekday())
+		} else if weekday, ok := dateutil.ParseWeekday(dayOfWeek); ok {
+			dayOfWeek = model.SwedishWeekday(weekday)
+		}
+
+		time := normalizeOCRTime(entry.Time)
 
 		var occasion *string
 		if entry.Occasion != "" {
@@ -491,12 +637,13 @@ func (s *GomosScraper) convertToServices(entries []vision.ScheduleEntry, sourceU
 			ParishSlug:  gomosParishSlug,
 			Source:      gomosSourceName,
 			SourceURL:   sourceURL,
-			Date:        entry.Date,
-			DayOfWeek:   entry.DayOfWeek,
+			Date:        date,
+			DayOfWeek:   dayOfWeek,
 			ServiceName: entry.ServiceName,
 			Location:  &location,
 			Time:      &time,
 			Occasion:  occasion,
+			Uncertain: entry.Uncertain,
 		})
 	}
 
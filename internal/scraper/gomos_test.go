@@ -1,7 +1,12 @@
 package scraper
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
 )
 
 func TestImageExtension(t *testing.T) {
@@ -31,3 +36,280 @@ func TestImageExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeOCRTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"09:00", "09:00"},
+		{"9:00", "09:00"},
+		{"9 00", "09:00"},
+		{"9.00", "09:00"},
+		{"9.OO", "09:00"},
+		{"I8:00", "18:00"},
+		{"l7.3O", "17:30"},
+		{" 10 : 30 ", "10:30"},
+		{"18:00 - 19:30", "18:00 - 19:30"},
+		{"", ""},
+		{"Ol:75", "Ol:75"},
+		{"24:00", "24:00"},
+		{"23:59", "23:59"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := normalizeOCRTime(tt.input); got != tt.want {
+				t.Errorf("normalizeOCRTime(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGomosEntryDateAdvancesYear(t *testing.T) {
+	s := &GomosScraper{}
+	s.SetClock(func() time.Time { return time.Date(2026, time.December, 10, 12, 0, 0, 0, time.UTC) })
+
+	entries := []vision.ScheduleEntry{
+		{Date: "2026-01-04", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Θεία Λειτουργία"},
+		{Date: "2026-12-20", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Θεία Λειτουργία"},
+		{Date: "2025-11-30", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Θεία Λειτουργία"},
+	}
+	services := s.convertToServices(entries, "https://gomos.se")
+
+	want := []struct{ date, day string }{
+		{"2027-01-04", "Måndag"}, // the weekday follows the corrected year
+		{"2026-12-20", "Söndag"},
+		{"2025-11-30", "Söndag"},
+	}
+	if len(services) != len(want) {
+		t.Fatalf("got %d services, want %d", len(services), len(want))
+	}
+	for i, svc := range services {
+		if svc.Date != want[i].date || svc.DayOfWeek != want[i].day {
+			t.Errorf("services[%d] = %s %s, want %s %s", i, svc.Date, svc.DayOfWeek, want[i].date, want[i].day)
+		}
+	}
+}
+
+func TestGomosEntryDateKeepsRecentPast(t *testing.T) {
+	// A schedule from four months ago stays in the past so stale data is detected
+	s := &GomosScraper{}
+	s.SetClock(func() time.Time { return time.Date(2026, time.July, 15, 12, 0, 0, 0, time.UTC) })
+	if got := s.entryDate("2026-03-08"); got != "2026-03-08" {
+		t.Errorf("entryDate(2026-03-08) = %s, want 2026-03-08", got)
+	}
+}
+
+func TestOCRConfidence(t *testing.T) {
+	good := vision.ScheduleEntry{Date: "2026-03-08", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Helig Liturgi"}
+
+	tests := []struct {
+		name    string
+		entries []vision.ScheduleEntry
+		want    float64
+	}{
+		{"no entries", nil, 0},
+		{"all well-formed", []vision.ScheduleEntry{good, good}, 1},
+		{"garbled time still parses", []vision.ScheduleEntry{{Date: "2026-03-08", Time: "9.OO", ServiceName: "Liturgi"}}, 1},
+		{"mixed", []vision.ScheduleEntry{
+			good,
+			{Date: "2026-03-08", DayOfWeek: "Söndag", Time: "??", ServiceName: "Liturgi"},    // unreadable time
+			{Date: "2026-03-08", DayOfWeek: "Måndag", Time: "09:00", ServiceName: "Liturgi"}, // weekday disagrees
+			{Date: "2026-13-40", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Liturgi"}, // invalid date
+		}, 0.25},
+		// Read in December 2025, the OCR'd 2025 is corrected to January 2026,
+		// when the 4th is a Sunday
+		{"corrected year", []vision.ScheduleEntry{{Date: "2025-01-04", DayOfWeek: "Söndag", Time: "10:00", ServiceName: "Liturgi"}}, 1},
+		{"unpadded date", []vision.ScheduleEntry{{Date: "2026-1-4", DayOfWeek: "Söndag", Time: "10:00", ServiceName: "Liturgi"}}, 1},
+	}
+
+	s := &GomosScraper{}
+	s.SetClock(func() time.Time { return time.Date(2025, time.December, 20, 12, 0, 0, 0, time.UTC) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.ocrConfidence(tt.entries); got != tt.want {
+				t.Errorf("ocrConfidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGomosEntryDateZeroPads(t *testing.T) {
+	s := &GomosScraper{}
+	s.SetClock(func() time.Time { return time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC) })
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2026-02-05", "2026-02-05"},
+		{"2026-02-5", "2026-02-05"},
+		{"2026-2-5", "2026-02-05"},
+		{"2026-02-15", "2026-02-15"},
+		{"2026-2-15", "2026-02-15"},
+		{"2026-3-9", "2026-03-09"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := s.entryDate(tt.input)
+			if got != tt.want {
+				t.Errorf("entryDate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if !dateRegex.MatchString(got) {
+				t.Errorf("entryDate(%q) = %q, does not match %s", tt.input, got, dateRegex)
+			}
+		})
+	}
+}
+
+type fakeOCR struct {
+	result vision.RawScheduleResult
+	calls  int
+}
+
+func (f *fakeOCR) ExtractScheduleRaw(ctx context.Context, imageData []byte) (*vision.RawScheduleResult, string, error) {
+	f.calls++
+	result := f.result
+	return &result, `{"canned": true}`, nil
+}
+
+func TestGomosOCRBackend(t *testing.T) {
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	ocr := &fakeOCR{result: vision.RawScheduleResult{
+		Language: "Swedish",
+		Entries: []vision.RawScheduleEntry{
+			{Date: "2026-03-08", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Helig Liturgi"},
+		},
+	}}
+	s := NewGomosScraper(st, nil)
+	s.SetOCR(ocr)
+
+	res, err := s.ocrImage(context.Background(), []byte("fake image"), "https://gomos.se/schedule.jpg")
+	if err != nil {
+		t.Fatalf("ocrImage: %v", err)
+	}
+	if len(res.Entries) != 1 || res.Entries[0].ServiceName != "Helig Liturgi" {
+		t.Errorf("entries = %+v, want the canned entry", res.Entries)
+	}
+
+	// The result is cached by image checksum, so the backend isn't called again
+	if _, err := s.ocrImage(context.Background(), []byte("fake image"), "https://gomos.se/schedule.jpg"); err != nil {
+		t.Fatalf("ocrImage (cached): %v", err)
+	}
+	if ocr.calls != 1 {
+		t.Errorf("OCR backend called %d times, want 1", ocr.calls)
+	}
+}
+
+func TestGomosPersistsOCRTextNextToImage(t *testing.T) {
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	s := NewGomosScraper(st, nil)
+	s.SetOCR(&fakeOCR{result: vision.RawScheduleResult{Language: "Swedish"}})
+
+	image := []byte("fake image")
+	if _, err := s.ocrImage(context.Background(), image, "https://gomos.se/schedule.png"); err != nil {
+		t.Fatalf("ocrImage: %v", err)
+	}
+
+	prefix := "gomos-ocr/v3/" + s.computeChecksum(image)
+	keys, err := st.List(prefix)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := map[string]bool{prefix: true, prefix + ".png": true, prefix + ".response.txt": true}
+	for _, key := range keys {
+		delete(want, key)
+	}
+	if len(want) > 0 {
+		t.Errorf("missing stored blobs %v, got %v", want, keys)
+	}
+}
+
+func TestGomosSavesSourceImageWithSniffedExtension(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     []byte
+		sourceRef string
+		wantExt   string
+	}{
+		{"png without extension", []byte("\x89PNG\r\n\x1a\n rest of png"), "uploads/schedule", ".png"},
+		{"png served as jpg", []byte("\x89PNG\r\n\x1a\n another png"), "https://gomos.se/schedule.jpg", ".png"},
+		{"jpeg", []byte("\xff\xd8\xff\xe0 rest of jpeg"), "https://gomos.se/schedule.jpeg", ".jpeg"},
+		{"jpeg without extension", []byte("\xff\xd8\xff\xe0 other jpeg"), "uploads/scan", ".jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := store.NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocal: %v", err)
+			}
+			s := NewGomosScraper(st, nil)
+			s.SetOCR(&fakeOCR{result: vision.RawScheduleResult{Language: "Swedish"}})
+
+			if _, err := s.ocrImage(context.Background(), tt.image, tt.sourceRef); err != nil {
+				t.Fatalf("ocrImage: %v", err)
+			}
+
+			key := "gomos-ocr/v3/" + s.computeChecksum(tt.image) + tt.wantExt
+			keys, err := st.List(key)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(keys) != 1 || keys[0] != key {
+				t.Errorf("stored blobs = %v, want %s", keys, key)
+			}
+		})
+	}
+}
+
+func TestGomosVisionEntriesMapToServices(t *testing.T) {
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	s := NewGomosScraper(st, nil)
+	s.SetClock(func() time.Time { return time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC) })
+	s.SetOCR(&fakeOCR{result: vision.RawScheduleResult{
+		Language: "Swedish",
+		Entries: []vision.RawScheduleEntry{
+			{Date: "2026-03-08", DayOfWeek: "Söndag", Time: "09:00", ServiceName: "Helig Liturgi"},
+			{Date: "2026-03-14", DayOfWeek: "Lördag", Time: "18:00", ServiceName: "Vesper", Occasion: "Korsets söndag", Uncertain: true},
+		},
+	}})
+
+	services, err := s.processImages(context.Background(), []imageWithData{
+		{data: []byte("march schedule"), sourceRef: "https://gomos.se/march.jpg", sourceURL: "https://gomos.se/schedule/march"},
+	})
+	if err != nil {
+		t.Fatalf("processImages: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2", len(services))
+	}
+	for _, svc := range services {
+		if svc.Source != gomosSourceName || svc.ParishSlug != gomosParishSlug {
+			t.Errorf("source = %q/%q, want %q/%q", svc.Source, svc.ParishSlug, gomosSourceName, gomosParishSlug)
+		}
+		if svc.Location == nil || *svc.Location != gomosLocation {
+			t.Errorf("location = %v, want %q", svc.Location, gomosLocation)
+		}
+		if svc.SourceURL != "https://gomos.se/schedule/march" {
+			t.Errorf("source URL = %q", svc.SourceURL)
+		}
+	}
+	if services[1].Occasion == nil || *services[1].Occasion != "Korsets söndag" {
+		t.Errorf("occasion = %v, want Korsets söndag", services[1].Occasion)
+	}
+	if services[0].Uncertain || !services[1].Uncertain {
+		t.Errorf("uncertain = %v, %v; want only the entry the model flagged", services[0].Uncertain, services[1].Uncertain)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 
+	"ortodoxa-gudstjanster/internal/dateutil"
 	"ortodoxa-gudstjanster/internal/model"
 )
 
@@ -32,6 +33,17 @@ func (s *HeligaAnnaScraper) Name() string {
 	return heligaAnnaSourceName
 }
 
+func (s *HeligaAnnaScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      heligaAnnaSourceName,
+		Location:  heligaAnnaLocation,
+		Languages: []string{"Svenska"},
+		SourceURL: heligaAnnaURL,
+		Lat:       59.3858,
+		Lng:       18.0437,
+	}
+}
+
 func (s *HeligaAnnaScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	doc, err := fetchDocument(ctx, heligaAnnaURL)
@@ -39,18 +51,29 @@ func (s *HeligaAnnaScraper) Fetch(ctx context.Context) ([]model.ChurchService, e
 		return nil, err
 	}
 
+	services, stockholmFound := s.parse(doc.Selection)
+	if !stockholmFound {
+		s.note("Stockholm section not found on page — 0 services parsed")
+	} else {
+		s.note("found %d services", len(services))
+	}
+	return services, nil
+}
+
+// parse returns the services in the Stockholm section of the page, and
+// whether that section was found. Years are inferred from the scraper's clock.
+func (s *HeligaAnnaScraper) parse(root *goquery.Selection) ([]model.ChurchService, bool) {
 	var services []model.ChurchService
-	now := time.Now()
-	currentYear := now.Year()
+	now := s.clock()
 
 	// Pattern: <strong>Söndag 8/2</strong> kl. 09:00. Liturgi. Optional occasion
 	// The text after the service name (after the dot) might be an occasion
-	serviceRegex := regexp.MustCompile(`(?i)(måndag|tisdag|onsdag|torsdag|fredag|lördag|söndag)\s+(\d{1,2})/(\d{1,2})`)
+	serviceRegex := regexp.MustCompile(`(` + dateutil.DayPattern + `)\s+(\d{1,2})/(\d{1,2})`)
 	timeRegex := regexp.MustCompile(`kl\.?\s*(\d{1,2})[.:](\d{2})`)
 
 	// Find the Stockholm section - look for h3 with "Stockholm" and get its container
 	stockholmFound := false
-	doc.Find(".elementor-widget-text-editor").Each(func(i int, container *goquery.Selection) {
+	root.Find(".elementor-widget-text-editor").Each(func(i int, container *goquery.Selection) {
 		html, _ := container.Html()
 		if !strings.Contains(html, "<h3>Stockholm</h3>") {
 			return
@@ -67,7 +90,11 @@ func (s *HeligaAnnaScraper) Fetch(ctx context.Context) ([]model.ChurchService, e
 				return
 			}
 
-			dayOfWeek := capitalize(dateMatch[1])
+			weekday, ok := dateutil.ParseWeekday(dateMatch[1])
+			if !ok {
+				return
+			}
+			dayOfWeek := model.SwedishWeekday(weekday)
 			day, err := strconv.Atoi(dateMatch[2])
 			if err != nil || day < 1 || day > 31 {
 				return
@@ -81,13 +108,7 @@ func (s *HeligaAnnaScraper) Fetch(ctx context.Context) ([]model.ChurchService, e
 			// more than 3 months in the past, assume next year. If more
 			// than 9 months in the future, assume previous year. This
 			// places events in a [-3, +9] month window around today.
-			year := currentYear
-			candidate := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
-			if candidate.Before(now.AddDate(0, -3, 0)) {
-				year++
-			} else if candidate.After(now.AddDate(0, 9, 0)) {
-				year--
-			}
+			year := inferYear(now, time.Month(month), day, 3)
 
 			date := fmt.Sprintf("%d-%02d-%02d", year, month, day)
 
@@ -137,17 +158,5 @@ func (s *HeligaAnnaScraper) Fetch(ctx context.Context) ([]model.ChurchService, e
 		})
 	})
 
-	if !stockholmFound {
-		s.note("Stockholm section not found on page — 0 services parsed")
-	} else {
-		s.note("found %d services", len(services))
-	}
-	return services, nil
-}
-
-func capitalize(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+	return services, stockholmFound
 }
@@ -5,19 +5,36 @@ import (
 	"time"
 )
 
+func TestHeligaAnnaParseRollsOverYear(t *testing.T) {
+	page := `<div class="elementor-widget-text-editor"><h3>Stockholm</h3><ul>
+<li><strong>Söndag 20/12</strong> kl. 10:00. Liturgi</li>
+<li><strong>Tisdag 6/1</strong> kl. 09:30. Liturgi. Theofania</li>
+</ul></div>`
+
+	s := NewHeligaAnnaScraper()
+	s.SetClock(func() time.Time { return time.Date(2026, time.December, 10, 12, 0, 0, 0, stockholm) })
+	services, found := s.parse(parseHTML(t, page))
+	if !found {
+		t.Fatal("Stockholm section not found")
+	}
+
+	want := []string{"2026-12-20", "2027-01-06"}
+	if len(services) != len(want) {
+		t.Fatalf("got %d services, want %d", len(services), len(want))
+	}
+	for i, svc := range services {
+		if svc.Date != want[i] {
+			t.Errorf("services[%d].Date = %s, want %s", i, svc.Date, want[i])
+		}
+	}
+	if occ := services[1].Occasion; occ == nil || *occ != "Theofania" {
+		t.Errorf("services[1].Occasion = %v, want Theofania", occ)
+	}
+}
+
 func TestHeligaAnnaYearAssignment(t *testing.T) {
-	// The year assignment logic from Fetch, extracted for testing:
-	// If the candidate date is more than 3 months in the past, use next year.
-	// Otherwise use current year.
 	assignYear := func(now time.Time, month, day int) int {
-		year := now.Year()
-		candidate := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
-		if candidate.Before(now.AddDate(0, -3, 0)) {
-			year++
-		} else if candidate.After(now.AddDate(0, 9, 0)) {
-			year--
-		}
-		return year
+		return inferYear(now, time.Month(month), day, 3)
 	}
 
 	// Simulate: today is March 7, 2026
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"html"
 	"io"
-	"log"
 	"net/http"
 	"regexp"
 	"strings"
@@ -44,6 +43,15 @@ func (s *HeligeSergijScraper) Name() string {
 	return heligeSergijSourceName
 }
 
+func (s *HeligeSergijScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      heligeSergijSourceName,
+		Location:  heligeSergijDefaultLocation,
+		Languages: []string{"Kyrkoslaviska", "Svenska"},
+		SourceURL: heligeSergijURL,
+	}
+}
+
 func (s *HeligeSergijScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	var text string
@@ -57,11 +65,12 @@ func (s *HeligeSergijScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 			return nil, err
 		}
 		s.note("attempt %d/3: %d message elements, %d schedule posts matched", attempt, elemCount, postCount)
+		s.log().Info("fetched Telegram page", "attempt", attempt, "elements", elemCount, "schedule_posts", postCount)
 		if text != "" {
 			break
 		}
 		if attempt < 3 {
-			log.Printf("Helige Sergij: no schedule posts found on attempt %d/3, retrying in 10s", attempt)
+			s.log().Warn("no schedule posts found, retrying in 10s", "attempt", attempt)
 			time.Sleep(10 * time.Second)
 		}
 	}
@@ -70,14 +79,14 @@ func (s *HeligeSergijScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 		if len(rawHTML) > 0 {
 			diagKey := "helige-sergij/debug/" + time.Now().UTC().Format("20060102-150405") + ".html"
 			if werr := s.store.SetRaw(diagKey, rawHTML); werr != nil {
-				log.Printf("Helige Sergij: failed to save diagnostic HTML: %v", werr)
+				s.log().Warn("saving diagnostic HTML", "err", werr)
 			} else {
-				log.Printf("Helige Sergij: saved diagnostic HTML to %s (%d bytes)", diagKey, len(rawHTML))
+				s.log().Info("saved diagnostic HTML", "key", diagKey, "bytes", len(rawHTML))
 				s.note("saved diagnostic HTML to GCS: %s", diagKey)
 			}
 		}
 		if cached, ok := s.store.Get(heligeSergijTextCacheKey); ok && len(cached) > 0 {
-			log.Printf("Helige Sergij: Telegram unavailable, using cached schedule text")
+			s.log().Warn("Telegram unavailable, using cached schedule text")
 			s.note("Telegram page empty on all attempts — using cached schedule text")
 			text = string(cached)
 		} else {
@@ -86,7 +95,7 @@ func (s *HeligeSergijScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 		}
 	} else {
 		if err := s.store.Set(heligeSergijTextCacheKey, []byte(text)); err != nil {
-			log.Printf("Helige Sergij: failed to cache schedule text: %v", err)
+			s.log().Warn("caching schedule text", "err", err)
 		}
 	}
 
@@ -105,7 +114,7 @@ func (s *HeligeSergijScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 	}
 
 	if err := s.store.SetJSON(cacheKey, entries); err != nil {
-		log.Printf("warning: failed to cache helige-sergij schedule: %v", err)
+		s.log().Warn("caching schedule", "err", err)
 	}
 
 	return s.entriesToServices(entries), nil
@@ -158,11 +167,17 @@ func fetchTelegramScheduleText(ctx context.Context) (text string, rawHTML []byte
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", nil, 0, 0, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, heligeSergijURL)
+	if err := checkStatus(resp, heligeSergijURL); err != nil {
+		return "", nil, 0, 0, err
+	}
+
+	body, err := responseBody(resp)
+	if err != nil {
+		return "", nil, 0, 0, err
 	}
+	defer body.Close()
 
-	rawHTML, err = io.ReadAll(resp.Body)
+	rawHTML, err = io.ReadAll(body)
 	if err != nil {
 		return "", nil, 0, 0, fmt.Errorf("reading body: %w", err)
 	}
@@ -194,7 +209,6 @@ func fetchTelegramScheduleText(ctx context.Context) (text string, rawHTML []byte
 
 	elementCount = allElements.Length()
 	postCount = len(schedulePosts)
-	log.Printf("Helige Sergij: page has %d message elements, %d matching schedule posts", elementCount, postCount)
 
 	// Use only the most recent 2 schedule posts to avoid sending stale data to OpenAI.
 	// Posts on the Telegram page are in chronological order so the last items are newest.
@@ -1,10 +1,20 @@
 package scraper
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestFetchURLNon200(t *testing.T) {
@@ -35,6 +45,19 @@ func TestFetchURL200(t *testing.T) {
 	}
 }
 
+func TestFetchURLErrorIncludesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<h1>Database   error</h1>\n"))
+	}))
+	defer srv.Close()
+
+	_, err := fetchURL(context.Background(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "<h1>Database error</h1>") {
+		t.Errorf("fetchURL error = %v, want the status and body snippet", err)
+	}
+}
+
 func TestFetchDocumentNon200(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -62,3 +85,216 @@ func TestFetchDocument200(t *testing.T) {
 		t.Errorf("body text = %q, want %q", doc.Find("body").Text(), "hello")
 	}
 }
+
+func TestFetchURLGzip(t *testing.T) {
+	page := bytes.Repeat([]byte("<p>Lördag 10:00 Vesper</p>\n"), 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip, deflate" {
+			w.Write(page)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write(page)
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	data, err := fetchURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchURL: %v", err)
+	}
+	if !bytes.Equal(data, page) {
+		t.Errorf("fetchURL returned %d bytes, want the %d-byte decompressed page", len(data), len(page))
+	}
+
+	doc, err := fetchDocument(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchDocument: %v", err)
+	}
+	if got := doc.Find("p").First().Text(); got != "Lördag 10:00 Vesper" {
+		t.Errorf("first paragraph = %q, want %q", got, "Lördag 10:00 Vesper")
+	}
+}
+
+func TestFetchURLDeflate(t *testing.T) {
+	page := bytes.Repeat([]byte("<p>Söndag 10:00 Liturgi</p>\n"), 100)
+	tests := []struct {
+		name     string
+		compress func(io.Writer) io.WriteCloser
+	}{
+		{"zlib", func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }},
+		{"raw", func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "deflate")
+				zw := tt.compress(w)
+				zw.Write(page)
+				zw.Close()
+			}))
+			defer srv.Close()
+
+			data, err := fetchURL(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatalf("fetchURL: %v", err)
+			}
+			if !bytes.Equal(data, page) {
+				t.Errorf("fetchURL returned %d bytes, want the %d-byte decompressed page", len(data), len(page))
+			}
+		})
+	}
+}
+
+func TestFetchURLIgnoredAcceptEncoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	}))
+	defer srv.Close()
+
+	data, err := fetchURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchURL: %v", err)
+	}
+	if string(data) != "plain" {
+		t.Errorf("fetchURL = %q, want %q", data, "plain")
+	}
+}
+
+func TestFetchDocumentCharset(t *testing.T) {
+	latin1, err := os.ReadFile("testdata/latin1.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		page        []byte
+	}{
+		{"meta tag", "text/html", latin1},
+		{"header", "text/html; charset=windows-1252", []byte("<p class=\"service\">L\xf6rdag 17:00 Vesper</p>")},
+		{"utf-8", "text/html; charset=utf-8", []byte(`<p class="service">Lördag 17:00 Vesper</p>`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write(tt.page)
+			}))
+			defer srv.Close()
+
+			doc, err := fetchDocument(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatalf("fetchDocument: %v", err)
+			}
+			got := doc.Find("p.service").Text()
+			if got != "Lördag 17:00 Vesper" || !utf8.ValidString(got) {
+				t.Errorf("service = %q, want %q", got, "Lördag 17:00 Vesper")
+			}
+		})
+	}
+}
+
+func TestFetchURLUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	if _, err := fetchURL(context.Background(), srv.URL); err != nil {
+		t.Fatalf("fetchURL: %v", err)
+	}
+	if got != browserUserAgent {
+		t.Errorf("User-Agent = %q, want the browser default", got)
+	}
+
+	t.Setenv("SCRAPER_USER_AGENT", "OrtodoxaGudstjanster/1.0 (+https://ortodoxagudstjanster.se)")
+	if _, err := fetchDocument(context.Background(), srv.URL); err != nil {
+		t.Fatalf("fetchDocument: %v", err)
+	}
+	if got != "OrtodoxaGudstjanster/1.0 (+https://ortodoxagudstjanster.se)" {
+		t.Errorf("User-Agent = %q, want SCRAPER_USER_AGENT", got)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	tests := []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", defaultFetchTimeout},
+		{"45s", 45 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"soon", defaultFetchTimeout},
+		{"-5s", defaultFetchTimeout},
+	}
+	for _, tt := range tests {
+		t.Setenv("SCRAPER_TIMEOUT", tt.env)
+		if got := fetchTimeout(); got != tt.want {
+			t.Errorf("SCRAPER_TIMEOUT=%q: fetchTimeout() = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestFetchResponseTooLarge(t *testing.T) {
+	t.Setenv("SCRAPER_MAX_RESPONSE_BYTES", "1024")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := bytes.Repeat([]byte("<p>x</p>"), 64)
+		for i := 0; i < 16; i++ {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := fetchURL(context.Background(), srv.URL); !errors.Is(err, errResponseTooLarge) {
+		t.Errorf("fetchURL error = %v, want errResponseTooLarge", err)
+	}
+	if _, err := fetchDocument(context.Background(), srv.URL); !errors.Is(err, errResponseTooLarge) {
+		t.Errorf("fetchDocument error = %v, want errResponseTooLarge", err)
+	}
+	if _, err := fetchSrpskaScheduleHTTP(context.Background(), srv.URL); !errors.Is(err, errResponseTooLarge) {
+		t.Errorf("fetchSrpskaScheduleHTTP error = %v, want errResponseTooLarge", err)
+	}
+}
+
+func TestFetchResponseAtLimit(t *testing.T) {
+	t.Setenv("SCRAPER_MAX_RESPONSE_BYTES", "1024")
+	page := bytes.Repeat([]byte("x"), 1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	}))
+	defer srv.Close()
+
+	data, err := fetchURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchURL: %v", err)
+	}
+	if len(data) != len(page) {
+		t.Errorf("fetchURL returned %d bytes, want %d", len(data), len(page))
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int64
+	}{
+		{"", defaultMaxResponseBytes},
+		{"1048576", 1 << 20},
+		{"10MB", defaultMaxResponseBytes},
+		{"0", defaultMaxResponseBytes},
+	}
+	for _, tt := range tests {
+		t.Setenv("SCRAPER_MAX_RESPONSE_BYTES", tt.env)
+		if got := maxResponseBytes(); got != tt.want {
+			t.Errorf("SCRAPER_MAX_RESPONSE_BYTES=%q: maxResponseBytes() = %d, want %d", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+// HTMLScraperConfig describes a parish page that lists one service per HTML
+// element, located with CSS selectors.
+type HTMLScraperConfig struct {
+	// Metadata describes the source. Its Name is the scraper name and the
+	// services' source.
+	Metadata   model.SourceMetadata
+	Parish     string
+	ParishSlug string
+	URL        string
+
+	ItemSelector string // one match per service, e.g. "div.calendar-item"
+
+	// Selectors below are relative to the item; an empty selector means the
+	// item itself. Items without a parseable date are skipped, as are items
+	// without a name unless DefaultName is set.
+	DateSelector string
+	DatePattern  *regexp.Regexp // if set, its first group is the date text
+	DateFormat   string         // Go time layout of the date text, defaults to 2006-01-02
+	TimeSelector string         // optional; no time is set if empty
+	NameSelector string
+	DefaultName  string // service name of items whose name is empty
+
+	// Customize, if set, fills in page-specific fields (location, occasion,
+	// notes, ...) of each service from its item.
+	Customize func(item *goquery.Selection, svc *model.ChurchService)
+}
+
+// HTMLScraper scrapes a page described by an HTMLScraperConfig.
+type HTMLScraper struct {
+	NoteCollector
+	cfg HTMLScraperConfig
+}
+
+// NewHTMLScraper creates a scraper for cfg.
+func NewHTMLScraper(cfg HTMLScraperConfig) *HTMLScraper {
+	if cfg.DateFormat == "" {
+		cfg.DateFormat = "2006-01-02"
+	}
+	return &HTMLScraper{cfg: cfg}
+}
+
+func (s *HTMLScraper) Name() string {
+	return s.cfg.Metadata.Name
+}
+
+func (s *HTMLScraper) Metadata() model.SourceMetadata {
+	return s.cfg.Metadata
+}
+
+func (s *HTMLScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
+	s.resetNotes()
+	doc, err := fetchDocument(ctx, s.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	services, skipped := s.parse(doc.Selection)
+	s.note("found %d services on page, %d items skipped", len(services), skipped)
+	return services, nil
+}
+
+// parse returns the services listed in root and the number of items skipped.
+func (s *HTMLScraper) parse(root *goquery.Selection) ([]model.ChurchService, int) {
+	var services []model.ChurchService
+	skipped := 0
+	root.Find(s.cfg.ItemSelector).Each(func(_ int, item *goquery.Selection) {
+		dateText := selectText(item, s.cfg.DateSelector)
+		if s.cfg.DatePattern != nil {
+			m := s.cfg.DatePattern.FindStringSubmatch(dateText)
+			if len(m) < 2 {
+				skipped++
+				return
+			}
+			dateText = m[1]
+		}
+		date, err := time.ParseInLocation(s.cfg.DateFormat, dateText, stockholm)
+		name := selectText(item, s.cfg.NameSelector)
+		if name == "" {
+			name = s.cfg.DefaultName
+		}
+		if err != nil || name == "" {
+			skipped++
+			return
+		}
+
+		svc := model.ChurchService{
+			Parish:      s.cfg.Parish,
+			ParishSlug:  s.cfg.ParishSlug,
+			Source:      s.cfg.Metadata.Name,
+			SourceURL:   s.cfg.URL,
+			Date:        date.Format("2006-01-02"),
+			DayOfWeek:   model.SwedishWeekday(date.Weekday()),
+			ServiceName: name,
+		}
+		if s.cfg.TimeSelector != "" {
+			svc.Time = strPtr(selectText(item, s.cfg.TimeSelector))
+		}
+		if s.cfg.Customize != nil {
+			s.cfg.Customize(item, &svc)
+		}
+		services = append(services, svc)
+	})
+	return services, skipped
+}
+
+// selectText returns the trimmed text of the first match of selector within
+// item, or of item itself if selector is empty.
+func selectText(item *goquery.Selection, selector string) string {
+	if selector == "" {
+		return strings.TrimSpace(item.Text())
+	}
+	return strings.TrimSpace(item.Find(selector).First().Text())
+}
@@ -0,0 +1,167 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+func parseHTML(t *testing.T, page string) *goquery.Selection {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("parsing HTML: %v", err)
+	}
+	return doc.Selection
+}
+
+func TestHTMLScraperFinska(t *testing.T) {
+	page := `<section class="calendar">
+<div class="calendar-item">
+  <div class="meta">2026-03-01 | Söndag</div>
+  <div class="calendar-item-content">
+    <h3>Liturgi</h3>
+    <div><strong>Ortodoxins söndag</strong><br>
+      <strong>Plats:</strong> Helige Nikolai kyrka<br>
+      <strong>Tid:</strong> 10:00 - ca 12:00
+      <p>Kyrkkaffe efteråt</p></div>
+  </div>
+</div>
+<div class="calendar-item">
+  <div class="meta">2026-03-07 | Lördag</div>
+  <div class="calendar-item-content"><h3>Vesper</h3><div><strong>Tid:</strong> 17:00</div></div>
+</div>
+<div class="calendar-item"><div class="meta">Inställt</div></div>
+<div class="calendar-item">
+  <div class="meta">2026-03-08 | Söndag</div>
+  <div class="calendar-item-content"><h3></h3><div><strong>Tid:</strong> 10:00</div></div>
+</div>
+</section>`
+
+	services, skipped := NewFinskaScraper("").parse(parseHTML(t, page))
+	if len(services) != 3 || skipped != 1 {
+		t.Fatalf("got %d services, %d skipped; want 3, 1", len(services), skipped)
+	}
+
+	first := services[0]
+	if first.Date != "2026-03-01" || first.DayOfWeek != "Söndag" || first.ServiceName != "Liturgi" || first.Source != finskaSourceName {
+		t.Errorf("first service = %+v", first)
+	}
+	for name, got := range map[string]*string{"location": first.Location, "time": first.Time, "occasion": first.Occasion, "notes": first.Notes} {
+		if got == nil {
+			t.Errorf("%s not set", name)
+		}
+	}
+	if first.Location != nil && *first.Location != finskaLocation {
+		t.Errorf("location = %q, want the normalized address", *first.Location)
+	}
+	if first.Time != nil && *first.Time != "10:00 - ca 12:00" {
+		t.Errorf("time = %q", *first.Time)
+	}
+	if first.Occasion != nil && *first.Occasion != "Ortodoxins söndag" {
+		t.Errorf("occasion = %q", *first.Occasion)
+	}
+
+	second := services[1]
+	if second.Time == nil || *second.Time != "17:00" || second.Occasion != nil || second.Location != nil {
+		t.Errorf("second service = %+v", second)
+	}
+
+	// An item with an empty heading is kept under a placeholder name
+	if third := services[2]; third.Date != "2026-03-08" || third.ServiceName != "Unknown" {
+		t.Errorf("third service = %+v, want ServiceName Unknown", third)
+	}
+}
+
+func TestHTMLScraperSelectors(t *testing.T) {
+	page := `<ul class="events">
+<li><span class="when">8.3.2026</span> <b>09:30</b> <em>Helig Liturgi</em></li>
+<li><span class="when">14.3.2026</span> <em>Vigilia</em></li>
+<li><span class="when">snart</span> <em>Akathist</em></li>
+<li><span class="when">21.3.2026</span> <em></em></li>
+</ul>`
+
+	s := NewHTMLScraper(HTMLScraperConfig{
+		Metadata:     model.SourceMetadata{Name: "Test Parish"},
+		Parish:       "Test Parish",
+		URL:          "https://example.org/events",
+		ItemSelector: "ul.events li",
+		DateSelector: "span.when",
+		DateFormat:   "2.1.2006",
+		TimeSelector: "b",
+		NameSelector: "em",
+	})
+
+	services, skipped := s.parse(parseHTML(t, page))
+	if len(services) != 2 || skipped != 2 {
+		t.Fatalf("got %d services, %d skipped; want 2, 2", len(services), skipped)
+	}
+	if got := services[0]; got.Date != "2026-03-08" || got.DayOfWeek != "Söndag" || got.Time == nil || *got.Time != "09:30" || got.ServiceName != "Helig Liturgi" {
+		t.Errorf("first service = %+v", got)
+	}
+	if got := services[1]; got.Date != "2026-03-14" || got.Time != nil || got.Parish != "Test Parish" || got.SourceURL != "https://example.org/events" {
+		t.Errorf("second service = %+v", got)
+	}
+}
+
+func TestHTMLScraperServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`<html><body><section class="calendar"></section></body></html>`))
+	}))
+	defer srv.Close()
+
+	services, err := NewFinskaScraper(srv.URL).Fetch(context.Background())
+	if err == nil {
+		t.Fatalf("Fetch returned %d services and no error for a 500 response", len(services))
+	}
+	if !strings.Contains(err.Error(), "500") {
+		t.Errorf("err = %v, want the status code", err)
+	}
+}
+
+func TestFinskaScraperName(t *testing.T) {
+	s := NewFinskaScraper("")
+	if s.Name() != "Helige Nikolai ortodoxa kyrka" || !utf8.ValidString(s.Name()) {
+		t.Errorf("Name() = %q, want %q", s.Name(), "Helige Nikolai ortodoxa kyrka")
+	}
+}
+
+func TestFinskaScraperLatin1Page(t *testing.T) {
+	// "Söndag", "Ortodoxins söndag" and "efteråt" in Windows-1252
+	page := "<section class=\"calendar\"><div class=\"calendar-item\">" +
+		"<div class=\"meta\">2026-03-01 | S\xf6ndag</div>" +
+		"<div class=\"calendar-item-content\"><h3>Liturgi</h3>" +
+		"<div><strong>Ortodoxins s\xf6ndag</strong><br><p>Kyrkkaffe efter\xe5t</p></div>" +
+		"</div></div></section>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1252")
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	services, err := NewFinskaScraper(srv.URL).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("got %d services, want 1", len(services))
+	}
+	svc := services[0]
+	if svc.Occasion == nil || *svc.Occasion != "Ortodoxins söndag" {
+		t.Errorf("Occasion = %v, want %q", svc.Occasion, "Ortodoxins söndag")
+	}
+	if svc.Notes == nil || !strings.Contains(*svc.Notes, "efteråt") {
+		t.Errorf("Notes = %v, want them to contain %q", svc.Notes, "efteråt")
+	}
+	for _, err := range model.Validate(svc) {
+		t.Error(err)
+	}
+}
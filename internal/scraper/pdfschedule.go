@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+// pdfExtractionMaxAge bounds how long an extraction is reused for an
+// unchanged PDF, as for Ryska.
+const pdfExtractionMaxAge = 30 * 24 * time.Hour
+
+// PDFScheduleScraper scrapes a parish that publishes its schedule as a PDF
+// with a text layer. The text is extracted locally and turned into services
+// by the OpenAI text extraction, so a new PDF-based parish only needs its
+// metadata and PDF URL.
+type PDFScheduleScraper struct {
+	NoteCollector
+	meta   model.SourceMetadata
+	parish string
+	pdfURL string
+	store  store.Store
+	vision scheduleTextExtractor // nil without an OpenAI client
+}
+
+// NewPDFScheduleScraper creates a scraper for the PDF at pdfURL. meta.Name
+// is used as the scraper name and the services' source and parish, and
+// meta.Location as their location. Parishes listed in a definitions file
+// with type "pdf" get one from NewDefaultRegistry.
+func NewPDFScheduleScraper(meta model.SourceMetadata, pdfURL string, s store.Store, v *vision.Client) *PDFScheduleScraper {
+	if meta.SourceURL == "" {
+		meta.SourceURL = pdfURL
+	}
+	pdf := &PDFScheduleScraper{
+		meta:   meta,
+		parish: meta.Name,
+		pdfURL: pdfURL,
+		store:  s,
+	}
+	// A nil *vision.Client stored in the interface would not compare equal
+	// to nil, so only set it when there is one
+	if v != nil {
+		pdf.vision = v
+	}
+	return pdf
+}
+
+func (s *PDFScheduleScraper) Name() string {
+	return s.meta.Name
+}
+
+func (s *PDFScheduleScraper) Metadata() model.SourceMetadata {
+	return s.meta
+}
+
+func (s *PDFScheduleScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
+	s.resetNotes()
+
+	data, err := fetchURL(ctx, s.pdfURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching PDF: %w", err)
+	}
+	text, err := extractPDFText(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading PDF: %w", err)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("PDF %s has no text layer", s.pdfURL)
+	}
+	s.note("PDF text: %d chars", len(text))
+
+	hash := sha256.Sum256([]byte(text))
+	cacheKey := "pdf-text/v1/" + hex.EncodeToString(hash[:])
+	var entries []vision.ScheduleEntry
+	if s.store.GetJSONFresh(cacheKey, pdfExtractionMaxAge, &entries) {
+		s.note("cache hit: %d entries", len(entries))
+		return s.entriesToServices(entries), nil
+	}
+
+	if s.vision == nil {
+		return nil, fmt.Errorf("extracting schedule: no OpenAI client configured")
+	}
+	entries, err = s.vision.ExtractScheduleFromText(ctx, text)
+	vision.RecordExtract(s.meta.Name, len(entries), err)
+	if err != nil {
+		return nil, fmt.Errorf("extracting schedule: %w", err)
+	}
+	s.note("AI extraction: %d entries", len(entries))
+
+	if err := s.store.SetJSONStamped(cacheKey, entries); err != nil {
+		s.log().Warn("caching schedule", "err", err)
+	}
+
+	return s.entriesToServices(entries), nil
+}
+
+func (s *PDFScheduleScraper) entriesToServices(entries []vision.ScheduleEntry) []model.ChurchService {
+	var services []model.ChurchService
+	for _, entry := range entries {
+		location := s.meta.Location
+		if entry.Location != "" {
+			location = entry.Location
+		}
+		services = append(services, model.ChurchService{
+			Parish:      s.parish,
+			Source:      s.meta.Name,
+			SourceURL:   s.meta.SourceURL,
+			Date:        entry.Date,
+			DayOfWeek:   entry.DayOfWeek,
+			ServiceName: entry.ServiceName,
+			Location:    strPtr(location),
+			Time:        strPtr(entry.Time),
+			Occasion:    strPtr(entry.Occasion),
+		})
+	}
+	return services
+}
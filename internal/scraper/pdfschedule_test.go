@@ -0,0 +1,225 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+func TestExtractPDFText(t *testing.T) {
+	data, err := os.ReadFile("testdata/schedule.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := extractPDFText(data)
+	if err != nil {
+		t.Fatalf("extractPDFText: %v", err)
+	}
+	want := strings.Join([]string{
+		"Gudstjänster i mars 2026",
+		"1 Söndag  10:00  Helig Liturgi",
+		"7 Lördag  17:00   Vesper (Aftongudstjänst)",
+		"15 Söndag  10:00  Helig Liturgi – Korsets söndag",
+	}, "\n")
+	if text != want {
+		t.Errorf("text:\n%s\nwant:\n%s", text, want)
+	}
+
+	if _, err := extractPDFText([]byte("<html></html>")); err == nil {
+		t.Error("expected an error for a non-PDF file")
+	}
+}
+
+func TestExtractPDFTextPageOrderAndCIDFonts(t *testing.T) {
+	text, err := extractPDFText(pageOrderTestPDF(t))
+	if err != nil {
+		t.Fatalf("extractPDFText: %v", err)
+	}
+	if want := "Mars\n10:00 M\nSida två"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+// pageOrderTestPDF returns a PDF whose page tree lists object 4 before
+// object 3, with both pages in a compressed object stream. F1 is a CID font
+// whose text is only readable through its ToUnicode CMap.
+func pageOrderTestPDF(t testing.TB) []byte {
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <004D>
+<0005> <003A>
+endbfchar
+2 beginbfrange
+<0002> <0004> [<0061> <0072> <0073>]
+<0010> <0019> <0030>
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+	page3 := "<< /Type /Page /Parent 2 0 R /Contents 9 0 R >>\n"
+	page4 := "<< /Type /Page /Parent 2 0 R /Contents [10 0 R 11 0 R] >>"
+	header := fmt.Sprintf("3 0 4 %d\n", len(page3))
+	return buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R 3 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 7 0 R >> >> >>",
+		"", "", // pages 3 and 4 are in object stream 8
+		"<< /Type /Font /Subtype /Type0 /BaseFont /ABCDEF+Font /Encoding /Identity-H /ToUnicode 6 0 R >>",
+		testPDFStream(t, "", cmap),
+		"<< /Type /Font /Subtype /TrueType /BaseFont /Arial /Encoding /WinAnsiEncoding >>",
+		testPDFStream(t, fmt.Sprintf("/Type /ObjStm /N 2 /First %d", len(header)), header+page3+page4),
+		testPDFStream(t, "", "BT /F2 12 Tf 72 700 Td (Sida tv\345) Tj ET"),
+		testPDFStream(t, "", "BT /F1 12 Tf 72 700 Td <0001000200030004> Tj ET"),
+		testPDFStream(t, "", "q BI /W 1 /H 1 /BPC 8 /CS /G ID \x00\xff) EI Q\nBT /F1 12 Tf 72 680 Td [<00110010000500100010> -300 <0001>] TJ ET"),
+	)
+}
+
+func TestExtractPDFTextInflateLimit(t *testing.T) {
+	t.Setenv("SCRAPER_MAX_RESPONSE_BYTES", "10000")
+	// Each stream is under the limit, but together they exceed it
+	content := "BT (" + strings.Repeat("a", 6000) + ") Tj ET"
+	data := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+		testPDFStream(t, "", content),
+		testPDFStream(t, "", content),
+	)
+
+	if _, err := extractPDFText(data); !errors.Is(err, errResponseTooLarge) {
+		t.Errorf("err = %v, want errResponseTooLarge", err)
+	}
+}
+
+func FuzzExtractPDFText(f *testing.F) {
+	fixture, err := os.ReadFile("testdata/schedule.pdf")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(fixture)
+	f.Add(pageOrderTestPDF(f))
+	f.Add([]byte("%PDF-1.7\n1 0 obj\n" + strings.Repeat("[", 100000) + "\nendobj\n"))
+	f.Add([]byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 1 0 R /Kids [1 0 R] >>\nendobj\ntrailer << /Root 1 0 R >>"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Any input must return rather than panic or hang
+		extractPDFText(data)
+	})
+}
+
+// buildTestPDF writes objects numbered from 1 into a PDF whose trailer
+// names object 1 as the catalog. Empty objects are left out.
+func buildTestPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		if obj != "" {
+			fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+		}
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// testPDFStream returns a FlateDecode stream object with the given extra
+// dictionary entries.
+func testPDFStream(t testing.TB, dict, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(content))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("<< %s /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", dict, buf.Len(), buf.Bytes())
+}
+
+// recordingTextExtractor returns canned entries and records the text it was given.
+type recordingTextExtractor struct {
+	entries []vision.ScheduleEntry
+	text    string
+	calls   int
+}
+
+func (r *recordingTextExtractor) ExtractScheduleFromText(ctx context.Context, text string) ([]vision.ScheduleEntry, error) {
+	r.text = text
+	r.calls++
+	return r.entries, nil
+}
+
+func TestPDFScheduleScraper(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/schedule.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdf)
+	}))
+	defer srv.Close()
+
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	extractor := &recordingTextExtractor{entries: []vision.ScheduleEntry{
+		{Date: "2026-03-01", DayOfWeek: "Söndag", Time: "10:00", ServiceName: "Helig Liturgi"},
+		{Date: "2026-03-07", DayOfWeek: "Lördag", Time: "17:00", ServiceName: "Vesper", Location: "Kapellet"},
+	}}
+	s := NewPDFScheduleScraper(model.SourceMetadata{Name: "Test Parish", Location: "Kyrkvägen 1"}, srv.URL+"/schema.pdf", st, nil)
+	s.vision = extractor
+
+	services, err := s.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if !strings.Contains(extractor.text, "7 Lördag  17:00   Vesper") {
+		t.Errorf("extractor got text:\n%s", extractor.text)
+	}
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2", len(services))
+	}
+	first, second := services[0], services[1]
+	if first.Source != "Test Parish" || first.SourceURL != srv.URL+"/schema.pdf" || first.Date != "2026-03-01" {
+		t.Errorf("first service = %+v", first)
+	}
+	if first.Location == nil || *first.Location != "Kyrkvägen 1" || second.Location == nil || *second.Location != "Kapellet" {
+		t.Errorf("locations = %v, %v; want the source's, then the entry's", first.Location, second.Location)
+	}
+
+	// Unchanged text reuses the stored extraction
+	if _, err := s.Fetch(context.Background()); err != nil {
+		t.Fatalf("second Fetch: %v", err)
+	}
+	if extractor.calls != 1 {
+		t.Errorf("extractor called %d times, want 1", extractor.calls)
+	}
+
+	// Without an OpenAI client, uncached text is an error rather than a panic
+	empty, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	s = NewPDFScheduleScraper(model.SourceMetadata{Name: "Test Parish"}, srv.URL+"/schema.pdf", empty, nil)
+	if _, err := s.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "no OpenAI client") {
+		t.Errorf("Fetch without a vision client: err = %v", err)
+	}
+}
@@ -0,0 +1,1003 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// extractPDFText returns the text drawn on the pages of a PDF, in page order
+// and one line per text line. Pages are found by walking the page tree from
+// the catalog, including objects stored in object streams. Strings are
+// decoded through their font's ToUnicode CMap, which is how CID-keyed
+// (Type0) fonts map their codes to text, and as WinAnsi for fonts without
+// one. Only uncompressed and FlateDecode streams are read; encrypted files
+// and scanned pages give no text.
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("not a PDF file")
+	}
+
+	doc := parsePDF(data)
+	pages := doc.pages()
+	if doc.err != nil {
+		return "", doc.err
+	}
+	if len(pages) == 0 {
+		return "", fmt.Errorf("no pages found")
+	}
+
+	var lines []string
+	for _, page := range pages {
+		e := &pdfTextExtractor{doc: doc}
+		e.run(doc.contents(page.dict), page.resources, 0)
+		e.flush()
+		lines = append(lines, e.lines...)
+	}
+	if doc.err != nil {
+		return "", doc.err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// PDF objects are represented as nil, bool, float64, []byte (strings),
+// pdfName, []any (arrays), pdfDict, pdfRef and *pdfStream. Operators in
+// content streams, and other bare words, are pdfKeyword.
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict    pdfDict
+		raw     []byte // undecoded data
+		decoded []byte // set by pdfDocument.decode
+		done    bool   // whether decode has run
+		ok      bool   // and succeeded
+	}
+)
+
+// pdfDocument holds the objects of a PDF by object number.
+type pdfDocument struct {
+	objects map[int]any
+	root    any // the catalog, as named by the last trailer
+	fonts   map[pdfRef]*pdfFont
+
+	// inflateBudget is how many more bytes streams may decompress to, so
+	// that a small file cannot expand without bound. Once it runs out, err
+	// is set and no more streams are decoded.
+	inflateBudget int64
+	err           error
+}
+
+// pdfObjectStart matches the header of an indirect object or a trailer.
+var pdfObjectStart = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b|\btrailer\b`)
+
+// parsePDF reads every object in the file. Objects are found by scanning
+// for their headers rather than through the cross-reference table, whose
+// offsets are often wrong in files from small generators; a later
+// definition of an object replaces an earlier one, as with incremental
+// updates.
+func parsePDF(data []byte) *pdfDocument {
+	doc := &pdfDocument{
+		objects:       make(map[int]any),
+		fonts:         make(map[pdfRef]*pdfFont),
+		inflateBudget: maxResponseBytes(),
+	}
+	var objectStreams []*pdfStream
+
+	pos := 0
+	for _, m := range pdfObjectStart.FindAllSubmatchIndex(data, -1) {
+		if m[0] < pos {
+			continue // inside stream data
+		}
+		p := newPDFParser(data, m[1])
+		if m[2] < 0 {
+			if trailer, ok := p.object(); ok {
+				if dict, ok := trailer.(pdfDict); ok && dict["Root"] != nil {
+					doc.root = dict["Root"]
+				}
+			}
+			continue
+		}
+
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		obj, ok := p.object()
+		if !ok {
+			break
+		}
+		if dict, ok := obj.(pdfDict); ok {
+			// "stream" may have been read ahead after a number at the end of
+			// the dictionary, but is always the last token lexed
+			if tok, ok := p.token(); ok && tok == pdfKeyword("stream") && len(p.queued) == 0 {
+				stream := &pdfStream{dict: dict, raw: p.lex.streamData(dict)}
+				pos = p.lex.pos
+				obj = stream
+				switch dict["Type"] {
+				case pdfName("ObjStm"):
+					objectStreams = append(objectStreams, stream)
+				case pdfName("XRef"):
+					// A cross-reference stream doubles as the trailer
+					if dict["Root"] != nil {
+						doc.root = dict["Root"]
+					}
+				}
+			}
+		}
+		doc.objects[num] = obj
+	}
+
+	for _, s := range objectStreams {
+		doc.loadObjectStream(s)
+	}
+	return doc
+}
+
+// loadObjectStream adds the objects compressed into an object stream,
+// except those also defined directly in the file.
+func (d *pdfDocument) loadObjectStream(s *pdfStream) {
+	data, ok := d.decode(s)
+	if !ok {
+		return
+	}
+	n, _ := d.resolve(s.dict["N"]).(float64)
+	first, _ := d.resolve(s.dict["First"]).(float64)
+
+	// The stream starts with pairs of object number and offset from First
+	header := pdfLexer{data: data}
+	for i := 0; i < int(n); i++ {
+		num, _ := header.token()
+		offset, _ := header.token()
+		numF, ok1 := num.(float64)
+		offsetF, ok2 := offset.(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, ok := d.objects[int(numF)]; ok {
+			continue
+		}
+		start := int(first) + int(offsetF)
+		if start < 0 || start >= len(data) {
+			continue
+		}
+		if obj, ok := newPDFParser(data, start).object(); ok {
+			d.objects[int(numF)] = obj
+		}
+	}
+}
+
+// resolve follows indirect references. Missing objects resolve to nil.
+func (d *pdfDocument) resolve(v any) any {
+	for range 16 {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.objects[ref.num]
+	}
+	return nil
+}
+
+// dict resolves v to a dictionary, or nil if it is something else.
+func (d *pdfDocument) dict(v any) pdfDict {
+	dict, _ := d.resolve(v).(pdfDict)
+	return dict
+}
+
+// decode returns the decoded data of a stream, or false if it uses a filter
+// other than FlateDecode or would exceed the inflate budget. Each stream is
+// decoded once.
+func (d *pdfDocument) decode(s *pdfStream) ([]byte, bool) {
+	if !s.done {
+		s.decoded, s.ok = d.decodeStream(s)
+		s.done = true
+	}
+	return s.decoded, s.ok
+}
+
+func (d *pdfDocument) decodeStream(s *pdfStream) ([]byte, bool) {
+	var filters []any
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+
+	data := s.raw
+	for _, f := range filters {
+		if d.resolve(f) != pdfName("FlateDecode") {
+			return nil, false
+		}
+		if d.err != nil {
+			return nil, false
+		}
+		var err error
+		if data, err = inflate(data, d.inflateBudget); err != nil {
+			if errors.Is(err, errResponseTooLarge) {
+				d.err = err
+			}
+			return nil, false
+		}
+		d.inflateBudget -= int64(len(data))
+	}
+	return data, true
+}
+
+// inflate decompresses FlateDecode data, which should be zlib-wrapped but
+// is raw deflate in some files, to at most limit bytes. Data up to a corrupt
+// or truncated end is kept, as other PDF readers do.
+func inflate(data []byte, limit int64) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if int64(len(out)) > limit {
+		return nil, fmt.Errorf("%w: PDF streams decompress to more than %d bytes", errResponseTooLarge, maxResponseBytes())
+	}
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+// pdfPage is a leaf of the page tree with the resources it draws with.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages in document order. Resources are inherited from
+// the nearest ancestor in the page tree that defines them.
+func (d *pdfDocument) pages() []pdfPage {
+	catalog := d.dict(d.root)
+	if catalog == nil {
+		catalog = d.catalog()
+	}
+
+	var pages []pdfPage
+	visited := make(map[pdfRef]bool)
+	var walk func(node any, resources pdfDict)
+	walk = func(node any, resources pdfDict) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		dict := d.dict(node)
+		if dict == nil {
+			return
+		}
+		if res := d.dict(dict["Resources"]); res != nil {
+			resources = res
+		}
+		if kids, ok := d.resolve(dict["Kids"]).([]any); ok {
+			for _, kid := range kids {
+				walk(kid, resources)
+			}
+			return
+		}
+		if dict["Type"] == pdfName("Page") || dict["Contents"] != nil {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+		}
+	}
+	walk(catalog["Pages"], nil)
+	return pages
+}
+
+// catalog finds the catalog of a file without a usable trailer, taking the
+// highest-numbered one if there are several.
+func (d *pdfDocument) catalog() pdfDict {
+	var catalog pdfDict
+	best := -1
+	for num, obj := range d.objects {
+		if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") && num > best {
+			catalog, best = dict, num
+		}
+	}
+	return catalog
+}
+
+// contents returns a page's decoded content, joining the streams of a
+// Contents array.
+func (d *pdfDocument) contents(page pdfDict) []byte {
+	var streams []any
+	switch c := d.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{c}
+	case []any:
+		streams = c
+	}
+
+	var out []byte
+	for _, s := range streams {
+		stream, ok := d.resolve(s).(*pdfStream)
+		if !ok {
+			continue
+		}
+		if data, ok := d.decode(stream); ok {
+			out = append(out, data...)
+			out = append(out, '\n')
+		}
+	}
+	return out
+}
+
+// pdfFont decodes the strings shown with a font.
+type pdfFont struct {
+	cmap    *pdfCMap // from ToUnicode, nil if the font has none
+	codeLen int      // bytes per code where the CMap gives no codespace: 2 for Type0 fonts
+}
+
+// font returns the decoder for a font dictionary, caching it by reference.
+func (d *pdfDocument) font(v any) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if f, ok := d.fonts[ref]; isRef && ok {
+		return f
+	}
+
+	dict := d.dict(v)
+	f := &pdfFont{codeLen: 1}
+	if dict["Subtype"] == pdfName("Type0") {
+		f.codeLen = 2
+	}
+	if s, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, ok := d.decode(s); ok {
+			f.cmap = parseCMap(data)
+		}
+	}
+
+	if isRef {
+		d.fonts[ref] = f
+	}
+	return f
+}
+
+// decode returns the text of a string shown with the font. A nil font, for
+// text shown before any Tf, reads as WinAnsi.
+func (f *pdfFont) decode(s []byte) string {
+	if f == nil || (f.cmap == nil && f.codeLen == 1) {
+		return winAnsi(s)
+	}
+	if f.cmap == nil {
+		// Without a CMap the codes of a CID font are glyph numbers, not text
+		return ""
+	}
+
+	var sb strings.Builder
+	for len(s) > 0 {
+		n := f.cmap.codeLength(s, f.codeLen)
+		code := s[:n]
+		s = s[n:]
+		if text, ok := f.cmap.lookup(code); ok {
+			sb.WriteString(text)
+		} else if n == 1 {
+			sb.WriteString(winAnsi(code))
+		}
+	}
+	return sb.String()
+}
+
+// pdfCMap is a ToUnicode CMap, mapping character codes of one to four bytes
+// to text.
+type pdfCMap struct {
+	codespaces []pdfCodespace
+	chars      map[string]string // from bfchar, keyed by code bytes
+	ranges     []pdfCMapRange    // from bfrange
+}
+
+type pdfCodespace struct {
+	lo, hi []byte
+}
+
+type pdfCMapRange struct {
+	n      int // code length in bytes
+	lo, hi uint32
+	dst    []rune   // text for lo; later codes increment its last rune
+	dsts   []string // or the text for each code, from an array
+}
+
+// parseCMap reads the codespace ranges and bfchar and bfrange mappings of a
+// ToUnicode CMap.
+func parseCMap(data []byte) *pdfCMap {
+	c := &pdfCMap{chars: make(map[string]string)}
+	p := newPDFParser(data, 0)
+	for {
+		tok, ok := p.object()
+		if !ok {
+			return c
+		}
+		switch tok {
+		case pdfKeyword("begincodespacerange"):
+			ops := p.until("endcodespacerange")
+			for i := 0; i+1 < len(ops); i += 2 {
+				lo, _ := ops[i].([]byte)
+				hi, _ := ops[i+1].([]byte)
+				if len(lo) > 0 && len(lo) == len(hi) {
+					c.codespaces = append(c.codespaces, pdfCodespace{lo: lo, hi: hi})
+				}
+			}
+		case pdfKeyword("beginbfchar"):
+			ops := p.until("endbfchar")
+			for i := 0; i+1 < len(ops); i += 2 {
+				code, _ := ops[i].([]byte)
+				if dst, ok := ops[i+1].([]byte); ok && len(code) > 0 {
+					c.chars[string(code)] = utf16BE(dst)
+				}
+			}
+		case pdfKeyword("beginbfrange"):
+			ops := p.until("endbfrange")
+			for i := 0; i+2 < len(ops); i += 3 {
+				lo, _ := ops[i].([]byte)
+				hi, _ := ops[i+1].([]byte)
+				if len(lo) == 0 || len(lo) > 4 || len(lo) != len(hi) {
+					continue
+				}
+				r := pdfCMapRange{n: len(lo), lo: codeValue(lo), hi: codeValue(hi)}
+				switch dst := ops[i+2].(type) {
+				case []byte:
+					r.dst = []rune(utf16BE(dst))
+				case []any:
+					for _, d := range dst {
+						b, _ := d.([]byte)
+						r.dsts = append(r.dsts, utf16BE(b))
+					}
+				}
+				c.ranges = append(c.ranges, r)
+			}
+		}
+	}
+}
+
+// codeLength returns the length of the code at the start of s: that of the
+// codespace range it falls in, or def if none matches.
+func (c *pdfCMap) codeLength(s []byte, def int) int {
+	for _, cs := range c.codespaces {
+		n := len(cs.lo)
+		if n > len(s) {
+			continue
+		}
+		in := true
+		for i := range n {
+			if s[i] < cs.lo[i] || s[i] > cs.hi[i] {
+				in = false
+				break
+			}
+		}
+		if in {
+			return n
+		}
+	}
+	return min(def, len(s))
+}
+
+func (c *pdfCMap) lookup(code []byte) (string, bool) {
+	if text, ok := c.chars[string(code)]; ok {
+		return text, true
+	}
+	v := codeValue(code)
+	for _, r := range c.ranges {
+		if r.n != len(code) || v < r.lo || v > r.hi {
+			continue
+		}
+		offset := int(v - r.lo)
+		if r.dsts != nil {
+			if offset < len(r.dsts) {
+				return r.dsts[offset], true
+			}
+			return "", false
+		}
+		if len(r.dst) == 0 {
+			return "", false
+		}
+		text := append([]rune(nil), r.dst...)
+		text[len(text)-1] += rune(offset)
+		return string(text), true
+	}
+	return "", false
+}
+
+// codeValue reads a code of up to four bytes as a big-endian number.
+func codeValue(code []byte) uint32 {
+	var v uint32
+	for _, b := range code {
+		v = v<<8 | uint32(b)
+	}
+	return v
+}
+
+// utf16BE decodes the UTF-16BE text of a CMap destination.
+func utf16BE(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+// pdfTextExtractor interprets the text operators of a page's content,
+// starting a new line whenever the text position moves vertically.
+type pdfTextExtractor struct {
+	doc     *pdfDocument
+	lines   []string
+	line    strings.Builder
+	font    *pdfFont
+	lastTmY float64
+}
+
+// maxFormDepth bounds the nesting of form XObjects drawn with Do.
+const maxFormDepth = 8
+
+func (e *pdfTextExtractor) run(content []byte, resources pdfDict, depth int) {
+	fonts := e.doc.dict(resources["Font"])
+	xobjects := e.doc.dict(resources["XObject"])
+
+	var (
+		operands []any
+		saved    []*pdfFont // fonts saved by q, restored by Q
+	)
+	p := newPDFParser(content, 0)
+	for {
+		obj, ok := p.object()
+		if !ok {
+			return
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		nums := pdfNumbers(operands)
+		switch op {
+		case "q":
+			saved = append(saved, e.font)
+		case "Q":
+			if n := len(saved); n > 0 {
+				e.font, saved = saved[n-1], saved[:n-1]
+			}
+		case "Tf":
+			if len(operands) > 0 {
+				if name, ok := operands[0].(pdfName); ok {
+					e.font = e.doc.font(fonts[name])
+				}
+			}
+		case "Tj", "TJ":
+			e.show(operands)
+		case "'", `"`:
+			e.flush()
+			e.show(operands)
+		case "Td", "TD":
+			if len(nums) == 2 && nums[1] != 0 {
+				e.flush()
+			} else {
+				e.space()
+			}
+		case "Tm":
+			if len(nums) == 6 && nums[5] != e.lastTmY {
+				e.flush()
+				e.lastTmY = nums[5]
+			} else {
+				e.space()
+			}
+		case "T*", "ET":
+			e.flush()
+		case "Do":
+			if len(operands) == 1 && depth < maxFormDepth {
+				if name, ok := operands[0].(pdfName); ok {
+					e.drawForm(xobjects[name], resources, depth)
+				}
+			}
+		case "BI":
+			p.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// drawForm runs the content of a form XObject, which uses its own
+// resources if it has them.
+func (e *pdfTextExtractor) drawForm(v any, resources pdfDict, depth int) {
+	form, ok := e.doc.resolve(v).(*pdfStream)
+	if !ok || form.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, ok := e.doc.decode(form)
+	if !ok {
+		return
+	}
+	if res := e.doc.dict(form.dict["Resources"]); res != nil {
+		resources = res
+	}
+	font := e.font
+	e.run(data, resources, depth+1)
+	e.font = font
+}
+
+// show writes the strings of a text-showing operator. In a TJ array, a
+// kerning adjustment wide enough to be a word gap becomes a space.
+func (e *pdfTextExtractor) show(operands []any) {
+	for _, op := range operands {
+		switch op := op.(type) {
+		case []byte:
+			e.line.WriteString(e.font.decode(op))
+		case []any:
+			for _, el := range op {
+				switch el := el.(type) {
+				case []byte:
+					e.line.WriteString(e.font.decode(el))
+				case float64:
+					if el < -200 {
+						e.line.WriteByte(' ')
+					}
+				}
+			}
+		}
+	}
+}
+
+func (e *pdfTextExtractor) flush() {
+	if text := strings.TrimSpace(e.line.String()); text != "" {
+		e.lines = append(e.lines, text)
+	}
+	e.line.Reset()
+}
+
+func (e *pdfTextExtractor) space() {
+	if e.line.Len() > 0 {
+		e.line.WriteByte(' ')
+	}
+}
+
+func pdfNumbers(operands []any) []float64 {
+	var nums []float64
+	for _, op := range operands {
+		if n, ok := op.(float64); ok {
+			nums = append(nums, n)
+		}
+	}
+	return nums
+}
+
+// pdfParser reads objects from a token stream, looking ahead to recognize
+// "num gen R" references.
+type pdfParser struct {
+	lex    pdfLexer
+	queued []any // tokens read ahead, last one next
+	depth  int   // arrays and dictionaries being read
+}
+
+// maxPDFNesting bounds how deeply arrays and dictionaries may nest, so that
+// a file of brackets cannot exhaust the stack.
+const maxPDFNesting = 64
+
+func newPDFParser(data []byte, pos int) *pdfParser {
+	return &pdfParser{lex: pdfLexer{data: data, pos: pos}}
+}
+
+func (p *pdfParser) token() (any, bool) {
+	if n := len(p.queued); n > 0 {
+		tok := p.queued[n-1]
+		p.queued = p.queued[:n-1]
+		return tok, true
+	}
+	return p.lex.token()
+}
+
+// object reads one object. Keywords other than true, false and null are
+// returned as they are, as are stray closing delimiters.
+func (p *pdfParser) object() (any, bool) {
+	tok, ok := p.token()
+	if !ok {
+		return nil, false
+	}
+
+	switch t := tok.(type) {
+	case pdfKeyword:
+		if t == "[" || t == "<<" {
+			if p.depth >= maxPDFNesting {
+				return nil, false
+			}
+			p.depth++
+			defer func() { p.depth-- }()
+		}
+		switch t {
+		case "[":
+			arr := []any{}
+			for {
+				obj, ok := p.object()
+				if !ok || obj == pdfKeyword("]") {
+					return arr, true
+				}
+				arr = append(arr, obj)
+			}
+		case "<<":
+			dict := make(pdfDict)
+			for {
+				key, ok := p.object()
+				if !ok || key == pdfKeyword(">>") {
+					return dict, true
+				}
+				val, ok := p.object()
+				if !ok || val == pdfKeyword(">>") {
+					return dict, true
+				}
+				if name, ok := key.(pdfName); ok {
+					dict[name] = val
+				}
+			}
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+	case float64:
+		gen, ok := p.token()
+		if !ok {
+			return t, true
+		}
+		r, ok := p.token()
+		if g, isNum := gen.(float64); ok && isNum && r == pdfKeyword("R") {
+			return pdfRef{num: int(t), gen: int(g)}, true
+		}
+		if ok {
+			p.queued = append(p.queued, r)
+		}
+		p.queued = append(p.queued, gen)
+	}
+	return tok, true
+}
+
+// until reads objects up to the keyword end.
+func (p *pdfParser) until(end pdfKeyword) []any {
+	var objs []any
+	for {
+		obj, ok := p.object()
+		if !ok || obj == end {
+			return objs
+		}
+		objs = append(objs, obj)
+	}
+}
+
+var (
+	inlineImageData = regexp.MustCompile(`\sID\s`)
+	inlineImageEnd  = regexp.MustCompile(`\sEI(\s|$)`)
+)
+
+// skipInlineImage skips past an inline image (BI ... ID data EI), whose data
+// is binary and cannot be tokenized.
+func (p *pdfParser) skipInlineImage() {
+	p.queued = nil
+	rest := p.lex.data[p.lex.pos:]
+	start := inlineImageData.FindIndex(rest)
+	if start == nil {
+		p.lex.pos = len(p.lex.data)
+		return
+	}
+	end := inlineImageEnd.FindIndex(rest[start[1]:])
+	if end == nil {
+		p.lex.pos = len(p.lex.data)
+		return
+	}
+	p.lex.pos += start[1] + end[1]
+}
+
+// pdfLexer splits PDF syntax into tokens: numbers, strings, names, and
+// keywords for everything else, including the delimiters [ ] << >>.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) token() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literal(), true
+	case c == '<' && l.peek(1) == '<', c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfKeyword(l.data[l.pos-2 : l.pos]), true
+	case c == '<':
+		return l.hex(), true
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(l.data[start:l.pos]), true
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		word := l.word()
+		n, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return pdfKeyword(word), true
+		}
+		return n, true
+	}
+	return pdfKeyword(l.word()), true
+}
+
+// streamData reads the data of a stream whose "stream" keyword was just
+// read. Length is trusted only if "endstream" follows it, since it may be
+// an indirect reference or simply wrong.
+func (l *pdfLexer) streamData(dict pdfDict) []byte {
+	if l.peek(0) == '\r' {
+		l.pos++
+	}
+	if l.peek(0) == '\n' {
+		l.pos++
+	}
+	start := l.pos
+
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && start+int(n) <= len(l.data) {
+		end := start + int(n)
+		if bytes.HasPrefix(bytes.TrimLeft(l.data[end:], "\r\n \t"), []byte("endstream")) {
+			l.pos = end
+			return l.data[start:end]
+		}
+	}
+	i := bytes.Index(l.data[start:], []byte("endstream"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return l.data[start:]
+	}
+	l.pos = start + i
+	return bytes.TrimRight(l.data[start:l.pos], "\r\n")
+}
+
+// literal reads a (string), which may contain balanced parentheses and
+// backslash escapes.
+func (l *pdfLexer) literal() []byte {
+	out := []byte{}
+	depth := 0
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				l.pos++
+				return out
+			}
+			depth--
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for k := 0; k < 3 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; k++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					l.pos--
+					out = append(out, byte(n))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// hex reads a <hex string>.
+func (l *pdfLexer) hex() []byte {
+	var digits []byte
+	for l.pos++; l.pos < len(l.data) && l.data[l.pos] != '>'; l.pos++ {
+		if c := l.data[l.pos]; strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		n, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(n)
+	}
+	return out
+}
+
+// word reads a run of regular characters, or a single delimiter.
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// winAnsiPunctuation maps the WinAnsi bytes in 0x80-0x9F that commonly occur
+// in schedules; bytes from 0xA0 up match Latin-1.
+var winAnsiPunctuation = map[byte]rune{
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+}
+
+// winAnsi decodes a PDF string drawn with a WinAnsi-encoded font.
+func winAnsi(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if r, ok := winAnsiPunctuation[c]; ok {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
@@ -29,6 +29,15 @@ func (s *RomanianScraper) Name() string {
 	return romanianSourceName
 }
 
+func (s *RomanianScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      romanianSourceName,
+		Location:  romanianLocation,
+		Languages: []string{"Rumänska"},
+		SourceURL: romanianCalendarPage,
+	}
+}
+
 func (s *RomanianScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	data, err := fetchURL(ctx, romanianICSURL)
@@ -6,7 +6,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"html"
-	"log"
 	"os"
 	"regexp"
 	"strings"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/chromedp/chromedp"
 
+	"ortodoxa-gudstjanster/internal/dateutil"
 	"ortodoxa-gudstjanster/internal/model"
 	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/vision"
@@ -21,8 +21,18 @@ import (
 
 // ExtractRyskaScheduleText fetches the Ryska website using headless Chrome
 // (needed because it's a Wix site that renders content via JavaScript)
-// and extracts the schedule text.
+// and extracts the schedule text. This is the text the scraper extracts
+// services from.
 func ExtractRyskaScheduleText(ctx context.Context) (string, error) {
+	body, err := renderRyskaPage(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ExtractRyskaScheduleTextFromHTML(body), nil
+}
+
+// renderRyskaPage returns the rendered page body; replaceable in tests.
+var renderRyskaPage = func(ctx context.Context) (string, error) {
 	opts := chromedp.DefaultExecAllocatorOptions[:]
 	if chromePath := os.Getenv("CHROME_PATH"); chromePath != "" {
 		opts = append(opts, chromedp.ExecPath(chromePath))
@@ -48,8 +58,7 @@ func ExtractRyskaScheduleText(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("fetching with chromedp: %w", err)
 	}
-
-	return ExtractRyskaScheduleTextFromHTML(body), nil
+	return body, nil
 }
 
 // ExtractRyskaScheduleTextFromHTML extracts schedule text from raw HTML.
@@ -71,24 +80,45 @@ func ExtractRyskaScheduleTextFromHTML(htmlContent string) string {
 	}
 
 	// Add newlines for better structure
-	content = regexp.MustCompile(`(?i)\s+(Januari|Februari|Mars|April|Maj|Juni|Juli|Augusti|September|Oktober|November|December)\s`).ReplaceAllString(content, "\n\n$1\n")
-	content = regexp.MustCompile(`\s+(\d{1,2}\s+(?:Söndag|Måndag|Tisdag|Onsdag|Torsdag|Fredag|Lördag))`).ReplaceAllString(content, "\n$1")
+	content = regexp.MustCompile(`(?i)\s+(`+strings.Join(dateutil.MonthNames[:], "|")+`)\s`).ReplaceAllString(content, "\n\n$1\n")
+	content = regexp.MustCompile(`\s+(\d{1,2}\s+`+dateutil.DayPattern+`)`).ReplaceAllString(content, "\n$1")
 
 	return strings.TrimSpace(content)
 }
 
+// RyskaScheduleTextFromMonth drops the part of text extracted by
+// ExtractRyskaScheduleTextFromHTML that comes before the heading of month,
+// so that earlier months are not passed on. text is returned unchanged if
+// it has no heading for month.
+func RyskaScheduleTextFromMonth(text string, month time.Month) string {
+	heading := regexp.MustCompile(`(?im)^` + dateutil.MonthName(month) + `$`)
+	if loc := heading.FindStringIndex(text); loc != nil {
+		return text[loc[0]:]
+	}
+	return text
+}
+
 const (
 	ryskaSourceName = "Kristi Förklarings Ortodoxa Församling"
 	ryskaParishSlug = "kristi-forklaring"
 	ryskaURL        = "https://www.ryskaortodoxakyrkan.se/gudstjänst"
 	ryskaLocation   = "Birger Jarlsgatan 98, 114 20 Stockholm"
+
+	// ryskaExtractionMaxAge bounds how long an extraction is reused for
+	// unchanged text, so prompt improvements eventually reach the stored data.
+	ryskaExtractionMaxAge = 30 * 24 * time.Hour
 )
 
+// scheduleTextExtractor is the part of vision.Client used by RyskaScraper.
+type scheduleTextExtractor interface {
+	ExtractScheduleFromText(ctx context.Context, text string) ([]vision.ScheduleEntry, error)
+}
+
 // RyskaScraper scrapes the Russian Orthodox Church schedule.
 type RyskaScraper struct {
 	NoteCollector
 	store  store.Store
-	vision *vision.Client
+	vision scheduleTextExtractor
 }
 
 // NewRyskaScraper creates a new scraper for the Russian Orthodox Church.
@@ -103,6 +133,17 @@ func (s *RyskaScraper) Name() string {
 	return ryskaSourceName
 }
 
+func (s *RyskaScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      ryskaSourceName,
+		Location:  ryskaLocation,
+		Languages: []string{"Kyrkoslaviska", "Svenska"},
+		SourceURL: ryskaURL,
+		Lat:       59.3483,
+		Lng:       18.0579,
+	}
+}
+
 func (s *RyskaScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 
@@ -116,28 +157,35 @@ func (s *RyskaScraper) Fetch(ctx context.Context) ([]model.ChurchService, error)
 		s.note("Chrome rendered page: schedule text %d chars", len(content))
 	}
 
+	return s.servicesFromText(ctx, content)
+}
+
+// servicesFromText extracts services from the schedule text, reusing the
+// stored result for text that has been extracted before.
+func (s *RyskaScraper) servicesFromText(ctx context.Context, content string) ([]model.ChurchService, error) {
 	// Compute checksum for caching
 	hash := sha256.Sum256([]byte(content))
 	checksum := hex.EncodeToString(hash[:])
 	cacheKey := "ryska-ocr/v4/" + checksum
 	// Check store for cached result
 	var entries []vision.ScheduleEntry
-	if s.store.GetJSON(cacheKey, &entries) {
+	if s.store.GetJSONFresh(cacheKey, ryskaExtractionMaxAge, &entries) {
 		s.note("cache hit: %d entries", len(entries))
 		return s.entriesToServices(entries), nil
 	}
 
 	// Use OpenAI to extract schedule from text
-	entries, err = s.vision.ExtractScheduleFromText(ctx, content)
+	entries, err := s.vision.ExtractScheduleFromText(ctx, content)
+	vision.RecordExtract(ryskaSourceName, len(entries), err)
 	if err != nil {
 		return nil, fmt.Errorf("extracting schedule: %w", err)
 	}
 	s.note("AI extraction: %d entries", len(entries))
 
 	// Cache result
-	if err := s.store.SetJSON(cacheKey, entries); err != nil {
+	if err := s.store.SetJSONStamped(cacheKey, entries); err != nil {
 		// Log but don't fail
-		log.Printf("warning: failed to cache ryska schedule: %v", err)
+		s.log().Warn("caching schedule", "err", err)
 	}
 
 	return s.entriesToServices(entries), nil
@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/store"
+	"ortodoxa-gudstjanster/internal/vision"
+)
+
+type fakeTextExtractor struct {
+	entries []vision.ScheduleEntry
+}
+
+func (f fakeTextExtractor) ExtractScheduleFromText(ctx context.Context, text string) ([]vision.ScheduleEntry, error) {
+	return f.entries, nil
+}
+
+func visionExtractCount(source, result string) uint64 {
+	for _, c := range vision.ExtractCounts() {
+		if c.Source == source && c.Result == result {
+			return c.Count
+		}
+	}
+	return 0
+}
+
+func TestRyskaEmptyExtractionCounted(t *testing.T) {
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	s := &RyskaScraper{store: st, vision: fakeTextExtractor{}}
+
+	before := visionExtractCount(ryskaSourceName, vision.ExtractEmpty)
+	services, err := s.servicesFromText(context.Background(), "GUDSTJÄNSTKUNGÖRELSE (layout changed)")
+	if err != nil {
+		t.Fatalf("servicesFromText: %v", err)
+	}
+	if len(services) != 0 {
+		t.Errorf("got %d services, want 0", len(services))
+	}
+	if got := visionExtractCount(ryskaSourceName, vision.ExtractEmpty); got != before+1 {
+		t.Errorf("empty count = %d, want %d", got, before+1)
+	}
+}
+
+const ryskaTestHTML = `<html><body><div>Meny</div>
+<p>GUDSTJÄNSTKUNGÖRELSE för våren</p>
+<h2>Januari</h2><p>6 Tisdag Liturgi kl. 10:00</p>
+<h2>Mars</h2><p>1 Söndag Liturgi kl. 10:00</p><p>7 Lördag Vesper kl. 17:00</p>
+<h2>April</h2><p>12 Söndag Påsk kl. 00:00</p>
+<footer>bottom of page</footer></body></html>`
+
+func TestRyskaScheduleTextFromMonth(t *testing.T) {
+	text := ExtractRyskaScheduleTextFromHTML(ryskaTestHTML)
+
+	got := RyskaScheduleTextFromMonth(text, time.March)
+	if !strings.HasPrefix(got, "Mars\n") {
+		t.Errorf("text should start at the March heading, got:\n%s", got)
+	}
+	if strings.Contains(got, "Januari") || !strings.Contains(got, "12 Söndag Påsk") {
+		t.Errorf("text should hold March and April only, got:\n%s", got)
+	}
+
+	if got := RyskaScheduleTextFromMonth(text, time.June); got != text {
+		t.Errorf("text without the month heading should be unchanged, got:\n%s", got)
+	}
+}
+
+// capturingExtractor records the text it is asked to extract services from.
+type capturingExtractor struct {
+	text string
+}
+
+func (c *capturingExtractor) ExtractScheduleFromText(ctx context.Context, text string) ([]vision.ScheduleEntry, error) {
+	c.text = text
+	return nil, nil
+}
+
+func TestRyskaFetchParsesScheduleText(t *testing.T) {
+	orig := renderRyskaPage
+	defer func() { renderRyskaPage = orig }()
+	renderRyskaPage = func(context.Context) (string, error) { return ryskaTestHTML, nil }
+
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	extractor := &capturingExtractor{}
+	s := &RyskaScraper{store: st, vision: extractor}
+	if _, err := s.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	want := "GUDSTJÄNSTKUNGÖRELSE för våren\n\nJanuari\n6 Tisdag Liturgi kl. 10:00\n\n" +
+		"Mars\n1 Söndag Liturgi kl. 10:00\n7 Lördag Vesper kl. 17:00\n\nApril\n12 Söndag Påsk kl. 00:00"
+	if extractor.text != want {
+		t.Errorf("Fetch parsed:\n%q\nwant:\n%q", extractor.text, want)
+	}
+	// cmd/extract-text prints this text
+	text, err := ExtractRyskaScheduleText(context.Background())
+	if err != nil {
+		t.Fatalf("ExtractRyskaScheduleText: %v", err)
+	}
+	if text != extractor.text {
+		t.Errorf("ExtractRyskaScheduleText = %q, want the text Fetch parsed, %q", text, extractor.text)
+	}
+}
@@ -1,21 +1,168 @@
 package scraper
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/html/charset"
 
 	"ortodoxa-gudstjanster/internal/model"
 )
 
-var httpClient = &http.Client{Timeout: 30 * time.Second}
+// defaultFetchTimeout bounds each scraper request unless SCRAPER_TIMEOUT is set.
+const defaultFetchTimeout = 30 * time.Second
+
+// httpClient is shared by all scrapers.
+var httpClient = &http.Client{Timeout: fetchTimeout()}
+
+// defaultMaxResponseBytes caps the (decompressed) size of a scraped response
+// unless SCRAPER_MAX_RESPONSE_BYTES is set. Schedule pages and PDFs are far
+// smaller; a larger body means a broken or hostile upstream.
+const defaultMaxResponseBytes = 10 << 20
 
 const browserUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36"
 
+// fetchTimeout returns the per-request timeout: SCRAPER_TIMEOUT (a Go
+// duration such as "45s") if set and valid, otherwise defaultFetchTimeout.
+func fetchTimeout() time.Duration {
+	if v := os.Getenv("SCRAPER_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		slog.Warn("ignoring invalid SCRAPER_TIMEOUT", "value", v)
+	}
+	return defaultFetchTimeout
+}
+
+// userAgent returns the User-Agent scrapers send: SCRAPER_USER_AGENT if set,
+// otherwise a desktop browser's, since some parish sites block Go's default.
+func userAgent() string {
+	if ua := os.Getenv("SCRAPER_USER_AGENT"); ua != "" {
+		return ua
+	}
+	return browserUserAgent
+}
+
+// maxResponseBytes returns the response size limit:
+// SCRAPER_MAX_RESPONSE_BYTES if set to a positive integer, otherwise
+// defaultMaxResponseBytes.
+func maxResponseBytes() int64 {
+	if v := os.Getenv("SCRAPER_MAX_RESPONSE_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+		slog.Warn("ignoring invalid SCRAPER_MAX_RESPONSE_BYTES", "value", v)
+	}
+	return defaultMaxResponseBytes
+}
+
+// newRequest creates a GET request for url with the scrapers' User-Agent.
+// It asks for a compressed response; read the body with responseBody.
+func newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	return req, nil
+}
+
+// responseBody returns the decompressed body of resp, limited to
+// maxResponseBytes. Since newRequest sets Accept-Encoding itself, the
+// transport leaves decompression to us; servers that ignore the header send
+// the body as is.
+func responseBody(resp *http.Response) (io.ReadCloser, error) {
+	var body io.ReadCloser = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing gzip response: %w", err)
+		}
+		body = r
+	case "deflate":
+		// Deflate should be zlib-wrapped, but some servers send raw deflate
+		br := bufio.NewReader(resp.Body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			r, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("decompressing deflate response: %w", err)
+			}
+			body = r
+		} else {
+			body = flate.NewReader(br)
+		}
+	}
+	limit := maxResponseBytes()
+	return &limitedBody{r: io.LimitReader(body, limit+1), closer: body, limit: limit}, nil
+}
+
+// isZlibHeader reports whether h starts a zlib stream: the deflate method
+// and a header checksum divisible by 31.
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+// checkStatus returns an error for a non-2xx response, including the start of
+// the body, so that an error page is never parsed as a schedule.
+func checkStatus(resp *http.Response, url string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	var snippet []byte
+	if body, err := responseBody(resp); err == nil {
+		snippet, _ = io.ReadAll(io.LimitReader(body, 200))
+		body.Close()
+	}
+	text := strings.Join(strings.Fields(string(snippet)), " ")
+	if text == "" {
+		return fmt.Errorf("unexpected status %d for %s", resp.StatusCode, url)
+	}
+	return fmt.Errorf("unexpected status %d for %s: %s", resp.StatusCode, url, text)
+}
+
+// errResponseTooLarge is returned when a response exceeds maxResponseBytes.
+var errResponseTooLarge = errors.New("response too large")
+
+// limitedBody reads at most limit bytes and fails with errResponseTooLarge,
+// rather than silently truncating, if the body is longer.
+type limitedBody struct {
+	r      io.Reader // limited to limit+1 bytes, to detect overlong bodies
+	closer io.Closer
+	limit  int64
+	read   int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), fmt.Errorf("%w: more than %d bytes", errResponseTooLarge, b.limit)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error { return b.closer.Close() }
+
 // stockholm is the Europe/Stockholm timezone, loaded once at init.
 var stockholm *time.Location
 
@@ -29,11 +176,10 @@ func init() {
 
 // fetchURL fetches the content of a URL and returns the response body as bytes.
 func fetchURL(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := newRequest(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", browserUserAgent)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -41,11 +187,17 @@ func fetchURL(ctx context.Context, url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, url)
+	if err := checkStatus(resp, url); err != nil {
+		return nil, err
+	}
+
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, err
 	}
+	defer body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
@@ -53,13 +205,14 @@ func fetchURL(ctx context.Context, url string) ([]byte, error) {
 	return data, nil
 }
 
-// fetchDocument fetches a URL and parses it as an HTML document.
+// fetchDocument fetches a URL and parses it as an HTML document. The body is
+// decoded to UTF-8 using the charset from the Content-Type header or the
+// page's meta tag.
 func fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := newRequest(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", browserUserAgent)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -67,11 +220,24 @@ func fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, url)
+	if err := checkStatus(resp, url); err != nil {
+		return nil, err
+	}
+
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Some parish pages are Latin-1 or Windows-1252; goquery expects UTF-8.
+	enc, _, _ := charset.DetermineEncoding(page, resp.Header.Get("Content-Type"))
+	doc, err := goquery.NewDocumentFromReader(enc.NewDecoder().Reader(bytes.NewReader(page)))
 	if err != nil {
 		return nil, fmt.Errorf("parsing HTML: %w", err)
 	}
@@ -79,6 +245,20 @@ func fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
 	return doc, nil
 }
 
+// inferYear returns the year for a date given only as month and day. The date
+// is placed in a one-year window around now that reaches pastMonths back;
+// dates before the window belong to next year, dates after it to last year.
+func inferYear(now time.Time, month time.Month, day, pastMonths int) int {
+	year := now.Year()
+	candidate := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	if candidate.Before(now.AddDate(0, -pastMonths, 0)) {
+		year++
+	} else if candidate.After(now.AddDate(0, 12-pastMonths, 0)) {
+		year--
+	}
+	return year
+}
+
 // Scraper defines the interface that all church calendar scrapers must implement.
 type Scraper interface {
 	// Name returns the human-readable name of this scraper's source.
@@ -96,11 +276,20 @@ type ScraperWithNotes interface {
 	FetchNotes() []string
 }
 
+// Describable is an optional interface scrapers can implement to report
+// static metadata about their source, listed at /sources.
+type Describable interface {
+	Metadata() model.SourceMetadata
+}
+
 // NoteCollector is an embeddable struct that implements ScraperWithNotes.
 // Embed it in a scraper struct, call resetNotes() at the top of Fetch,
-// and use note() to record key diagnostic events.
+// and use note() to record key diagnostic events. It also holds the
+// scraper's logger and clock, set by the Registry.
 type NoteCollector struct {
-	notes []string
+	notes  []string
+	logger *slog.Logger
+	now    func() time.Time
 }
 
 func (n *NoteCollector) note(format string, args ...any) {
@@ -112,9 +301,46 @@ func (n *NoteCollector) resetNotes() { n.notes = nil }
 // FetchNotes returns diagnostic notes collected during the last Fetch call.
 func (n *NoteCollector) FetchNotes() []string { return n.notes }
 
+// SetLogger sets the logger the scraper logs progress and failures to.
+func (n *NoteCollector) SetLogger(l *slog.Logger) { n.logger = l }
+
+// log returns the logger set with SetLogger, or slog's default logger.
+func (n *NoteCollector) log() *slog.Logger {
+	if n.logger == nil {
+		return slog.Default()
+	}
+	return n.logger
+}
+
+// SetClock sets the reference time the scraper infers missing years and
+// judges staleness from, which is otherwise the current time.
+func (n *NoteCollector) SetClock(now func() time.Time) { n.now = now }
+
+// clock returns the scraper's reference time: the clock set with SetClock,
+// or the current time.
+func (n *NoteCollector) clock() time.Time {
+	if n.now == nil {
+		return time.Now()
+	}
+	return n.now()
+}
+
+// loggerSetter is implemented by scrapers that embed NoteCollector.
+type loggerSetter interface {
+	SetLogger(*slog.Logger)
+}
+
+// clockSetter is implemented by scrapers that embed NoteCollector.
+type clockSetter interface {
+	SetClock(func() time.Time)
+}
+
 // Registry holds all registered scrapers and coordinates fetching.
 type Registry struct {
 	scrapers []Scraper
+	logger   *slog.Logger
+	now      func() time.Time
+	clocks   []clockSetter // dependencies shared by the scrapers that also take the clock
 }
 
 // NewRegistry creates a new scraper registry.
@@ -122,8 +348,52 @@ func NewRegistry() *Registry {
 	return &Registry{}
 }
 
+// SetLogger sets the logger handed to registered scrapers, now and on later
+// Register calls, tagged with each scraper's name.
+func (r *Registry) SetLogger(l *slog.Logger) {
+	r.logger = l
+	for _, s := range r.scrapers {
+		r.setScraperLogger(s)
+	}
+}
+
+func (r *Registry) setScraperLogger(s Scraper) {
+	if ls, ok := s.(loggerSetter); ok && r.logger != nil {
+		ls.SetLogger(r.logger.With("scraper", s.Name()))
+	}
+}
+
+// SetClock sets the reference time handed to registered scrapers, now and
+// on later Register calls, e.g. to re-run a scrape as of an earlier date.
+func (r *Registry) SetClock(now func() time.Time) {
+	r.now = now
+	for _, s := range r.scrapers {
+		r.setScraperClock(s)
+	}
+	for _, cs := range r.clocks {
+		cs.SetClock(now)
+	}
+}
+
+// shareClock hands the registry's clock to a dependency of its scrapers,
+// such as the vision client whose prompts state today's date.
+func (r *Registry) shareClock(cs clockSetter) {
+	r.clocks = append(r.clocks, cs)
+	if r.now != nil {
+		cs.SetClock(r.now)
+	}
+}
+
+func (r *Registry) setScraperClock(s Scraper) {
+	if cs, ok := s.(clockSetter); ok && r.now != nil {
+		cs.SetClock(r.now)
+	}
+}
+
 // Register adds a scraper to the registry.
 func (r *Registry) Register(s Scraper) {
+	r.setScraperLogger(s)
+	r.setScraperClock(s)
 	r.scrapers = append(r.scrapers, s)
 }
 
@@ -131,3 +401,38 @@ func (r *Registry) Register(s Scraper) {
 func (r *Registry) Scrapers() []Scraper {
 	return r.scrapers
 }
+
+// Sources returns metadata for every registered scraper, in registration
+// order. Scrapers that do not implement Describable report only their name.
+func (r *Registry) Sources() []model.SourceMetadata {
+	sources := make([]model.SourceMetadata, 0, len(r.scrapers))
+	for _, s := range r.scrapers {
+		if d, ok := s.(Describable); ok {
+			sources = append(sources, d.Metadata())
+		} else {
+			sources = append(sources, model.SourceMetadata{Name: s.Name()})
+		}
+	}
+	return sources
+}
+
+// tracerName identifies the spans this package creates.
+const tracerName = "ortodoxa-gudstjanster/internal/scraper"
+
+// Fetch runs s.Fetch inside a "scraper.Fetch" span named after the scraper,
+// recording the number of services or the error. Spans go to the global
+// OpenTelemetry tracer provider, which is a no-op unless one is installed.
+func Fetch(ctx context.Context, s Scraper) ([]model.ChurchService, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "scraper.Fetch",
+		trace.WithAttributes(attribute.String("scraper.name", s.Name())))
+	defer span.End()
+
+	services, err := s.Fetch(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("scraper.services", len(services)))
+	return services, nil
+}
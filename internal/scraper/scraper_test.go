@@ -1,13 +1,27 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/model"
 	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/vision"
@@ -48,27 +62,8 @@ func validateService(t *testing.T, s model.ChurchService, scraperName string) {
 		t.Errorf("Source mismatch: got %q, want %q", s.Source, scraperName)
 	}
 
-	// Date must be valid YYYY-MM-DD format
-	if !dateRegex.MatchString(s.Date) {
-		t.Errorf("Invalid date format: %q", s.Date)
-	}
-
-	// DayOfWeek must not be empty
-	if s.DayOfWeek == "" {
-		t.Error("DayOfWeek is empty")
-	}
-
-	// ServiceName must not be empty
-	if s.ServiceName == "" {
-		t.Error("ServiceName is empty")
-	}
-
-	// If Time is set, it should look like a time
-	if s.Time != nil && *s.Time != "" {
-		timeRegex := regexp.MustCompile(`^\d{1,2}:\d{2}`)
-		if !timeRegex.MatchString(*s.Time) {
-			t.Errorf("Time doesn't look like a time: %q", *s.Time)
-		}
+	for _, err := range model.Validate(s) {
+		t.Error(err)
 	}
 }
 
@@ -185,6 +180,224 @@ func TestRegistry(t *testing.T) {
 	}
 }
 
+// plainScraper is a scraper without metadata.
+type plainScraper struct{}
+
+func (plainScraper) Name() string { return "Plain" }
+
+func (plainScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) { return nil, nil }
+
+// failingScraper is a scraper whose fetch always fails.
+type failingScraper struct{}
+
+func (failingScraper) Name() string { return "Failing" }
+
+func (failingScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
+	return nil, errors.New("site down")
+}
+
+func TestFetchTracesEachScraper(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	registry := NewRegistry()
+	registry.Register(plainScraper{})
+	registry.Register(failingScraper{})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "ingest")
+	for _, s := range registry.Scrapers() {
+		Fetch(ctx, s)
+	}
+	parent.End()
+
+	var names []string
+	status := make(map[string]codes.Code)
+	for _, span := range recorder.Ended() {
+		if span.Name() != "scraper.Fetch" {
+			continue
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %v should be a child of the caller's span", span.Attributes())
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "scraper.name" {
+				names = append(names, attr.Value.AsString())
+				status[attr.Value.AsString()] = span.Status().Code
+			}
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"Plain", "Failing"}) {
+		t.Errorf("scraper spans = %v, want one per scraper", names)
+	}
+	if status["Plain"] == codes.Error || status["Failing"] != codes.Error {
+		t.Errorf("span statuses = %v, want only the failing scraper marked as an error", status)
+	}
+}
+
+func TestRegistrySources(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewFinskaScraper(""))
+	registry.Register(NewGomosScraper(nil, nil))
+	registry.Register(NewHeligaAnnaScraper())
+	registry.Register(NewRyskaScraper(nil, nil))
+	registry.Register(NewHeligeSergijScraper(nil, nil))
+	registry.Register(plainScraper{})
+
+	want := map[string]string{
+		finskaSourceName:       finskaDefaultURL,
+		gomosSourceName:        gomosScheduleURL,
+		heligaAnnaSourceName:   heligaAnnaURL,
+		ryskaSourceName:        ryskaURL,
+		heligeSergijSourceName: heligeSergijURL,
+	}
+
+	sources := registry.Sources()
+	if len(sources) != 6 {
+		t.Fatalf("got %d sources, want 6", len(sources))
+	}
+	for _, src := range sources {
+		url, known := want[src.Name]
+		if !known {
+			if src.SourceURL != "" {
+				t.Errorf("%s: non-describable scraper should report only its name, got %+v", src.Name, src)
+			}
+			continue
+		}
+		delete(want, src.Name)
+		if src.SourceURL != url {
+			t.Errorf("%s: SourceURL = %q, want %q", src.Name, src.SourceURL, url)
+		}
+		if src.Location == "" || len(src.Languages) == 0 {
+			t.Errorf("%s: missing location or languages: %+v", src.Name, src)
+		}
+	}
+	for name := range want {
+		t.Errorf("source %s missing", name)
+	}
+}
+
+func TestRegistrySetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewRegistry()
+	finska := NewFinskaScraper("")
+	registry.Register(finska) // before SetLogger
+	registry.Register(plainScraper{})
+	registry.SetLogger(logging.New(&buf, slog.LevelWarn))
+	ryska := NewRyskaScraper(nil, nil)
+	registry.Register(ryska) // after SetLogger
+
+	finska.log().Info("below the level")
+	finska.log().Warn("fetching page", "attempt", 2)
+	ryska.log().Warn("caching schedule")
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("decoding log output: %v", err)
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %v", len(records), records)
+	}
+	if r := records[0]; r["level"] != "WARN" || r["scraper"] != finskaSourceName || r["attempt"] != float64(2) {
+		t.Errorf("first record = %v, want a warning tagged with %s", r, finskaSourceName)
+	}
+	if r := records[1]; r["level"] != "WARN" || r["scraper"] != ryskaSourceName {
+		t.Errorf("second record = %v, want a warning tagged with %s", r, ryskaSourceName)
+	}
+}
+
+func TestRegistrySetClock(t *testing.T) {
+	fixed := time.Date(2026, time.December, 10, 12, 0, 0, 0, stockholm)
+	registry := NewRegistry()
+	heligaAnna := NewHeligaAnnaScraper()
+	registry.Register(heligaAnna) // before SetClock
+	registry.Register(plainScraper{})
+	registry.SetClock(func() time.Time { return fixed })
+	gomos := NewGomosScraper(nil, nil)
+	registry.Register(gomos) // after SetClock
+
+	if got := heligaAnna.clock(); !got.Equal(fixed) {
+		t.Errorf("HeligaAnna clock = %v, want %v", got, fixed)
+	}
+	if got := gomos.clock(); !got.Equal(fixed) {
+		t.Errorf("Gomos clock = %v, want %v", got, fixed)
+	}
+	if got := NewRyskaScraper(nil, nil).clock(); time.Since(got) > time.Minute {
+		t.Errorf("default clock = %v, want the current time", got)
+	}
+}
+
+// roundTripFunc stubs an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRegistrySetClockReachesVision(t *testing.T) {
+	var body []byte
+	orig := http.DefaultTransport
+	defer func() { http.DefaultTransport = orig }()
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ = io.ReadAll(r.Body)
+		return nil, errors.New("no network in tests")
+	})
+
+	v := vision.NewClient("test-key")
+	registry := NewDefaultRegistry(Dependencies{Vision: v})
+	registry.SetClock(func() time.Time { return time.Date(2026, time.December, 10, 12, 0, 0, 0, stockholm) })
+
+	// Gomos has its OCR result translated with this prompt
+	v.TranslateScheduleEntries(context.Background(), []vision.RawScheduleEntry{{Date: "2027-01-06", ServiceName: "Liturgi"}})
+	if !bytes.Contains(body, []byte("Today is December 10, 2026")) {
+		t.Errorf("prompt does not use the registry clock:\n%.300s", body)
+	}
+}
+
+func TestScraperMetadata(t *testing.T) {
+	scrapers := NewDefaultRegistry(Dependencies{
+		UploadBucket: "uploads-bucket",
+		Definitions: []ParishDefinition{
+			{Name: "Test", Type: DefinitionICS, URL: "https://example.com/cal.ics", Language: "Svenska, Engelska"},
+			{Name: "Test PDF", Parish: "Test", Type: DefinitionPDF, URL: "https://example.com/schema.pdf"},
+		},
+	}).Scrapers()
+	if len(scrapers) != 13 {
+		t.Errorf("default registry has %d scrapers, want 10 built-in, uploads and 2 definitions", len(scrapers))
+	}
+	if pdf, ok := scrapers[len(scrapers)-1].(*PDFScheduleScraper); !ok || pdf.vision != nil || pdf.parish != "Test" {
+		t.Errorf("pdf definition registered %#v, want a PDFScheduleScraper for parish Test without a vision client", scrapers[len(scrapers)-1])
+	}
+
+	for _, s := range scrapers {
+		t.Run(s.Name(), func(t *testing.T) {
+			d, ok := s.(Describable)
+			if !ok {
+				t.Fatalf("%T does not implement Describable", s)
+			}
+			meta := d.Metadata()
+			if meta.Name != s.Name() {
+				t.Errorf("Metadata().Name = %q, want %q", meta.Name, s.Name())
+			}
+			if s.Name() == uploadsSourceName {
+				if meta.SourceURL != "" {
+					t.Errorf("SourceURL = %q, want empty for uploads", meta.SourceURL)
+				}
+				return
+			}
+			u, err := url.Parse(meta.SourceURL)
+			if err != nil || !u.IsAbs() || u.Host == "" {
+				t.Errorf("SourceURL %q is not a valid absolute URL", meta.SourceURL)
+			}
+		})
+	}
+}
+
 func assertHasCurrentMonthEvents(t *testing.T, services []model.ChurchService) {
 	t.Helper()
 	currentMonth := time.Now().Format("2006-01")
@@ -5,7 +5,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
@@ -40,6 +39,14 @@ func (s *SommarlagerScraper) Name() string {
 	return sommarlagerSourceName
 }
 
+func (s *SommarlagerScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{
+		Name:      sommarlagerSourceName,
+		Languages: []string{"Svenska"},
+		SourceURL: sommarlagerURL,
+	}
+}
+
 func (s *SommarlagerScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 
@@ -56,17 +63,17 @@ func (s *SommarlagerScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 	regText := ""
 	regURL := findRegistrationLink(mainDoc)
 	if regURL != "" {
-		log.Printf("sommarlager: found registration link: %s", regURL)
+		s.log().Info("found registration link", "url", regURL)
 		s.note("registration link found: %s", regURL)
 		text, err := fetchPageText(ctx, regURL)
 		if err != nil {
-			log.Printf("sommarlager: failed to fetch registration page: %v", err)
+			s.log().Warn("fetching registration page", "err", err)
 			s.note("registration page fetch failed: %v", err)
 		} else {
 			regText = text
 		}
 	} else {
-		log.Printf("sommarlager: no registration link found on main page")
+		s.log().Info("no registration link found on main page")
 		s.note("no registration link found on main page")
 	}
 
@@ -82,9 +89,9 @@ func (s *SommarlagerScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 
 	var events []vision.CampEvent
 	notice := extractSommarlagerNotice(combined)
-	log.Printf("sommarlager: notice=%q", notice)
+	s.log().Debug("extracted notice", "notice", notice)
 	if s.store.GetJSON(cacheKey, &events) {
-		log.Printf("sommarlager: using cached result (%d events)", len(events))
+		s.log().Debug("using cached result", "events", len(events))
 		s.note("cache hit: %d events", len(events))
 		return s.eventsToServices(events, notice), nil
 	}
@@ -97,10 +104,10 @@ func (s *SommarlagerScraper) Fetch(ctx context.Context) ([]model.ChurchService,
 
 	// Cache result
 	if err := s.store.SetJSON(cacheKey, events); err != nil {
-		log.Printf("sommarlager: failed to cache result: %v", err)
+		s.log().Warn("caching result", "err", err)
 	}
 
-	log.Printf("sommarlager: extracted %d events", len(events))
+	s.log().Info("extracted events", "events", len(events))
 	s.note("AI extraction: %d events", len(events))
 	return s.eventsToServices(events, notice), nil
 }
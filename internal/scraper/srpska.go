@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"ortodoxa-gudstjanster/internal/schedule"
+	"ortodoxa-gudstjanster/internal/srpska"
+)
+
+// renderSrpskaPage and srpskaScheduleURL are replaceable in tests.
+var (
+	renderSrpskaPage  = srpska.FetchPageContent
+	srpskaScheduleURL = srpska.CalendarURL
+)
+
+// FetchSrpskaSchedule fetches and parses Sankt Sava's recurring schedule. The
+// calendar page is rendered in headless Chrome; if that fails, e.g. because no
+// Chrome binary is available, the page is fetched over plain HTTP and the
+// schedule is read from its table if it is in the static HTML.
+func FetchSrpskaSchedule(ctx context.Context) (*srpska.RecurringSchedule, error) {
+	page, err := renderSrpskaPage(ctx)
+	if err == nil {
+		return srpska.ParseScheduleTable(page.TableText)
+	}
+	slog.Warn("srpska: rendering the calendar failed, falling back to plain HTTP", "err", err)
+
+	result, httpErr := fetchSrpskaScheduleHTTP(ctx, srpskaScheduleURL)
+	if httpErr != nil {
+		return nil, fmt.Errorf("fetching schedule with Chrome: %v; with plain HTTP: %w", err, httpErr)
+	}
+	return result, nil
+}
+
+func fetchSrpskaScheduleHTTP(ctx context.Context, url string) (*srpska.RecurringSchedule, error) {
+	page, err := fetchURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	tableText, err := schedule.TableText(bytes.NewReader(page), "table")
+	if err != nil {
+		return nil, err
+	}
+	return srpska.ParseScheduleTable(tableText)
+}
@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ortodoxa-gudstjanster/internal/srpska"
+)
+
+func TestFetchSrpskaScheduleFallsBackToStaticTable(t *testing.T) {
+	const page = `<html><body><table>
+<tr><td>Распоред богослужења</td></tr>
+<tr><td>Јутрење - недеља:</td><td>8:00</td></tr>
+<tr><td>Вечерње - субота:</td><td>17:00</td></tr>
+</table></body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	origRender, origURL := renderSrpskaPage, srpskaScheduleURL
+	defer func() { renderSrpskaPage, srpskaScheduleURL = origRender, origURL }()
+	renderSrpskaPage = func(context.Context) (*srpska.PageContent, error) {
+		return nil, errors.New("exec: \"google-chrome\": executable file not found in $PATH")
+	}
+	srpskaScheduleURL = srv.URL
+
+	schedule, err := FetchSrpskaSchedule(context.Background())
+	if err != nil {
+		t.Fatalf("FetchSrpskaSchedule: %v", err)
+	}
+	want := []srpska.RecurringService{
+		{Name: "Morgongudstjänst", Days: []string{"söndag"}, Time: "08:00"},
+		{Name: "Aftongudstjänst", Days: []string{"lördag"}, Time: "17:00"},
+	}
+	if !reflect.DeepEqual(schedule.Services, want) {
+		t.Errorf("services = %+v, want %+v", schedule.Services, want)
+	}
+
+	// With neither path working, both errors are reported
+	srv.Config.Handler = http.NotFoundHandler()
+	_, err = FetchSrpskaSchedule(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "google-chrome") || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "page not found") {
+		t.Errorf("err = %v, want both the Chrome and HTTP failures", err)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 
 	"ortodoxa-gudstjanster/internal/model"
@@ -55,10 +54,16 @@ func (s *UploadsScraper) Name() string {
 	return uploadsSourceName
 }
 
+// Metadata leaves SourceURL empty: the uploads bucket is not a page visitors
+// can browse, and each service links its own source image.
+func (s *UploadsScraper) Metadata() model.SourceMetadata {
+	return model.SourceMetadata{Name: uploadsSourceName}
+}
+
 func (s *UploadsScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	if s.reader == nil {
-		log.Printf("Uploads scraper: no bucket reader configured, returning empty")
+		s.log().Info("no bucket reader configured, returning empty")
 		return nil, nil
 	}
 
@@ -80,20 +85,20 @@ func (s *UploadsScraper) Fetch(ctx context.Context) ([]model.ChurchService, erro
 		// Extract parish slug from first path component (e.g. "helige-giorgis/maj-2026.jpg" → "helige-giorgis")
 		slug, _, ok := strings.Cut(name, "/")
 		if !ok {
-			log.Printf("Uploads: skipping %s (not in a parish folder)", name)
+			s.log().Info("skipping object outside a parish folder", "object", name)
 			continue
 		}
 
 		parish, known := s.parishInfo[slug]
 		if !known {
-			log.Printf("Uploads: skipping %s (unknown parish slug %q)", name, slug)
+			s.log().Warn("skipping object with unknown parish slug", "object", name, "slug", slug)
 			s.note("skipped %s — unknown parish slug %q", name, slug)
 			continue
 		}
 
 		imageData, err := s.reader.ReadObject(ctx, name)
 		if err != nil {
-			log.Printf("Uploads: failed to read %s: %v", name, err)
+			s.log().Warn("reading upload", "object", name, "err", err)
 			s.note("failed to read %s: %v", name, err)
 			failedImages++
 			continue
@@ -101,7 +106,7 @@ func (s *UploadsScraper) Fetch(ctx context.Context) ([]model.ChurchService, erro
 
 		services, err := s.processImage(ctx, imageData, name, &parish)
 		if err != nil {
-			log.Printf("Uploads: failed to process %s: %v", name, err)
+			s.log().Warn("processing upload", "object", name, "err", err)
 			s.note("failed to process %s: %v", name, err)
 			failedImages++
 			continue
@@ -116,7 +121,7 @@ func (s *UploadsScraper) Fetch(ctx context.Context) ([]model.ChurchService, erro
 	} else {
 		s.note("processed %d images → %d services", imageCount, len(allServices))
 	}
-	log.Printf("Uploads: extracted %d services from %d images", len(allServices), imageCount)
+	s.log().Info("extracted services", "services", len(allServices), "images", imageCount)
 	return allServices, nil
 }
 
@@ -128,11 +133,11 @@ func (s *UploadsScraper) processImage(ctx context.Context, imageData []byte, obj
 
 	var cached vision.ImageEventResult
 	if s.store.GetJSON(cacheKey, &cached) {
-		log.Printf("Uploads: cache hit for %s (checksum %s)", objectName, checksum[:12])
+		s.log().Debug("cache hit", "object", objectName, "checksum", checksum[:12])
 		return s.convertToServices(&cached, objectName, parish), nil
 	}
 
-	log.Printf("Uploads: cache miss for %s (checksum %s), calling API", objectName, checksum[:12])
+	s.log().Info("cache miss, calling API", "object", objectName, "checksum", checksum[:12])
 
 	result, rawResponse, err := s.vision.ExtractEventsFromImage(ctx, imageData)
 	if err != nil {
@@ -141,17 +146,17 @@ func (s *UploadsScraper) processImage(ctx context.Context, imageData []byte, obj
 
 	// Persist raw API response for diagnostics
 	if werr := s.store.SetRaw(cacheKey+".response.txt", []byte(rawResponse)); werr != nil {
-		log.Printf("Uploads: failed to persist response: %v", werr)
+		s.log().Warn("persisting API response", "err", werr)
 	}
 
 	// Cache the result
 	if data, merr := json.Marshal(result); merr == nil {
 		if werr := s.store.SetRaw(cacheKey+".json", data); werr != nil {
-			log.Printf("Uploads: failed to cache result: %v", werr)
+			s.log().Warn("caching result", "err", werr)
 		}
 	}
 
-	log.Printf("Uploads: extracted %d events from %s (parish: %s)", len(result.Events), objectName, parish.Name)
+	s.log().Info("extracted events", "events", len(result.Events), "object", objectName, "parish", parish.Name)
 	return s.convertToServices(result, objectName, parish), nil
 }
 
@@ -36,6 +36,15 @@ func (s *UppstandelseScraper) Name() string {
 	return uppstandelseSourceName
 }
 
+func (s *UppstandelseScraper) Metadata() model.SourceMetadata {
+	// Services alternate between the churches in uppstandelseLocationMapping
+	return model.SourceMetadata{
+		Name:      uppstandelseSourceName,
+		Languages: []string{uppstandelseParishLang},
+		SourceURL: uppstandelseSourcePage,
+	}
+}
+
 func (s *UppstandelseScraper) Fetch(ctx context.Context) ([]model.ChurchService, error) {
 	s.resetNotes()
 	data, err := fetchURL(ctx, uppstandelseURL)
@@ -2,34 +2,49 @@ package srpska
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/chromedp/chromedp"
+
+	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/schedule"
 )
 
 const (
 	CalendarURL = "https://www.crkvastokholm.se/calendar"
+
+	// Metadata attached to services converted with ToServices.
+	SourceName = "Sankt Sava"
+	Location   = "Bägerstavägen 68, 120 47 Enskede"
+	Language   = "Serbiska"
+
+	// DefaultWeeks is how far ahead events are generated unless SRPSKA_WEEKS
+	// says otherwise.
+	DefaultWeeks = 26
 )
 
+// now is the clock GenerateEvents starts from, replaceable in tests.
+var now = time.Now
+
 // RecurringSchedule represents the structured schedule output
-type RecurringSchedule struct {
-	Services []RecurringService `json:"services"`
-}
+type RecurringSchedule = schedule.Schedule
 
 // RecurringService represents a single recurring service
-type RecurringService struct {
-	Name string   `json:"name"`
-	Days []string `json:"days"`
-	Time string   `json:"time"`
-}
+type RecurringService = schedule.Service
 
 // PageContent holds the extracted text from the calendar page.
 type PageContent struct {
-	TableText string // The recurring schedule table text
+	TableText string // The recurring schedule tables' text
 	BodyText  string // The full page body text (includes notices)
 }
 
@@ -45,9 +60,54 @@ type ExceptionService struct {
 	Time string `json:"time"` // HH:MM
 }
 
+var (
+	// ErrChromeNotFound means no Chrome binary could be started.
+	ErrChromeNotFound = errors.New("chrome not found (set CHROME_PATH)")
+	// ErrTableNotFound means the page loaded but its schedule table never
+	// got any text.
+	ErrTableNotFound = errors.New("schedule table never appeared")
+)
+
+// tableTimeout is how long to wait for the schedule table to be rendered.
+const tableTimeout = 20 * time.Second
+
+// renderPage renders the calendar page once; replaceable in tests.
+var renderPage = renderPageWithChrome
+
 // FetchPageContent fetches the calendar page and extracts both the recurring
 // schedule table text and the full page body text (which may contain notices).
+// A failed render is retried once, unless Chrome is missing. Errors wrap
+// ErrChromeNotFound or ErrTableNotFound where they apply.
 func FetchPageContent(ctx context.Context) (*PageContent, error) {
+	var err error
+	for attempt := 1; attempt <= 2; attempt++ {
+		var page *PageContent
+		page, err = renderPage(ctx)
+		if err == nil {
+			return page, nil
+		}
+		err = classifyRenderError(err)
+		if errors.Is(err, ErrChromeNotFound) || ctx.Err() != nil {
+			break
+		}
+		slog.Warn("srpska: rendering attempt failed", "attempt", attempt, "err", err)
+	}
+	return nil, err
+}
+
+// classifyRenderError wraps a chromedp error in ErrChromeNotFound or
+// ErrTableNotFound when it is one of those.
+func classifyRenderError(err error) error {
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %v", ErrChromeNotFound, err)
+	case errors.Is(err, chromedp.ErrPollingTimeout):
+		return fmt.Errorf("%w after %s: %v", ErrTableNotFound, tableTimeout, err)
+	}
+	return fmt.Errorf("extracting schedule table: %w", err)
+}
+
+func renderPageWithChrome(ctx context.Context) (*PageContent, error) {
 	// Create headless Chrome context
 	opts := chromedp.DefaultExecAllocatorOptions[:]
 	if chromePath := os.Getenv("CHROME_PATH"); chromePath != "" {
@@ -68,20 +128,21 @@ func FetchPageContent(ctx context.Context) (*PageContent, error) {
 	var tableText string
 	var bodyText string
 
-	// Navigate to the calendar page and extract the schedule table
+	// Navigate to the calendar page and extract the schedule tables
 	err := chromedp.Run(chromeCtx,
 		chromedp.Navigate(CalendarURL),
-		// Wait for the schedule table to be rendered
-		chromedp.WaitVisible(`table`, chromedp.ByQuery),
-		// Give React a moment to fully render
-		chromedp.Sleep(1*time.Second),
-		// Extract the table text content
-		chromedp.Text(`table`, &tableText, chromedp.ByQuery),
+		// Wait until React has rendered text into a schedule table
+		chromedp.Poll(`Array.from(document.querySelectorAll("table")).some(t => t.innerText.trim() !== "")`, nil,
+			chromedp.WithPollingInterval(250*time.Millisecond),
+			chromedp.WithPollingTimeout(tableTimeout)),
+		// Extract the text of every table; feast services may be in their own
+		chromedp.Evaluate(fmt.Sprintf(`Array.from(document.querySelectorAll("table"), t => t.innerText).join(%q)`,
+			schedule.TableSeparator), &tableText),
 		// Extract the full page body text (includes notices)
 		chromedp.Text(`body`, &bodyText, chromedp.ByQuery),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("extracting schedule table: %w", err)
+		return nil, err
 	}
 
 	return &PageContent{
@@ -90,62 +151,26 @@ func FetchPageContent(ctx context.Context) (*PageContent, error) {
 	}, nil
 }
 
+// tableFormat is the layout of the calendar page's schedule table, e.g.
+// "Јутрење - недеља:	8:00". A flattened row may hold several entries, such
+// as a morning and an evening service.
+var tableFormat = schedule.Format{
+	Layout:        "Name - days: HH:MM",
+	Entry:         regexp.MustCompile(`(?P<name>\S.*?)\s*[-–]\s*(?P<days>.+?):\s*(?P<hour>\d{1,2}):(?P<minute>\d{2})`),
+	ParseDays:     parseDays,
+	TranslateName: TranslateServiceName,
+	NormalizeName: normalizeServiceName,
+}
+
 // Part 2: Parse raw table text into structured schedule
 func ParseScheduleTable(text string) (*RecurringSchedule, error) {
-	schedule := &RecurringSchedule{
-		Services: []RecurringService{},
-	}
-
-	// Split into lines and process
-	lines := strings.Split(text, "\n")
-
-	// Pattern to match service entries like "Јутрење - недеља:	8:00"
-	// Format: "ServiceName - days:	HH:MM" (tab-separated)
-	servicePattern := regexp.MustCompile(`^(.+?)\s*[-–]\s*(.+?):\s*(\d{1,2}):(\d{2})`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Handle tab-separated format: join with space
-		line = strings.ReplaceAll(line, "\t", " ")
-
-		matches := servicePattern.FindStringSubmatch(line)
-		if len(matches) >= 5 {
-			name := strings.TrimSpace(matches[1])
-			daysStr := strings.TrimSpace(matches[2])
-			hour := matches[3]
-			minute := matches[4]
-
-			if len(hour) == 1 {
-				hour = "0" + hour
-			}
-			timeStr := hour + ":" + minute
-
-			// Translate Serbian service name to Swedish
-			swedishName := translateServiceName(name)
-			days := parseDays(daysStr)
-
-			if swedishName != "" && len(days) > 0 {
-				schedule.Services = append(schedule.Services, RecurringService{
-					Name: swedishName,
-					Days: days,
-					Time: timeStr,
-				})
-			}
-		}
-	}
-
-	if len(schedule.Services) == 0 {
-		return nil, fmt.Errorf("could not parse any services from table text: %q", text)
-	}
-
-	return schedule, nil
+	return schedule.Parse(text, tableFormat)
 }
 
-func translateServiceName(name string) string {
+// TranslateServiceName returns the Swedish name for a Serbian service name in
+// Cyrillic or Latin script, e.g. "Литургија" or "Liturgija" both become
+// "Helig Liturgi". Unrecognized names are returned unchanged.
+func TranslateServiceName(name string) string {
 	// Serbian (Cyrillic) to Swedish translations
 	translations := map[string]string{
 		"Јутрење":   "Morgongudstjänst",
@@ -157,8 +182,9 @@ func translateServiceName(name string) string {
 		"Večernje":  "Aftongudstjänst",
 	}
 
+	normalized := normalizeServiceName(name)
 	for serbian, swedish := range translations {
-		if strings.Contains(name, serbian) {
+		if strings.Contains(normalized, normalizeServiceName(serbian)) {
 			return swedish
 		}
 	}
@@ -166,6 +192,40 @@ func translateServiceName(name string) string {
 	return name
 }
 
+var foldDiacritics = strings.NewReplacer(
+	"č", "c", "ć", "c", "š", "s", "ž", "z", "đ", "dj",
+	"å", "a", "ä", "a", "ö", "o",
+)
+
+// foldDayName lowercases s and strips diacritics, including combining marks
+// left by decomposed input, so "Četvrtak", "Cetvrtak" and "C\u030cetvrtak"
+// all fold to "cetvrtak".
+func foldDayName(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			sb.WriteRune(r)
+		}
+	}
+	return foldDiacritics.Replace(sb.String())
+}
+
+// normalizeServiceName lowercases a service name, strips diacritics and
+// collapses doubled letters, so that spelling variants and typos in the
+// source ("Hellig liturgi", "Vecernje") compare equal to the canonical name.
+func normalizeServiceName(name string) string {
+	folded := foldDiacritics.Replace(strings.ToLower(strings.Join(strings.Fields(name), " ")))
+	var sb strings.Builder
+	var prev rune
+	for _, r := range folded {
+		if r != prev {
+			sb.WriteRune(r)
+		}
+		prev = r
+	}
+	return sb.String()
+}
+
 // CalendarEvent represents a single calendar event
 type CalendarEvent struct {
 	Date        string `json:"date"`
@@ -186,30 +246,21 @@ func GenerateEvents(schedule *RecurringSchedule, weeks int, exceptions []Schedul
 		exceptionMap[exc.Date] = exc.Services
 	}
 
-	stockholm, err := time.LoadLocation("Europe/Stockholm")
-	if err != nil {
-		panic(fmt.Sprintf("failed to load Europe/Stockholm timezone: %v", err))
-	}
-	now := time.Now().In(stockholm)
-	// Start from today
-	current := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, stockholm)
-	// Generate for specified weeks
-	end := current.AddDate(0, 0, weeks*7)
-
-	// Build a map of weekday name to time.Weekday
-	weekdayMap := map[string]time.Weekday{
-		"måndag":  time.Monday,
-		"tisdag":  time.Tuesday,
-		"onsdag":  time.Wednesday,
-		"torsdag": time.Thursday,
-		"fredag":  time.Friday,
-		"lördag":  time.Saturday,
-		"söndag":  time.Sunday,
+	start, end, stockholm := generationWindow(weeks)
+
+	// Expand the recurring schedule, grouped by date so exceptions can replace whole days
+	recurringByDate := make(map[string][]CalendarEvent)
+	for _, svc := range model.ExpandRecurring(recurringSpecs(schedule), start, end, stockholm) {
+		recurringByDate[svc.Date] = append(recurringByDate[svc.Date], CalendarEvent{
+			Date:        svc.Date,
+			DayOfWeek:   svc.DayOfWeek,
+			ServiceName: svc.ServiceName,
+			Time:        *svc.Time,
+		})
 	}
 
-	for current.Before(end) {
+	for current := start; current.Before(end); current = current.AddDate(0, 0, 1) {
 		dateStr := current.Format("2006-01-02")
-		currentWeekday := current.Weekday()
 
 		// Check if this date has an exception override
 		if excServices, hasException := exceptionMap[dateStr]; hasException {
@@ -217,69 +268,170 @@ func GenerateEvents(schedule *RecurringSchedule, weeks int, exceptions []Schedul
 			for _, excSvc := range excServices {
 				events = append(events, CalendarEvent{
 					Date:        dateStr,
-					DayOfWeek:   WeekdayToSwedish(currentWeekday),
+					DayOfWeek:   WeekdayToSwedish(current.Weekday()),
 					ServiceName: excSvc.Name,
 					Time:        excSvc.Time,
 				})
 			}
-			current = current.AddDate(0, 0, 1)
 			continue
 		}
 
-		for _, svc := range schedule.Services {
-			// Check if this service runs on the current weekday
-			shouldInclude := false
-			for _, day := range svc.Days {
-				if day == "helgdag" {
-					// Skip holidays for now - we don't have a holiday calendar
-					continue
-				}
-				if wd, ok := weekdayMap[day]; ok && wd == currentWeekday {
-					shouldInclude = true
-					break
-				}
-			}
+		events = append(events, recurringByDate[dateStr]...)
+	}
 
-			if shouldInclude {
-				events = append(events, CalendarEvent{
-					Date:        dateStr,
-					DayOfWeek:   WeekdayToSwedish(currentWeekday),
-					ServiceName: svc.Name,
-					Time:        svc.Time,
-				})
+	return events
+}
+
+// WeeksFromEnv returns the generation horizon in weeks from the SRPSKA_WEEKS
+// environment variable, or DefaultWeeks if it is unset.
+func WeeksFromEnv() (int, error) {
+	v := strings.TrimSpace(os.Getenv("SRPSKA_WEEKS"))
+	if v == "" {
+		return DefaultWeeks, nil
+	}
+	weeks, err := strconv.Atoi(v)
+	if err != nil || weeks <= 0 {
+		return 0, fmt.Errorf("invalid SRPSKA_WEEKS %q: want a positive number of weeks", v)
+	}
+	return weeks, nil
+}
+
+// generationWindow returns the days [start, end) that weeks of events cover,
+// starting today in Stockholm.
+func generationWindow(weeks int) (start, end time.Time, stockholm *time.Location) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		panic(fmt.Sprintf("failed to load Europe/Stockholm timezone: %v", err))
+	}
+	today := now().In(stockholm)
+	start = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, stockholm)
+	return start, start.AddDate(0, 0, weeks*7), stockholm
+}
+
+// RecurringServices is like ToServices(GenerateEvents(...)) but folds each
+// recurring service into a single service with a model.RecurrenceRule, so
+// calendar feeds carry one event per series. Exception dates become the
+// rule's ExDates, and the exception services are returned as one-off services.
+func RecurringServices(schedule *RecurringSchedule, weeks int, exceptions []ScheduleException) []model.ChurchService {
+	start, end, stockholm := generationWindow(weeks)
+
+	exceptionDates := make(map[string]bool)
+	var oneOff []CalendarEvent
+	for _, exc := range exceptions {
+		d, err := time.ParseInLocation("2006-01-02", exc.Date, stockholm)
+		if err != nil || d.Before(start) || !d.Before(end) {
+			continue
+		}
+		exceptionDates[exc.Date] = true
+		for _, excSvc := range exc.Services {
+			oneOff = append(oneOff, CalendarEvent{
+				Date:        exc.Date,
+				DayOfWeek:   WeekdayToSwedish(d.Weekday()),
+				ServiceName: excSvc.Name,
+				Time:        excSvc.Time,
+			})
+		}
+	}
+
+	var services []model.ChurchService
+	for _, spec := range recurringSpecs(schedule) {
+		occurrences := model.ExpandRecurring([]model.RecurringSpec{spec}, start, end, stockholm)
+		if len(occurrences) == 0 {
+			continue
+		}
+		rule := &model.RecurrenceRule{
+			Weekdays: spec.Weekdays,
+			Until:    occurrences[len(occurrences)-1].Date,
+		}
+		for _, occ := range occurrences {
+			if exceptionDates[occ.Date] {
+				rule.ExDates = append(rule.ExDates, occ.Date)
 			}
 		}
+		if len(rule.ExDates) == len(occurrences) {
+			continue
+		}
+		first := occurrences[0]
+		svc := ToServices([]CalendarEvent{{
+			Date:        first.Date,
+			DayOfWeek:   first.DayOfWeek,
+			ServiceName: first.ServiceName,
+			Time:        *first.Time,
+		}})[0]
+		svc.Recurring = rule
+		services = append(services, svc)
+	}
+
+	return append(services, ToServices(oneOff)...)
+}
 
-		current = current.AddDate(0, 0, 1)
+// ToServices converts generated events to services carrying the Sankt Sava
+// source, location and language.
+func ToServices(events []CalendarEvent) []model.ChurchService {
+	services := make([]model.ChurchService, 0, len(events))
+	for _, ev := range events {
+		location := Location
+		language := Language
+		t := ev.Time
+		services = append(services, model.ChurchService{
+			Source:      SourceName,
+			SourceURL:   CalendarURL,
+			Date:        ev.Date,
+			DayOfWeek:   ev.DayOfWeek,
+			ServiceName: ev.ServiceName,
+			Location:    &location,
+			Time:        &t,
+			Language:    &language,
+		})
 	}
+	return services
+}
 
-	return events
+// weekdayMap maps the Swedish day names produced by parseDays to time.Weekday.
+var weekdayMap = map[string]time.Weekday{
+	"måndag":  time.Monday,
+	"tisdag":  time.Tuesday,
+	"onsdag":  time.Wednesday,
+	"torsdag": time.Thursday,
+	"fredag":  time.Friday,
+	"lördag":  time.Saturday,
+	"söndag":  time.Sunday,
 }
 
-func WeekdayToSwedish(day time.Weekday) string {
-	switch day {
-	case time.Monday:
-		return "Måndag"
-	case time.Tuesday:
-		return "Tisdag"
-	case time.Wednesday:
-		return "Onsdag"
-	case time.Thursday:
-		return "Torsdag"
-	case time.Friday:
-		return "Fredag"
-	case time.Saturday:
-		return "Lördag"
-	case time.Sunday:
-		return "Söndag"
-	default:
-		return ""
+// recurringSpecs converts the parsed schedule to model.RecurringSpec values.
+// "helgdag" is skipped for now - we don't have a holiday calendar.
+func recurringSpecs(schedule *RecurringSchedule) []model.RecurringSpec {
+	specs := make([]model.RecurringSpec, 0, len(schedule.Services))
+	for _, svc := range schedule.Services {
+		var weekdays []time.Weekday
+		for _, day := range svc.Days {
+			if wd, ok := weekdayMap[day]; ok {
+				weekdays = append(weekdays, wd)
+			}
+		}
+		specs = append(specs, model.RecurringSpec{
+			ServiceName: svc.Name,
+			Weekdays:    weekdays,
+			Time:        svc.Time,
+		})
 	}
+	return specs
+}
+
+func WeekdayToSwedish(day time.Weekday) string {
+	return model.SwedishWeekday(day)
 }
 
 func parseDays(s string) []string {
 	var days []string
 
+	// Daily services: Serbian Cyrillic "сваки дан", Latin "svaki dan"
+	lower := strings.ToLower(s)
+	if strings.Contains(lower, "сваки дан") || strings.Contains(lower, "svaki dan") ||
+		strings.Contains(lower, "dagligen") || strings.Contains(lower, "daily") {
+		return []string{"måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag", "söndag"}
+	}
+
 	// Check for "working days" patterns in various languages
 	// Serbian Cyrillic: "радни дани", Latin: "radni dani"
 	if strings.Contains(s, "радни дани") || strings.Contains(strings.ToLower(s), "radni dan") ||
@@ -287,25 +439,37 @@ func parseDays(s string) []string {
 		return []string{"måndag", "tisdag", "onsdag", "torsdag", "fredag"}
 	}
 
-	// Map of day names (Serbian Cyrillic, Serbian Latin, Swedish) to Swedish
+	// Map of day names (Serbian Cyrillic, Serbian Latin, Swedish) to Swedish.
+	// Ekavian and ijekavian forms are both listed; patterns and input are
+	// compared after foldDayName, so diacritic-free spellings match too.
 	dayMappings := []struct {
 		patterns []string
 		swedish  string
 	}{
-		{[]string{"понедељак", "ponedeljak", "måndag"}, "måndag"},
+		{[]string{"понедељак", "ponedeljak", "ponedjeljak", "måndag"}, "måndag"},
 		{[]string{"уторак", "utorak", "tisdag"}, "tisdag"},
-		{[]string{"среда", "sreda", "onsdag"}, "onsdag"},
+		{[]string{"среда", "сриједа", "sreda", "srijeda", "onsdag"}, "onsdag"},
 		{[]string{"четвртак", "četvrtak", "torsdag"}, "torsdag"},
 		{[]string{"петак", "petak", "fredag"}, "fredag"},
 		{[]string{"субота", "subota", "lördag"}, "lördag"},
-		{[]string{"недеља", "nedelja", "söndag"}, "söndag"},
+		{[]string{"недеља", "недјеља", "nedelja", "nedjelja", "söndag"}, "söndag"},
 		{[]string{"празник", "praznik", "helgdag"}, "helgdag"},
 	}
 
-	lowerS := strings.ToLower(s)
+	// Match whole words by prefix: "ponedeljak" contains "nedelja"
+	words := strings.FieldsFunc(foldDayName(s), func(r rune) bool { return !unicode.IsLetter(r) })
+	hasWord := func(pattern string) bool {
+		for _, w := range words {
+			if strings.HasPrefix(w, pattern) {
+				return true
+			}
+		}
+		return false
+	}
+
 	for _, mapping := range dayMappings {
 		for _, pattern := range mapping.patterns {
-			if strings.Contains(s, pattern) || strings.Contains(lowerS, strings.ToLower(pattern)) {
+			if hasWord(foldDayName(pattern)) {
 				// Avoid duplicates
 				found := false
 				for _, d := range days {
@@ -1,11 +1,21 @@
 package srpska
 
 import (
+	"context"
+	"errors"
+	"io/fs"
+	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"ortodoxa-gudstjanster/internal/schedule"
 )
 
-// --- translateServiceName ---
+// --- TranslateServiceName ---
 
 func TestTranslateServiceName(t *testing.T) {
 	tests := []struct {
@@ -23,9 +33,30 @@ func TestTranslateServiceName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got := translateServiceName(tt.input)
+			got := TranslateServiceName(tt.input)
 			if got != tt.want {
-				t.Errorf("translateServiceName(%q) = %q, want %q", tt.input, got, tt.want)
+				t.Errorf("TranslateServiceName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateServiceNameConsistentAcrossEntryPoints(t *testing.T) {
+	names := []string{"Јутрење", "Литургија", "Вечерње", "Jutrenje", "Liturgija", "Vecernje"}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			want := TranslateServiceName(name)
+			if want == name {
+				t.Fatalf("TranslateServiceName(%q) left the name untranslated", name)
+			}
+
+			table, err := ParseScheduleTable(name + " - недеља: 10:00")
+			if err != nil {
+				t.Fatalf("ParseScheduleTable: %v", err)
+			}
+			if got := table.Services[0].Name; got != want {
+				t.Errorf("schedule table gives %q, want %q", got, want)
 			}
 		})
 	}
@@ -96,6 +127,32 @@ func TestParseDays(t *testing.T) {
 	}
 }
 
+func TestParseDaysSpellingVariants(t *testing.T) {
+	tests := []struct {
+		want     string
+		spelling []string
+	}{
+		{"måndag", []string{"понедељак", "ponedeljak", "ponedjeljak", "Ponedeljak"}},
+		{"tisdag", []string{"уторак", "utorak", "Utorak"}},
+		{"onsdag", []string{"среда", "сриједа", "sreda", "srijeda", "Srijeda"}},
+		{"torsdag", []string{"четвртак", "četvrtak", "Četvrtak", "cetvrtak", "Cetvrtak", "C\u030cetvrtak"}},
+		{"fredag", []string{"петак", "petak", "Petak"}},
+		{"lördag", []string{"субота", "subota", "Subota", "Lordag"}},
+		{"söndag", []string{"недеља", "недјеља", "nedelja", "nedjelja", "Nedjelja", "sondag"}},
+	}
+
+	for _, tt := range tests {
+		for _, spelling := range tt.spelling {
+			t.Run(spelling, func(t *testing.T) {
+				got := parseDays(spelling)
+				if len(got) != 1 || got[0] != tt.want {
+					t.Errorf("parseDays(%q) = %v, want [%s]", spelling, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
 // --- WeekdayToSwedish ---
 
 func TestWeekdayToSwedish(t *testing.T) {
@@ -160,6 +217,25 @@ func TestParseScheduleTable(t *testing.T) {
 	}
 }
 
+func TestParseScheduleTableMultipleTables(t *testing.T) {
+	regular := "Јутрење - недеља:\t8:00\nЛитургија - недеља:\t9:30"
+	feasts := "Вечерње - субота:\t17:00\nЛитургија - недеља:\t9:30"
+
+	result, err := ParseScheduleTable(regular + schedule.TableSeparator + feasts)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+
+	var got []string
+	for _, svc := range result.Services {
+		got = append(got, svc.Name+" "+svc.Time)
+	}
+	want := []string{"Morgongudstjänst 08:00", "Helig Liturgi 09:30", "Aftongudstjänst 17:00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("services = %v, want the union of both tables %v", got, want)
+	}
+}
+
 func TestParseScheduleTableEmpty(t *testing.T) {
 	_, err := ParseScheduleTable("")
 	if err == nil {
@@ -167,6 +243,157 @@ func TestParseScheduleTableEmpty(t *testing.T) {
 	}
 }
 
+func TestParseScheduleTableDetailedError(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"headers only", "Распоред богослужења\nНедеља", "2 lines, 0 with a time, 0 in"},
+		{"times in another layout", "Недеља 8:00 Јутрење\nСубота 17:00 Вечерње", "2 lines, 2 with a time, 0 in"},
+		{"unknown days", "Јутрење - sometimes:\t8:00", "1 lines, 1 with a time, 1 in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScheduleTable(tt.input)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleTableSkipsHeadersAndFootnotes(t *testing.T) {
+	input := "Распоред богослужења\n" +
+		"\n" +
+		"Недеља\n" +
+		"Јутрење - недеља:\t8:00\n" +
+		"Литургија - недеља, празник:\t9:30\n" +
+		"Субота\n" +
+		"Вечерње - субота:\t17:00\n" +
+		"* За велике празнике распоред се објављује посебно\n" +
+		"* Литургија - празник: 10:00 у манастиру\n"
+
+	schedule, err := ParseScheduleTable(input)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+
+	want := []string{"Morgongudstjänst 08:00", "Helig Liturgi 09:30", "Aftongudstjänst 17:00"}
+	if len(schedule.Services) != len(want) {
+		t.Fatalf("got %d services, want %d: %+v", len(schedule.Services), len(want), schedule.Services)
+	}
+	for i, svc := range schedule.Services {
+		if got := svc.Name + " " + svc.Time; got != want[i] {
+			t.Errorf("service[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestParseScheduleTableOnlyHeaders(t *testing.T) {
+	_, err := ParseScheduleTable("Распоред богослужења\nНедеља\n")
+	if err == nil {
+		t.Error("expected error when the table has no services")
+	}
+}
+
+func TestParseScheduleTableDedupesSpellingVariants(t *testing.T) {
+	input := "Литургија - недеља:\t9:30\n" +
+		"Hellig liturgi - nedelja:\t9:30\n" +
+		"Liturgija - недеља:\t9:30\n" +
+		"Vecernje - субота:\t17:00\n" +
+		"Вечерње - субота:\t17:00\n"
+
+	schedule, err := ParseScheduleTable(input)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+
+	if len(schedule.Services) != 2 {
+		t.Fatalf("got %d services, want 2: %+v", len(schedule.Services), schedule.Services)
+	}
+	if got := schedule.Services[0]; got.Name != "Helig Liturgi" || got.Time != "09:30" {
+		t.Errorf("service[0] = %+v, want Helig Liturgi 09:30", got)
+	}
+	if got := schedule.Services[1]; got.Name != "Aftongudstjänst" || got.Time != "17:00" {
+		t.Errorf("service[1] = %+v, want Aftongudstjänst 17:00", got)
+	}
+}
+
+func TestParseScheduleTableKeepsDifferentTimes(t *testing.T) {
+	input := "Литургија - недеља:\t8:00\n" +
+		"Литургија - недеља:\t10:00\n"
+
+	schedule, err := ParseScheduleTable(input)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+	if len(schedule.Services) != 2 {
+		t.Errorf("got %d services, want 2: %+v", len(schedule.Services), schedule.Services)
+	}
+}
+
+func TestParseScheduleTableMultipleServicesPerLine(t *testing.T) {
+	// A flattened row with a morning and an evening service
+	input := "Јутрење - субота:\t8:00\tВечерње - субота:\t17:00\n" +
+		"Литургија - недеља:\t9:30\n"
+
+	schedule, err := ParseScheduleTable(input)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+
+	want := []RecurringService{
+		{Name: "Morgongudstjänst", Days: []string{"lördag"}, Time: "08:00"},
+		{Name: "Aftongudstjänst", Days: []string{"lördag"}, Time: "17:00"},
+		{Name: "Helig Liturgi", Days: []string{"söndag"}, Time: "09:30"},
+	}
+	if len(schedule.Services) != len(want) {
+		t.Fatalf("got %d services, want %d: %+v", len(schedule.Services), len(want), schedule.Services)
+	}
+	for i, w := range want {
+		got := schedule.Services[i]
+		if got.Name != w.Name || got.Time != w.Time || strings.Join(got.Days, ",") != strings.Join(w.Days, ",") {
+			t.Errorf("service %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestParseScheduleTableRejectsServiceWithoutDays(t *testing.T) {
+	input := "Јутрење - недеља:\t8:00\n" +
+		"Литургија - по договору:\t9:30\n"
+
+	schedule, err := ParseScheduleTable(input)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+	if len(schedule.Services) != 1 || schedule.Services[0].Name != "Morgongudstjänst" {
+		t.Errorf("services = %+v, want only Morgongudstjänst", schedule.Services)
+	}
+}
+
+func TestParseScheduleTableDaily(t *testing.T) {
+	schedule, err := ParseScheduleTable("Вечерње - сваки дан:\t18:00\n")
+	if err != nil {
+		t.Fatalf("ParseScheduleTable failed: %v", err)
+	}
+	if len(schedule.Services) != 1 {
+		t.Fatalf("got %d services, want 1", len(schedule.Services))
+	}
+	if days := schedule.Services[0].Days; len(days) != 7 {
+		t.Errorf("days = %v, want all seven", days)
+	}
+
+	events := GenerateEvents(schedule, 1, nil)
+	if len(events) != 7 {
+		t.Errorf("got %d events for one week, want 7", len(events))
+	}
+}
+
 func TestParseScheduleTableWorkingDays(t *testing.T) {
 	input := "Јутрење - радни дани:\t6:00\n"
 
@@ -214,6 +441,48 @@ func TestGenerateEvents(t *testing.T) {
 	}
 }
 
+func TestGenerateEventsHorizon(t *testing.T) {
+	// A Wednesday, so every week of the window holds exactly one Sunday
+	now = func() time.Time { return time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	schedule := &RecurringSchedule{
+		Services: []RecurringService{
+			{Name: "Helig Liturgi", Days: []string{"söndag"}, Time: "09:30"},
+		},
+	}
+
+	for _, weeks := range []int{1, 8, DefaultWeeks} {
+		if got := len(GenerateEvents(schedule, weeks, nil)); got != weeks {
+			t.Errorf("GenerateEvents(%d weeks) produced %d Sundays, want %d", weeks, got, weeks)
+		}
+	}
+}
+
+func TestWeeksFromEnv(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    int
+		wantErr bool
+	}{
+		{"", DefaultWeeks, false},
+		{"12", 12, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("SRPSKA_WEEKS", tt.env)
+			got, err := WeeksFromEnv()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("WeeksFromEnv() = %d, %v; want %d, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGenerateEventsMultipleServices(t *testing.T) {
 	schedule := &RecurringSchedule{
 		Services: []RecurringService{
@@ -306,6 +575,46 @@ func TestGenerateEventsWithExceptions(t *testing.T) {
 	}
 }
 
+func TestRecurringServices(t *testing.T) {
+	// Wednesday 2026-03-04; four weeks run through 2026-03-31
+	now = func() time.Time { return time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	schedule := &RecurringSchedule{
+		Services: []RecurringService{
+			{Name: "Helig Liturgi", Days: []string{"söndag"}, Time: "09:30"},
+		},
+	}
+	exceptions := []ScheduleException{
+		{Date: "2026-03-15", Services: []ExceptionService{{Name: "Helig Liturgi", Time: "10:00"}}},
+		{Date: "2026-05-01"}, // outside the window
+	}
+
+	services := RecurringServices(schedule, 4, exceptions)
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want the series plus one exception: %+v", len(services), services)
+	}
+
+	series := services[0]
+	if series.Date != "2026-03-08" || *series.Time != "09:30" || series.Source != SourceName {
+		t.Errorf("series = %s %s from %s, want 2026-03-08 09:30 from %s", series.Date, *series.Time, series.Source, SourceName)
+	}
+	rule := series.Recurring
+	if rule == nil {
+		t.Fatal("series has no recurrence rule")
+	}
+	if len(rule.Weekdays) != 1 || rule.Weekdays[0] != time.Sunday || rule.Until != "2026-03-29" {
+		t.Errorf("rule = %+v, want Sundays until 2026-03-29", rule)
+	}
+	if len(rule.ExDates) != 1 || rule.ExDates[0] != "2026-03-15" {
+		t.Errorf("ExDates = %v, want [2026-03-15]", rule.ExDates)
+	}
+
+	if exc := services[1]; exc.Recurring != nil || exc.Date != "2026-03-15" || *exc.Time != "10:00" {
+		t.Errorf("exception = %+v, want a one-off on 2026-03-15 at 10:00", exc)
+	}
+}
+
 func TestGenerateEventsWithEmptyException(t *testing.T) {
 	schedule := &RecurringSchedule{
 		Services: []RecurringService{
@@ -339,3 +648,123 @@ func TestGenerateEventsWithEmptyException(t *testing.T) {
 		}
 	}
 }
+
+// --- Pipeline: ParseScheduleTable → GenerateEvents ---
+
+func TestPipelineCyrillicTableToEvents(t *testing.T) {
+	// Wednesday 2026-03-25; two weeks covers up to and including Tuesday 2026-04-07.
+	// DST starts on 2026-03-29, which must not shift wall-clock times.
+	now = func() time.Time { return time.Date(2026, time.March, 25, 10, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	table := "Јутрење - недеља:\t8:00\n" +
+		"Литургија - недеља, празник:\t9:30\n" +
+		"Вечерње - субота:\t17:00\n" +
+		"Јутрење - радни дани:\t7:00\n"
+
+	schedule, err := ParseScheduleTable(table)
+	if err != nil {
+		t.Fatalf("ParseScheduleTable: %v", err)
+	}
+	events := GenerateEvents(schedule, 2, nil)
+
+	want := []string{
+		"2026-03-25 Onsdag 07:00 Morgongudstjänst",
+		"2026-03-26 Torsdag 07:00 Morgongudstjänst",
+		"2026-03-27 Fredag 07:00 Morgongudstjänst",
+		"2026-03-28 Lördag 17:00 Aftongudstjänst",
+		"2026-03-29 Söndag 08:00 Morgongudstjänst",
+		"2026-03-29 Söndag 09:30 Helig Liturgi",
+		"2026-03-30 Måndag 07:00 Morgongudstjänst",
+		"2026-03-31 Tisdag 07:00 Morgongudstjänst",
+		"2026-04-01 Onsdag 07:00 Morgongudstjänst",
+		"2026-04-02 Torsdag 07:00 Morgongudstjänst",
+		"2026-04-03 Fredag 07:00 Morgongudstjänst",
+		"2026-04-04 Lördag 17:00 Aftongudstjänst",
+		"2026-04-05 Söndag 08:00 Morgongudstjänst",
+		"2026-04-05 Söndag 09:30 Helig Liturgi",
+		"2026-04-06 Måndag 07:00 Morgongudstjänst",
+		"2026-04-07 Tisdag 07:00 Morgongudstjänst",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		got := e.Date + " " + e.DayOfWeek + " " + e.Time + " " + e.ServiceName
+		if got != want[i] {
+			t.Errorf("events[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// --- FetchPageContent ---
+
+func TestClassifyRenderError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"not on PATH", &exec.Error{Name: "google-chrome", Err: exec.ErrNotFound}, ErrChromeNotFound},
+		{"bad CHROME_PATH", &fs.PathError{Op: "fork/exec", Path: "/nowhere/chrome", Err: fs.ErrNotExist}, ErrChromeNotFound},
+		{"table timeout", chromedp.ErrPollingTimeout, ErrTableNotFound},
+		{"other", errors.New("net::ERR_NAME_NOT_RESOLVED"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyRenderError(tt.err)
+			for _, sentinel := range []error{ErrChromeNotFound, ErrTableNotFound} {
+				if errors.Is(got, sentinel) != (sentinel == tt.want) {
+					t.Errorf("classifyRenderError(%v) = %v; errors.Is(%v) = %v", tt.err, got, sentinel, !(sentinel == tt.want))
+				}
+			}
+			if !strings.Contains(got.Error(), tt.err.Error()) {
+				t.Errorf("error %q should include the cause %q", got, tt.err)
+			}
+		})
+	}
+}
+
+func TestFetchPageContentRetries(t *testing.T) {
+	orig := renderPage
+	defer func() { renderPage = orig }()
+
+	tests := []struct {
+		name      string
+		errs      []error // returned by successive renders; nil renders the page
+		wantCalls int
+		wantErr   error
+	}{
+		{"first try", []error{nil}, 1, nil},
+		{"retried after a slow render", []error{chromedp.ErrPollingTimeout, nil}, 2, nil},
+		{"table never appears", []error{chromedp.ErrPollingTimeout, chromedp.ErrPollingTimeout}, 2, ErrTableNotFound},
+		{"no retry without Chrome", []error{&exec.Error{Name: "chrome", Err: exec.ErrNotFound}}, 1, ErrChromeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			renderPage = func(context.Context) (*PageContent, error) {
+				err := tt.errs[calls]
+				calls++
+				if err != nil {
+					return nil, err
+				}
+				return &PageContent{TableText: "Јутрење - недеља:\t8:00"}, nil
+			}
+
+			page, err := FetchPageContent(context.Background())
+			if calls != tt.wantCalls {
+				t.Errorf("rendered %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == nil {
+				if err != nil || page == nil {
+					t.Errorf("FetchPageContent = %v, %v; want the page", page, err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
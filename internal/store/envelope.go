@@ -0,0 +1,38 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// now is the clock used for envelope timestamps, replaceable in tests.
+var now = time.Now
+
+// envelope wraps a JSON value stored by SetJSONStamped with its write time.
+type envelope struct {
+	StoredAt *time.Time      `json:"stored_at"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// marshalStamped encodes v inside an envelope stamped with the current time.
+func marshalStamped(v interface{}) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	storedAt := now().UTC()
+	return json.Marshal(envelope{StoredAt: &storedAt, Payload: payload})
+}
+
+// unmarshalFresh decodes an envelope into v if it was stored within maxAge.
+// Values written without an envelope count as stale.
+func unmarshalFresh(data []byte, maxAge time.Duration, v interface{}) bool {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.StoredAt == nil || env.Payload == nil {
+		return false
+	}
+	if now().Sub(*env.StoredAt) > maxAge {
+		return false
+	}
+	return json.Unmarshal(env.Payload, v) == nil
+}
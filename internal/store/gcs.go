@@ -3,11 +3,14 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
 )
 
 // GCSStore is a Cloud Storage-backed implementation of Store.
@@ -89,6 +92,25 @@ func (s *GCSStore) SetJSON(key string, v interface{}) error {
 	return s.Set(key, data)
 }
 
+// SetJSONStamped stores a value as JSON along with the current time.
+func (s *GCSStore) SetJSONStamped(key string, v interface{}) error {
+	data, err := marshalStamped(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, data)
+}
+
+// GetJSONFresh retrieves a value stored with SetJSONStamped if it is at
+// most maxAge old.
+func (s *GCSStore) GetJSONFresh(key string, maxAge time.Duration, v interface{}) bool {
+	data, ok := s.Get(key)
+	if !ok {
+		return false
+	}
+	return unmarshalFresh(data, maxAge, v)
+}
+
 // SetWithExtension stores raw bytes with a custom file extension.
 func (s *GCSStore) SetWithExtension(key string, ext string, value []byte) error {
 	s.mu.Lock()
@@ -106,6 +128,8 @@ func (s *GCSStore) SetWithExtension(key string, ext string, value []byte) error
 		writer.ContentType = "image/jpeg"
 	case ".png":
 		writer.ContentType = "image/png"
+	case ".txt", ".response.txt":
+		writer.ContentType = "text/plain; charset=utf-8"
 	default:
 		writer.ContentType = "application/octet-stream"
 	}
@@ -136,6 +160,48 @@ func (s *GCSStore) SetRaw(path string, data []byte) error {
 	return writer.Close()
 }
 
+// Delete removes a key.
+func (s *GCSStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	bucket := s.client.Bucket(s.bucket)
+	err := bucket.Object(s.keyPath(key)).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		err = bucket.Object(key).Delete(ctx)
+	}
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List returns the keys under a prefix in lexical order.
+func (s *GCSStore) List(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	it := s.client.Bucket(s.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	var keys []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, strings.TrimSuffix(attrs.Name, ".json"))
+	}
+	return keys, nil
+}
+
 // Close closes the GCS client.
 func (s *GCSStore) Close() error {
 	return s.client.Close()
@@ -0,0 +1,427 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Store is an implementation of Store backed by an S3-compatible object
+// store (AWS S3, MinIO, ...). Requests use path-style addressing and are
+// signed with AWS Signature Version 4.
+type S3Store struct {
+	httpClient *http.Client
+	endpoint   string // scheme://host, without trailing slash
+	bucket     string
+	region     string
+	accessKey  string
+	secretKey  string
+	mu         sync.RWMutex
+}
+
+// NewS3 creates a new S3Store for the specified bucket. endpoint and region
+// are optional and default to AWS S3 in us-east-1; set endpoint for MinIO
+// and other S3-compatible services. Credentials are read from the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+func NewS3(bucket, endpoint, region string) (*S3Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return &S3Store{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		bucket:     bucket,
+		region:     region,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+	}, nil
+}
+
+// Get retrieves a value by key. Returns the value and true if found,
+// or nil and false if not found.
+func (s *S3Store) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp, err := s.do(http.MethodGet, s.keyPath(key), nil, nil, "")
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores a value with the given key.
+func (s *S3Store) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(s.keyPath(key), value, "application/json")
+}
+
+// GetJSON retrieves and unmarshals a JSON value.
+func (s *S3Store) GetJSON(key string, v interface{}) bool {
+	data, ok := s.Get(key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// SetJSON marshals and stores a value as JSON.
+func (s *S3Store) SetJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, data)
+}
+
+// SetJSONStamped stores a value as JSON along with the current time.
+func (s *S3Store) SetJSONStamped(key string, v interface{}) error {
+	data, err := marshalStamped(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, data)
+}
+
+// GetJSONFresh retrieves a value stored with SetJSONStamped if it is at
+// most maxAge old.
+func (s *S3Store) GetJSONFresh(key string, maxAge time.Duration, v interface{}) bool {
+	data, ok := s.Get(key)
+	if !ok {
+		return false
+	}
+	return unmarshalFresh(data, maxAge, v)
+}
+
+// SetWithExtension stores raw bytes with a custom file extension.
+func (s *S3Store) SetWithExtension(key string, ext string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Set content type based on extension
+	var contentType string
+	switch ext {
+	case ".jpg", ".jpeg":
+		contentType = "image/jpeg"
+	case ".png":
+		contentType = "image/png"
+	case ".txt", ".response.txt":
+		contentType = "text/plain; charset=utf-8"
+	default:
+		contentType = "application/octet-stream"
+	}
+
+	return s.put(key+ext, value, contentType)
+}
+
+// SetRaw writes raw bytes to the given path (used as-is, no extension appended).
+func (s *S3Store) SetRaw(path string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(path, data, "application/json")
+}
+
+func (s *S3Store) keyPath(key string) string {
+	return key + ".json"
+}
+
+func (s *S3Store) put(object string, body []byte, contentType string) error {
+	resp, err := s.do(http.MethodPut, object, nil, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 PUT %s: status %d: %s", object, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Delete removes a key.
+func (s *S3Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// S3 reports success for deleting a missing object, so check which of
+	// the two forms exists before deleting.
+	object := s.keyPath(key)
+	exists, err := s.exists(object)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		object = key
+	}
+
+	resp, err := s.do(http.MethodDelete, object, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("S3 DELETE %s: status %d", object, resp.StatusCode)
+	}
+	return nil
+}
+
+// List returns the keys under a prefix in lexical order.
+func (s *S3Store) List(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("S3 list %s: status %d", prefix, resp.StatusCode)
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing S3 list response: %w", err)
+		}
+
+		for _, obj := range result.Contents {
+			keys = append(keys, strings.TrimSuffix(obj.Key, ".json"))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Store) exists(object string) (bool, error) {
+	resp, err := s.do(http.MethodHead, object, nil, nil, "")
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("S3 HEAD %s: status %d", object, resp.StatusCode)
+	}
+}
+
+// do sends a signed request for an object in the bucket, or for the bucket
+// itself if object is empty.
+func (s *S3Store) do(method, object string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("building S3 URL: %w", err)
+	}
+	// Send the path and query exactly as they are signed
+	u.Path = "/" + s.bucket + "/" + object
+	u.RawPath = canonicalURI(u.Path)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{resp.Body, cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a request context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           req.Header.Get("X-Amz-Date"),
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+	signV4(req, headers, payloadHash, s.accessKey, s.secretKey, s.region, "s3", now)
+}
+
+// signV4 sets the Authorization header of req to an AWS Signature Version 4
+// signature over headers (lower-case names, all of them signed; host and
+// x-amz-date must be included), the method, path, query and payloadHash.
+func signV4(req *http.Request, headers map[string]string, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	signedHeaders := make([]string, 0, len(headers))
+	for h := range headers {
+		signedHeaders = append(signedHeaders, h)
+	}
+	sort.Strings(signedHeaders)
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + strings.Join(strings.Fields(headers[h]), " ") + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// canonicalURI URI-encodes each segment of the decoded path, as SigV4
+// requires. S3 paths are encoded once and not normalized.
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes the query parameters sorted by name, then value.
+// Parameters without a value get an empty one ("lifecycle=").
+func canonicalQuery(query url.Values) string {
+	type param struct{ key, value string }
+	var params []param
+	for k, vs := range query {
+		for _, v := range vs {
+			params = append(params, param{uriEncode(k), uriEncode(v)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].key != params[j].key {
+			return params[i].key < params[j].key
+		}
+		return params[i].value < params[j].value
+	})
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.key + "=" + p.value
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters
+// A-Z, a-z, 0-9, '-', '.', '_' and '~', with upper-case hex digits.
+func uriEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -2,21 +2,37 @@ package store
 
 import (
 	"encoding/json"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Store is the interface for a persistent key-value store.
-// Unlike cache, it has no TTL - data persists indefinitely.
+// Unlike cache, it has no TTL - data persists indefinitely. Callers that want
+// values to expire write them with SetJSONStamped and read them with GetJSONFresh.
 type Store interface {
 	Get(key string) ([]byte, bool)
 	Set(key string, value []byte) error
 	GetJSON(key string, v interface{}) bool
 	SetJSON(key string, v interface{}) error
+	// SetJSONStamped stores v as JSON together with the time it was written.
+	SetJSONStamped(key string, v interface{}) error
+	// GetJSONFresh reads a value written by SetJSONStamped, returning false
+	// if it is missing or older than maxAge.
+	GetJSONFresh(key string, maxAge time.Duration, v interface{}) bool
 	SetWithExtension(key string, ext string, value []byte) error
 	// SetRaw writes raw bytes to the given path (used as-is, no extension appended).
 	SetRaw(path string, data []byte) error
+	// Delete removes a key. Keys returned by List for non-JSON objects (which
+	// keep their extension) are removed as-is. Deleting a missing key is not an error.
+	Delete(key string) error
+	// List returns the keys under a prefix, with the .json suffix stripped so
+	// they can be passed back to Get and Delete.
+	List(prefix string) ([]string, error)
 }
 
 // LocalStore is a file-based implementation of Store.
@@ -77,6 +93,25 @@ func (s *LocalStore) SetJSON(key string, v interface{}) error {
 	return s.Set(key, data)
 }
 
+// SetJSONStamped stores a value as JSON along with the current time.
+func (s *LocalStore) SetJSONStamped(key string, v interface{}) error {
+	data, err := marshalStamped(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, data)
+}
+
+// GetJSONFresh retrieves a value stored with SetJSONStamped if it is at
+// most maxAge old.
+func (s *LocalStore) GetJSONFresh(key string, maxAge time.Duration, v interface{}) bool {
+	data, ok := s.Get(key)
+	if !ok {
+		return false
+	}
+	return unmarshalFresh(data, maxAge, v)
+}
+
 // SetWithExtension stores raw bytes with a custom file extension.
 func (s *LocalStore) SetWithExtension(key string, ext string, value []byte) error {
 	s.mu.Lock()
@@ -101,6 +136,51 @@ func (s *LocalStore) SetRaw(path string, data []byte) error {
 	return os.WriteFile(fullPath, data, 0644)
 }
 
+// Delete removes a key.
+func (s *LocalStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.keyPath(key))
+	if os.IsNotExist(err) {
+		err = os.Remove(filepath.Join(s.dir, key))
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List returns the keys under a prefix in sorted order.
+func (s *LocalStore) List(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			keys = append(keys, strings.TrimSuffix(name, ".json"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
 func (s *LocalStore) keyPath(key string) string {
 	return filepath.Join(s.dir, key+".json")
 }
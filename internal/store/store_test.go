@@ -3,7 +3,9 @@ package store
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func tempStoreDir(t *testing.T) string {
@@ -149,3 +151,112 @@ func TestLocalStoreOverwrite(t *testing.T) {
 		t.Errorf("Get = %q, want %q", string(data), "second")
 	}
 }
+
+func TestLocalStoreListAndDelete(t *testing.T) {
+	s, err := NewLocal(tempStoreDir(t))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	if err := s.Set("ocr/b", []byte("1")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("ocr/a", []byte("2")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.SetWithExtension("ocr/a", ".jpg", []byte("img")); err != nil {
+		t.Fatalf("SetWithExtension: %v", err)
+	}
+	if err := s.Set("other", []byte("3")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	keys, err := s.List("ocr/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []string{"ocr/a", "ocr/a.jpg", "ocr/b"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("List = %v, want %v", keys, want)
+	}
+
+	// Listed keys round-trip through Get and Delete
+	if _, ok := s.Get(keys[0]); !ok {
+		t.Errorf("Get(%q) should find the listed key", keys[0])
+	}
+	for _, key := range keys {
+		if err := s.Delete(key); err != nil {
+			t.Fatalf("Delete(%q): %v", key, err)
+		}
+	}
+
+	keys, err = s.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "other" {
+		t.Errorf("List after delete = %v, want [other]", keys)
+	}
+	if _, ok := s.Get("ocr/a"); ok {
+		t.Error("Get should miss after Delete")
+	}
+}
+
+func TestLocalStoreDeleteMissing(t *testing.T) {
+	s, err := NewLocal(tempStoreDir(t))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	if err := s.Delete("nonexistent"); err != nil {
+		t.Errorf("Delete of missing key should not fail: %v", err)
+	}
+}
+
+func TestLocalStoreGetJSONFresh(t *testing.T) {
+	s, err := NewLocal(tempStoreDir(t))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	clock := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	if err := s.SetJSONStamped("ocr", []string{"Liturgi"}); err != nil {
+		t.Fatalf("SetJSONStamped: %v", err)
+	}
+
+	var got []string
+	clock = clock.Add(23 * time.Hour)
+	if !s.GetJSONFresh("ocr", 24*time.Hour, &got) {
+		t.Fatal("GetJSONFresh should return a value within maxAge")
+	}
+	if len(got) != 1 || got[0] != "Liturgi" {
+		t.Errorf("GetJSONFresh = %v, want [Liturgi]", got)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	if s.GetJSONFresh("ocr", 24*time.Hour, &got) {
+		t.Error("GetJSONFresh should return false for a stale value")
+	}
+}
+
+func TestLocalStoreGetJSONFreshLegacyValue(t *testing.T) {
+	s, err := NewLocal(tempStoreDir(t))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	// Values written by SetJSON have no timestamp and count as stale
+	if err := s.SetJSON("ocr", []string{"Liturgi"}); err != nil {
+		t.Fatalf("SetJSON: %v", err)
+	}
+	var got []string
+	if s.GetJSONFresh("ocr", time.Hour, &got) {
+		t.Error("GetJSONFresh should return false for a value without envelope")
+	}
+	if !s.GetJSON("ocr", &got) {
+		t.Error("GetJSON should still read values written by SetJSON")
+	}
+}
@@ -0,0 +1,250 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter sends spans to an OTLP/HTTP collector as JSON-encoded
+// ExportTraceServiceRequests. The JSON mapping is small enough to implement
+// here rather than adding the otlptracehttp exporter and the generated OTLP
+// protobuf module it depends on.
+type Exporter struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewExporter returns an exporter posting to url, the collector's traces
+// endpoint (usually ending in /v1/traces), with the given extra headers.
+func NewExporter(url string, headers http.Header) *Exporter {
+	return &Exporter{url: url, headers: headers, client: &http.Client{Timeout: exportTimeout}}
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *Exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return fmt.Errorf("encoding spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for key, values := range e.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("exporting spans: status %d: %s", resp.StatusCode, strings.Join(strings.Fields(string(msg)), " "))
+	}
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// The types below are the JSON mapping of the OTLP trace protobufs. 64-bit
+// integers are strings and IDs are hex, as the mapping requires.
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   otlpResource `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []keyValue  `json:"attributes,omitempty"`
+	Events            []otlpEvent `json:"events,omitempty"`
+	Status            otlpStatus  `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"` // 0 unset, 1 ok, 2 error
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue `json:"arrayValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+// encodeSpans groups spans by resource and instrumentation scope, keeping
+// the order they first appear in.
+func encodeSpans(spans []sdktrace.ReadOnlySpan) exportRequest {
+	var req exportRequest
+	resourceIndex := make(map[attribute.Distinct]int)
+	scopeIndex := make(map[attribute.Distinct]map[instrumentation.Scope]int)
+
+	for _, span := range spans {
+		res := span.Resource()
+		key := res.Equivalent()
+		ri, ok := resourceIndex[key]
+		if !ok {
+			ri = len(req.ResourceSpans)
+			resourceIndex[key] = ri
+			scopeIndex[key] = make(map[instrumentation.Scope]int)
+			req.ResourceSpans = append(req.ResourceSpans, resourceSpans{
+				Resource: otlpResource{Attributes: encodeAttributes(res.Attributes())},
+			})
+		}
+
+		scope := span.InstrumentationScope()
+		scope.Attributes = attribute.Set{} // not comparable across spans
+		si, ok := scopeIndex[key][scope]
+		if !ok {
+			si = len(req.ResourceSpans[ri].ScopeSpans)
+			scopeIndex[key][scope] = si
+			req.ResourceSpans[ri].ScopeSpans = append(req.ResourceSpans[ri].ScopeSpans, scopeSpans{
+				Scope: otlpScope{Name: scope.Name, Version: scope.Version},
+			})
+		}
+		ss := &req.ResourceSpans[ri].ScopeSpans[si]
+		ss.Spans = append(ss.Spans, encodeSpan(span))
+	}
+	return req
+}
+
+func encodeSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	sc := span.SpanContext()
+	out := otlpSpan{
+		TraceID:           sc.TraceID().String(),
+		SpanID:            sc.SpanID().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()), // the SDK's values match OTLP's
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+		Attributes:        encodeAttributes(span.Attributes()),
+	}
+	if parent := span.Parent(); parent.HasSpanID() {
+		out.ParentSpanID = parent.SpanID().String()
+	}
+	for _, ev := range span.Events() {
+		out.Events = append(out.Events, otlpEvent{
+			TimeUnixNano: strconv.FormatInt(ev.Time.UnixNano(), 10),
+			Name:         ev.Name,
+			Attributes:   encodeAttributes(ev.Attributes),
+		})
+	}
+	switch status := span.Status(); status.Code {
+	case codes.Ok:
+		out.Status = otlpStatus{Code: 1}
+	case codes.Error:
+		out.Status = otlpStatus{Code: 2, Message: status.Description}
+	}
+	return out
+}
+
+func encodeAttributes(attrs []attribute.KeyValue) []keyValue {
+	var out []keyValue
+	for _, kv := range attrs {
+		out = append(out, keyValue{Key: string(kv.Key), Value: encodeValue(kv.Value)})
+	}
+	return out
+}
+
+func encodeValue(v attribute.Value) anyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return anyValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return anyValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return anyValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var values []anyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, encodeValue(attribute.BoolValue(b)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		var values []anyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, encodeValue(attribute.Int64Value(i)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []anyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, encodeValue(attribute.Float64Value(f)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []anyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, encodeValue(attribute.StringValue(s)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	}
+	s := v.Emit()
+	return anyValue{StringValue: &s}
+}
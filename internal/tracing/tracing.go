@@ -0,0 +1,74 @@
+// Package tracing installs an OpenTelemetry tracer provider for the commands,
+// exporting spans over OTLP/HTTP with JSON encoding.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Setup installs a global tracer provider that batches spans to the OTLP
+// collector at OTEL_EXPORTER_OTLP_ENDPOINT (e.g. "http://localhost:4318"),
+// sending the comma-separated key=value pairs in OTEL_EXPORTER_OTLP_HEADERS
+// with each export. Spans are attributed to OTEL_SERVICE_NAME, or
+// serviceName if that is unset.
+//
+// Without an endpoint nothing is installed and spans stay no-ops. The
+// returned function flushes pending spans; call it before exiting.
+func Setup(serviceName string) (shutdown func(context.Context) error, err error) {
+	endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	headers, err := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+	if name := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); name != "" {
+		serviceName = name
+	}
+
+	exporter := NewExporter(strings.TrimSuffix(endpoint, "/")+"/v1/traces", headers)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// parseHeaders parses OTEL_EXPORTER_OTLP_HEADERS: comma-separated key=value
+// pairs with percent-encoded values, as in "authorization=Bearer%20abc,x-team=web".
+func parseHeaders(s string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", strings.TrimSpace(key), err)
+		}
+		headers.Set(strings.TrimSpace(key), decoded)
+	}
+	return headers, nil
+}
+
+// exportTimeout bounds each export request.
+const exportTimeout = 10 * time.Second
@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupExportsSpans(t *testing.T) {
+	var got exportRequest
+	var gotPath, gotType, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType, gotAuth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decoding export: %v\n%s", err, body)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL+"/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer abc")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	shutdown, err := Setup("ingest")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	tracer := otel.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "ingest")
+	_, child := tracer.Start(ctx, "scrape Finska", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("scraper.name", "Finska"), attribute.Int("services", 12)))
+	child.RecordError(errors.New("boom"))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if gotPath != "/v1/traces" || gotType != "application/json" || gotAuth != "Bearer abc" {
+		t.Errorf("request: path %q, Content-Type %q, Authorization %q", gotPath, gotType, gotAuth)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("export = %+v, want one resource and scope", got)
+	}
+	res := got.ResourceSpans[0].Resource.Attributes
+	if len(res) != 1 || res[0].Key != "service.name" || *res[0].Value.StringValue != "ingest" {
+		t.Errorf("resource attributes = %+v, want service.name=ingest", res)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	c, p := spans[0], spans[1]
+	if c.Name != "scrape Finska" || c.Kind != 3 || c.ParentSpanID != p.SpanID || c.TraceID != p.TraceID || len(c.TraceID) != 32 {
+		t.Errorf("child span = %+v, parent = %+v", c, p)
+	}
+	if c.Status.Code != 2 || c.Status.Message != "boom" || len(c.Events) != 1 || c.Events[0].Name != "exception" {
+		t.Errorf("child status = %+v, events = %+v, want an error and an exception event", c.Status, c.Events)
+	}
+	if len(c.Attributes) != 2 || *c.Attributes[1].Value.IntValue != "12" {
+		t.Errorf("child attributes = %+v", c.Attributes)
+	}
+	if p.ParentSpanID != "" || p.Status.Code != 0 {
+		t.Errorf("parent span = %+v, want a root with unset status", p)
+	}
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	before := otel.GetTracerProvider()
+	shutdown, err := Setup("ingest")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Error("Setup installed a tracer provider without an endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	h, err := parseHeaders("authorization=Bearer a=b, x-team = web,")
+	if err != nil {
+		t.Fatalf("parseHeaders: %v", err)
+	}
+	if h.Get("Authorization") != "Bearer a=b" || h.Get("X-Team") != "web" || len(h) != 2 {
+		t.Errorf("headers = %v", h)
+	}
+	if _, err := parseHeaders("novalue"); err == nil {
+		t.Error("want an error for a pair without =")
+	}
+
+	h, err = parseHeaders("Authorization=Bearer%20abc%2Cdef")
+	if err != nil {
+		t.Fatalf("parseHeaders: %v", err)
+	}
+	if got := h.Get("Authorization"); got != "Bearer abc,def" {
+		t.Errorf("Authorization = %q, want percent-decoded value", got)
+	}
+	if _, err := parseHeaders("authorization=Bearer%zz"); err == nil {
+		t.Error("want an error for a malformed escape")
+	}
+}
@@ -0,0 +1,59 @@
+package vision
+
+import (
+	"sort"
+	"sync"
+)
+
+// Extraction results counted by RecordExtract.
+const (
+	ExtractSuccess = "success"
+	ExtractError   = "error"
+	ExtractEmpty   = "empty" // the call succeeded but found no entries
+)
+
+// ExtractCount is the number of extractions for one source and result.
+type ExtractCount struct {
+	Source string
+	Result string
+	Count  uint64
+}
+
+var extractCounts = struct {
+	mu     sync.Mutex
+	counts map[[2]string]uint64 // {source, result} → count
+}{counts: make(map[[2]string]uint64)}
+
+// RecordExtract counts the outcome of one schedule extraction for a source.
+// A rising share of empty results for a source usually means its page
+// layout changed.
+func RecordExtract(source string, entries int, err error) {
+	result := ExtractSuccess
+	if err != nil {
+		result = ExtractError
+	} else if entries == 0 {
+		result = ExtractEmpty
+	}
+
+	extractCounts.mu.Lock()
+	defer extractCounts.mu.Unlock()
+	extractCounts.counts[[2]string{source, result}]++
+}
+
+// ExtractCounts returns the extraction counters, ordered by source then result.
+func ExtractCounts() []ExtractCount {
+	extractCounts.mu.Lock()
+	defer extractCounts.mu.Unlock()
+
+	counts := make([]ExtractCount, 0, len(extractCounts.counts))
+	for key, n := range extractCounts.counts {
+		counts = append(counts, ExtractCount{Source: key[0], Result: key[1], Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Source != counts[j].Source {
+			return counts[i].Source < counts[j].Source
+		}
+		return counts[i].Result < counts[j].Result
+	})
+	return counts
+}
@@ -7,10 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const openaiAPIURL = "https://api.openai.com/v1/chat/completions"
@@ -24,6 +30,9 @@ type ScheduleEntry struct {
 	Occasion    string `json:"occasion,omitempty"`
 	Location    string `json:"location,omitempty"`
 	Abroad      bool   `json:"abroad,omitempty"`
+	// Uncertain is set by the model when it had to guess the date or time,
+	// e.g. because the image is blurry or cut off.
+	Uncertain bool `json:"uncertain,omitempty"`
 }
 
 // RawScheduleResult holds the raw OCR output from an image in its original language.
@@ -41,6 +50,9 @@ type RawScheduleEntry struct {
 	Occasion    string `json:"occasion,omitempty"`
 	Location    string `json:"location,omitempty"`
 	Abroad      bool   `json:"abroad,omitempty"`
+	// Uncertain is set by the model when it had to guess the date or time,
+	// e.g. because the image is blurry or cut off.
+	Uncertain bool `json:"uncertain,omitempty"`
 }
 
 // normalizeTime fixes invalid HH:MM values. In particular, 24:00 becomes 23:59.
@@ -51,24 +63,110 @@ func normalizeTime(t string) string {
 	return t
 }
 
+// defaultTimeout bounds each OpenAI request unless WithTimeout is given.
+const defaultTimeout = 60 * time.Second
+
 // Client is an OpenAI Vision API client.
 type Client struct {
 	apiKey     string
+	apiURL     string
 	httpClient *http.Client
+	timeout    time.Duration    // per request, including reading the response
+	now        func() time.Time // reference date for prompts
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets how long a single OpenAI request may take (default 60s).
+// It applies on top of the caller's context, so a hung connection fails
+// even when the caller's own deadline is much later.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
 }
 
 // NewClient creates a new OpenAI Vision client.
-func NewClient(apiKey string) *Client {
-	return &Client{
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 120 * time.Second},
+		apiURL:     openaiAPIURL,
+		httpClient: &http.Client{},
+		timeout:    defaultTimeout,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// SetClock overrides the reference date given to the model in prompts
+// ("Today is ..."), which is otherwise the current time.
+func (c *Client) SetClock(now func() time.Time) {
+	c.now = now
 }
 
-// doRequest executes an OpenAI API request with logging.
+// doRequest executes an OpenAI API request with logging, inside an
+// "openai <caller>" span that is a child of the request context's span.
 func (c *Client) doRequest(req *http.Request, caller string, model string) (*http.Response, error) {
-	log.Printf("OPENAI API CALL: %s (model: %s)", caller, model)
-	return c.httpClient.Do(req)
+	slog.Info("OpenAI API call", "caller", caller, "model", model)
+
+	ctx, span := otel.Tracer("ortodoxa-gudstjanster/internal/vision").Start(req.Context(), "openai "+caller,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("openai.model", model)))
+	defer span.End()
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// post sends reqBody as JSON to the API and returns the response body. The
+// request is bounded by the client's timeout, independent of the caller's
+// deadline; a status other than 200 is an error.
+func (c *Client) post(ctx context.Context, caller string, model string, reqBody interface{}) ([]byte, error) {
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.doRequest(req, caller, model)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
 }
 
 // ExtractScheduleRaw sends an image to OpenAI's vision API and extracts church service
@@ -82,7 +180,7 @@ func (c *Client) ExtractScheduleRaw(ctx context.Context, imageData []byte) (*Raw
 		mediaType = "image/png"
 	}
 
-	currentYear := time.Now().Year()
+	currentYear := c.now().Year()
 	prompt := fmt.Sprintf(`Extract ALL church service schedule entries from this image. The schedule is dense and may contain 30+ entries — be extremely thorough and do not skip any.
 
 STEP 1: First, scan the entire image top to bottom (and left column then right column if multi-column) and identify every date header (e.g., "Κυριακή 1 Μαρτίου", "Torsdag 26 mars"). List them mentally — you must not miss any date section.
@@ -101,6 +199,7 @@ Return a JSON object with these fields:
   - occasion: optional, any special occasion or holiday mentioned, in the ORIGINAL language
   - location: optional. Set ONLY when the text explicitly states the event takes place at a different named venue (e.g. "NOTERA: ... i Aposteln Bartholomaios församling i Reykjavik"). Do NOT set this for phrases like "från [place]" which describe where a visiting person is from, not where the event is held.
   - abroad: optional boolean. Set to true when location is set and that location is clearly outside Sweden.
+  - uncertain: optional boolean. Set to true when you could not read the date or time clearly (blurry, cut off or ambiguous text) and had to guess it.
 
 Only include entries that have both a date/day and a time specified. Note that NOTERING/NOTE entries also have times — the time typically appears right-aligned at the end of the last line of wrapped text (e.g., after a closing parenthesis).
 IMPORTANT: Double-check that you have not skipped any date sections or services. The output should cover the ENTIRE schedule from first date to last date. Count the number of date headers you found and verify none were skipped. Verify that no entry has time 00:00 unless it genuinely says midnight.
@@ -129,32 +228,9 @@ Return ONLY the JSON object, no other text.`, currentYear)
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, "", fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, "", fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ExtractScheduleRaw", "gpt-4.1")
-	if err != nil {
-		return nil, "", fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "ExtractScheduleRaw", "gpt-4.1", reqBody)
 	if err != nil {
-		return nil, "", fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, "", err
 	}
 
 	var apiResp struct {
@@ -190,23 +266,7 @@ Return ONLY the JSON object, no other text.`, currentYear)
 
 // ExtractScheduleFromText sends text to OpenAI's API and extracts church service schedule entries.
 func (c *Client) ExtractScheduleFromText(ctx context.Context, text string) ([]ScheduleEntry, error) {
-	today := time.Now().Format("January 2, 2006")
-	prompt := fmt.Sprintf(`Extract church service schedule information from this text.
-Return a JSON array of services with these fields:
-- date: in YYYY-MM-DD format. IMPORTANT: Today is %s. Extract ALL events including past ones. If the text mentions a year that would place all events in the past, it is likely a typo; use the current year instead. If no year is specified, use 2026.
-- day_of_week: the day name in Swedish (e.g., "Måndag", "Söndag")
-- time: in HH:MM format (24-hour)
-- service_name: the name of the service in Swedish
-- occasion: optional, any special occasion or holiday mentioned
-
-Only include entries that have both a date/day and a time specified.
-Include entries where the time is given in prose form rather than tabular form. For example, "21 Tisdag Rádonitsa — minnesdag för de avsomnade Kl. 14.00 förrättas panichida på Skogskyrkogården" is a valid entry (date=21, time=14:00, service_name="Panichida på Skogskyrkogården", occasion="Rádonitsa — minnesdag för de avsomnade"). Times written as "Kl. HH.MM" or "HH.MM" should be normalized to "HH:MM".
-
-Correct typos where the day-of-week and the day-of-month disagree. ALWAYS trust the day-of-week label over the day number, even when the day number coincides with a traditional feast date (e.g., St. George is traditionally May 6, but if the entry reads "6 Lördag SM Georgios" in May 2026 and May 6 is a Wednesday, the parish has moved the observance to a Saturday — correct the date to the nearest Saturday in May 2026, e.g., May 2). Replace the day number with the nearest date in the same month that matches that weekday and is not already assigned to another entry. Another example: "7 Söndag Den lame mannens söndag" in May 2026 where May 7 is a Thursday → use May 3 (Sunday). If two surrounding entries bracket the ambiguous date, pick the option that keeps the schedule in chronological order where possible, but never assign the same date as another entry.
-Return ONLY the JSON array, no other text.
-
-Text to parse:
-`, today) + text
+	prompt := textSchedulePrompt(c.now()) + text
 
 	reqBody := map[string]interface{}{
 		"model": "gpt-4o",
@@ -219,32 +279,9 @@ Text to parse:
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ExtractScheduleFromText", "gpt-4o")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "ExtractScheduleFromText", "gpt-4o", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -282,6 +319,27 @@ Text to parse:
 	return entries, nil
 }
 
+// textSchedulePrompt builds the ExtractScheduleFromText prompt for the given
+// reference date. The schedule text is appended by the caller.
+func textSchedulePrompt(today time.Time) string {
+	return fmt.Sprintf(`Extract church service schedule information from this text.
+Return a JSON array of services with these fields:
+- date: in YYYY-MM-DD format. IMPORTANT: Today is %s. Extract ALL events including past ones. If the text mentions a year that would place all events in the past, it is likely a typo; use the current year instead. If no year is specified, use %d.
+- day_of_week: the day name in Swedish (e.g., "Måndag", "Söndag")
+- time: in HH:MM format (24-hour)
+- service_name: the name of the service in Swedish
+- occasion: optional, any special occasion or holiday mentioned
+
+Only include entries that have both a date/day and a time specified.
+Include entries where the time is given in prose form rather than tabular form. For example, "21 Tisdag Rádonitsa — minnesdag för de avsomnade Kl. 14.00 förrättas panichida på Skogskyrkogården" is a valid entry (date=21, time=14:00, service_name="Panichida på Skogskyrkogården", occasion="Rádonitsa — minnesdag för de avsomnade"). Times written as "Kl. HH.MM" or "HH.MM" should be normalized to "HH:MM".
+
+Correct typos where the day-of-week and the day-of-month disagree. ALWAYS trust the day-of-week label over the day number, even when the day number coincides with a traditional feast date (e.g., St. George is traditionally May 6, but if the entry reads "6 Lördag SM Georgios" in May 2026 and May 6 is a Wednesday, the parish has moved the observance to a Saturday — correct the date to the nearest Saturday in May 2026, e.g., May 2). Replace the day number with the nearest date in the same month that matches that weekday and is not already assigned to another entry. Another example: "7 Söndag Den lame mannens söndag" in May 2026 where May 7 is a Thursday → use May 3 (Sunday). If two surrounding entries bracket the ambiguous date, pick the option that keeps the schedule in chronological order where possible, but never assign the same date as another entry.
+Return ONLY the JSON array, no other text.
+
+Text to parse:
+`, today.Format("January 2, 2006"), today.Year())
+}
+
 // TranslateScheduleEntries translates raw schedule entries to Swedish using a text-only
 // OpenAI call. Returns the translated entries and the raw API response content.
 func (c *Client) TranslateScheduleEntries(ctx context.Context, entries []RawScheduleEntry) ([]ScheduleEntry, string, error) {
@@ -290,7 +348,7 @@ func (c *Client) TranslateScheduleEntries(ctx context.Context, entries []RawSche
 		return nil, "", fmt.Errorf("marshaling entries: %w", err)
 	}
 
-	today := time.Now().Format("January 2, 2006")
+	today := c.now().Format("January 2, 2006")
 	prompt := fmt.Sprintf(`Translate these Orthodox church service schedule entries to Swedish.
 Today is %s.
 
@@ -363,6 +421,7 @@ Return a JSON array of services with these fields:
 - occasion: optional, any special occasion or holiday, translated to Swedish
 - location: optional. If present in the input, carry it through verbatim — do not translate or modify place names or church names.
 - abroad: optional boolean. If present in the input, carry it through unchanged.
+- uncertain: optional boolean. If present in the input, carry it through unchanged.
 
 Return ONLY the JSON array, no other text.`, today, string(entriesJSON))
 
@@ -377,32 +436,9 @@ Return ONLY the JSON array, no other text.`, today, string(entriesJSON))
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, "", fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, "", fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "TranslateScheduleEntries", "gpt-4o-mini")
-	if err != nil {
-		return nil, "", fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "TranslateScheduleEntries", "gpt-4o-mini", reqBody)
 	if err != nil {
-		return nil, "", fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, "", err
 	}
 
 	var apiResp struct {
@@ -496,32 +532,9 @@ Return ONLY the JSON object, no other text.`, string(namesJSON))
 		"max_tokens": 4096,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
+	body, err := c.post(ctx, "GenerateTitles", "gpt-4o-mini", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "GenerateTitles", "gpt-4o-mini")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -573,7 +586,7 @@ type ExceptionService struct {
 // recurringDesc describes the normal weekly schedule for context.
 // Returns a list of date-level exceptions, or nil if no changes are described.
 func (c *Client) InterpretScheduleNotice(ctx context.Context, noticeText string, recurringDesc string) ([]ScheduleException, error) {
-	today := time.Now().Format("2006-01-02")
+	today := c.now().Format("2006-01-02")
 	prompt := fmt.Sprintf(`You are given text from a Serbian Orthodox church website. The page has two sections:
 1. A notice/announcement section ("Обавештење") that may describe temporary schedule changes
 2. A recurring weekly schedule section ("Редовни распоред")
@@ -624,32 +637,9 @@ Return ONLY a JSON array, no other text.`, recurringDesc, noticeText, today)
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "InterpretScheduleNotice", "gpt-4o")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "InterpretScheduleNotice", "gpt-4o", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -760,32 +750,9 @@ Return ONLY the JSON array, no other text.`, len(events), string(eventsJSON))
 		"max_tokens": 4096,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
+	body, err := c.post(ctx, "parseEventLanguagesBatch", "gpt-4o", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "parseEventLanguagesBatch", "gpt-4o")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -885,32 +852,9 @@ Return ONLY the JSON array, no other text.`, string(entriesJSON))
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ParseTimes", "gpt-4o-mini")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "ParseTimes", "gpt-4o-mini", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -953,14 +897,14 @@ Return ONLY the JSON array, no other text.`, string(entriesJSON))
 		r := results[i]
 		start, err := time.Parse(time.RFC3339, r.Start)
 		if err != nil {
-			log.Printf("WARNING: skipping bad start time for %s %s: %v", entry.Date, entry.Time, err)
+			slog.Warn("skipping bad start time", "date", entry.Date, "time", entry.Time, "err", err)
 			continue
 		}
 		pt := ParsedTime{Start: start}
 		if r.End != nil {
 			end, err := time.Parse(time.RFC3339, *r.End)
 			if err != nil {
-				log.Printf("WARNING: ignoring bad end time for %s %s: %v", entry.Date, entry.Time, err)
+				slog.Warn("ignoring bad end time", "date", entry.Date, "time", entry.Time, "err", err)
 			} else {
 				pt.End = &end
 			}
@@ -984,7 +928,7 @@ type CampEvent struct {
 // ExtractCampEvents sends webpage text to OpenAI and extracts camp/event information.
 // Returns individual day events for multi-day camps and reminder events for deadlines.
 func (c *Client) ExtractCampEvents(ctx context.Context, text string) ([]CampEvent, error) {
-	today := time.Now().Format("January 2, 2006")
+	today := c.now().Format("January 2, 2006")
 	prompt := fmt.Sprintf(`Extract event information from this webpage text about an Orthodox summer camp.
 
 Today is %s.
@@ -1016,32 +960,9 @@ Webpage text:
 		"max_tokens": 4096,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ExtractCampEvents", "gpt-4o-mini")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, "ExtractCampEvents", "gpt-4o-mini", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -1105,7 +1026,7 @@ func (c *Client) ExtractEventsFromImage(ctx context.Context, imageData []byte) (
 		mediaType = "image/png"
 	}
 
-	currentYear := time.Now().Year()
+	currentYear := c.now().Year()
 	prompt := fmt.Sprintf(`Extract event information from this church-related image (flyer, poster, schedule, etc.).
 
 Identify:
@@ -1159,32 +1080,9 @@ Return ONLY the JSON object, no other text.`, currentYear)
 		"max_tokens": 4096,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
+	body, err := c.post(ctx, "ExtractEventsFromImage", "gpt-4.1", reqBody)
 	if err != nil {
-		return nil, "", fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, "", fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ExtractEventsFromImage", "gpt-4.1")
-	if err != nil {
-		return nil, "", fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, "", err
 	}
 
 	var apiResp struct {
@@ -1221,8 +1119,8 @@ Return ONLY the JSON object, no other text.`, currentYear)
 // ExtractScheduleFromRussianText extracts Orthodox church service schedule entries
 // from Russian-language text, translating service names to Swedish.
 func (c *Client) ExtractScheduleFromRussianText(ctx context.Context, text string) ([]ScheduleEntry, error) {
-	today := time.Now().Format("January 2, 2006")
-	currentYear := time.Now().Year()
+	today := c.now().Format("January 2, 2006")
+	currentYear := c.now().Year()
 
 	prompt := fmt.Sprintf(`Extract Orthodox church service schedule entries from Russian text and translate service names to Swedish.
 
@@ -1348,32 +1246,9 @@ Text:
 		"max_tokens": 16384,
 	}
 
-	reqJSON, err := json.Marshal(reqBody)
+	body, err := c.post(ctx, "ExtractScheduleFromRussianText", "gpt-4o", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshaling request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.doRequest(req, "ExtractScheduleFromRussianText", "gpt-4o")
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var apiResp struct {
@@ -0,0 +1,63 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextSchedulePromptUsesReferenceDate(t *testing.T) {
+	today := time.Date(2027, time.January, 14, 9, 0, 0, 0, time.UTC)
+
+	prompt := textSchedulePrompt(today)
+	if !strings.Contains(prompt, "Today is January 14, 2027.") {
+		t.Error("prompt should state the reference date")
+	}
+	if !strings.Contains(prompt, "If no year is specified, use 2027.") {
+		t.Error("prompt should default to the reference year")
+	}
+	if strings.Contains(prompt, "use 2026") {
+		t.Error("prompt should not hardcode the default year")
+	}
+}
+
+func TestClientSetClock(t *testing.T) {
+	c := NewClient("test-key")
+	fixed := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+	c.SetClock(func() time.Time { return fixed })
+
+	if got := c.now(); !got.Equal(fixed) {
+		t.Errorf("now() = %v, want %v", got, fixed)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer srv.Close()
+	defer close(hang)
+
+	c := NewClient("test-key", WithTimeout(100*time.Millisecond))
+	c.apiURL = srv.URL
+
+	// The caller's deadline is far later than the client's timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GenerateTitles(ctx, []string{"Liturgi"})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want a deadline error", err)
+	}
+	if elapsed < 100*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want about 100ms", elapsed)
+	}
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"time"
+)
+
+// statusRecorder remembers the status code a handler responds with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// logRequests is middleware that logs each request's method, path, status
+// code and duration once the handler returns.
+func (h *Handler) logRequests(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w}
+		next(sr, r)
+		if sr.status == 0 {
+			sr.status = http.StatusOK
+		}
+		h.logFor(r.Context()).Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", sr.status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000)
+	}
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// requireAdmin only lets through requests carrying the admin token as
+// "Authorization: Bearer <token>". Without a configured token the admin
+// endpoints do not exist.
+func (h *Handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// classifyResult is the response of /admin/classify.
+type classifyResult struct {
+	Text       string `json:"text"`
+	Normalized string `json:"normalized"`
+	Type       string `json:"type"`
+	Keyword    string `json:"keyword"`
+}
+
+// handleClassify shows how a service name is classified, for tuning the
+// service type keywords: GET /admin/classify?text=Helig+Liturgi.
+func (h *Handler) handleClassify(w http.ResponseWriter, r *http.Request) {
+	text := r.URL.Query().Get("text")
+	if strings.TrimSpace(text) == "" {
+		http.Error(w, "Missing text parameter", http.StatusBadRequest)
+		return
+	}
+
+	serviceType, keyword := classifyServiceName(text)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(classifyResult{
+		Text:       text,
+		Normalized: strings.Join(strings.Fields(strings.ToLower(text)), " "),
+		Type:       serviceType,
+		Keyword:    keyword,
+	})
+}
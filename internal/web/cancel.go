@@ -0,0 +1,206 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/ical"
+	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
+)
+
+// publishedKey is the store key of the events the calendar has published.
+const publishedKey = "ics/published"
+
+// publishedEvent is an event the calendar feed has published, remembered so
+// that a client can be told when it disappears.
+type publishedEvent struct {
+	Date      string `json:"date"`    // YYYY-MM-DD
+	DTStart   string `json:"dtstart"` // the DTSTART property as published
+	Summary   string `json:"summary"`
+	Sequence  int    `json:"sequence"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+}
+
+// handleCancellationsICS serves a METHOD:CANCEL calendar with an event for
+// each previously published service that is no longer in the feed, so that
+// subscribed clients drop it instead of keeping a stale copy. The published
+// events are recorded by the ingest job, see RecordPublished.
+func (h *Handler) handleCancellationsICS(w http.ResponseWriter, r *http.Request) {
+	if h.published == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	published, err := loadPublished(h.published)
+	if err != nil {
+		h.logFor(r.Context()).Error("loading published events", "err", err)
+		http.Error(w, "Failed to load published events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(generateCancellationsICS(published, time.Now())))
+}
+
+// RecordPublished records services as the events the calendar feed
+// publishes, marking previously published events that are gone as cancelled.
+// The ingest job calls it after each run; as the only writer it needs no
+// locking. If the stored events cannot be read, nothing is written, so that
+// the cancellation history is not lost.
+func RecordPublished(st store.Store, services []model.ChurchService, now time.Time) error {
+	published, err := loadPublished(st)
+	if err != nil {
+		return err
+	}
+	// Events that aged out of the feed's window were not cancelled
+	cutoff := now.AddDate(0, 0, -7).Format("2006-01-02")
+	published = updatePublished(published, filterAndSort(services), cutoff)
+	if err := st.SetJSON(publishedKey, published); err != nil {
+		return fmt.Errorf("saving published events: %w", err)
+	}
+	return nil
+}
+
+// loadPublished reads the published events. A missing key means nothing has
+// been published yet; any other failure is an error.
+func loadPublished(st store.Store) (map[string]publishedEvent, error) {
+	published := make(map[string]publishedEvent)
+	data, ok := st.Get(publishedKey)
+	if !ok {
+		// Get does not tell a missing key from a failed read
+		keys, err := st.List(publishedKey)
+		if err != nil {
+			return nil, fmt.Errorf("listing published events: %w", err)
+		}
+		if slices.Contains(keys, publishedKey) {
+			return nil, fmt.Errorf("reading published events from %s", publishedKey)
+		}
+		return published, nil
+	}
+	if err := json.Unmarshal(data, &published); err != nil {
+		return nil, fmt.Errorf("decoding published events: %w", err)
+	}
+	return published, nil
+}
+
+// publishedSequencesTTL is how long the published SEQUENCEs are reused
+// before the store is read again. Only the ingest job changes them.
+const publishedSequencesTTL = 5 * time.Minute
+
+// publishedSequences returns the SEQUENCE of each published event, for the
+// calendar feed to match the cancellation feed, read at most once per
+// publishedSequencesTTL. It returns nil, so that no SEQUENCE is written, if
+// there is no store or it has not been read yet; after a failed read the
+// last known SEQUENCEs are used and the next request tries again.
+func (h *Handler) publishedSequences(ctx context.Context) map[string]int {
+	if h.published == nil {
+		return nil
+	}
+	h.sequencesMu.Lock()
+	defer h.sequencesMu.Unlock()
+
+	if !h.sequencesCheckedAt.IsZero() && time.Since(h.sequencesCheckedAt) < publishedSequencesTTL {
+		return h.sequences
+	}
+	published, err := loadPublished(h.published)
+	if err != nil {
+		h.logFor(ctx).Warn("loading published events", "err", err)
+		return h.sequences
+	}
+	sequences := make(map[string]int, len(published))
+	for uid, ev := range published {
+		if ev.Sequence > 0 {
+			sequences[uid] = ev.Sequence
+		}
+	}
+	h.sequences = sequences
+	h.sequencesCheckedAt = time.Now()
+	return sequences
+}
+
+// updatePublished records the current services as published and marks
+// published events that are missing from them as cancelled, bumping their
+// SEQUENCE once. An event that returns after being cancelled is bumped again,
+// so that clients take it over the cancellation. Events before cutoff are
+// forgotten.
+func updatePublished(published map[string]publishedEvent, services []model.ChurchService, cutoff string) map[string]publishedEvent {
+	current := make(map[string]bool, len(services))
+	for _, s := range services {
+		uid := icsUID(s)
+		current[uid] = true
+		sequence := published[uid].Sequence
+		if published[uid].Cancelled {
+			sequence++
+		}
+		published[uid] = publishedEvent{
+			Date:     s.Date,
+			DTStart:  icsStartProperty(s),
+			Summary:  icsSummary(s),
+			Sequence: sequence,
+		}
+	}
+
+	for uid, ev := range published {
+		switch {
+		case ev.Date < cutoff:
+			delete(published, uid)
+		case !current[uid] && !ev.Cancelled:
+			ev.Cancelled = true
+			ev.Sequence++
+			published[uid] = ev
+		}
+	}
+	return published
+}
+
+// icsStartProperty returns the DTSTART property of a service's event.
+func icsStartProperty(s model.ChurchService) string {
+	if s.StartTime != nil {
+		return "DTSTART;TZID=Europe/Stockholm:" + s.StartTime.Format("20060102T150405")
+	}
+	if s.Time != nil {
+		if start := ical.ParseStartTime(*s.Time); start != "" {
+			return "DTSTART;TZID=Europe/Stockholm:" + strings.ReplaceAll(s.Date, "-", "") + "T" + start
+		}
+	}
+	return "DTSTART;VALUE=DATE:" + strings.ReplaceAll(s.Date, "-", "")
+}
+
+// generateCancellationsICS renders the cancelled events as a METHOD:CANCEL
+// calendar.
+func generateCancellationsICS(published map[string]publishedEvent, now time.Time) string {
+	uids := make([]string, 0, len(published))
+	for uid, ev := range published {
+		if ev.Cancelled {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Strings(uids)
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\n")
+	sb.WriteString("VERSION:2.0\r\n")
+	sb.WriteString("PRODID:-//Ortodoxa Gudstjänster//SV\r\n")
+	sb.WriteString("CALSCALE:GREGORIAN\r\n")
+	sb.WriteString("METHOD:CANCEL\r\n")
+	for _, uid := range uids {
+		ev := published[uid]
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		sb.WriteString(fmt.Sprintf("UID:%s\r\n", uid))
+		sb.WriteString(fmt.Sprintf("SEQUENCE:%d\r\n", ev.Sequence))
+		sb.WriteString("STATUS:CANCELLED\r\n")
+		sb.WriteString(ev.DTStart + "\r\n")
+		sb.WriteString(fmt.Sprintf("SUMMARY:%s\r\n", ical.Escape(ev.Summary)))
+		sb.WriteString(fmt.Sprintf("DTSTAMP:%s\r\n", now.UTC().Format("20060102T150405Z")))
+		sb.WriteString("END:VEVENT\r\n")
+	}
+	sb.WriteString("END:VCALENDAR\r\n")
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/ical"
+	"ortodoxa-gudstjanster/internal/model"
+)
+
+const siteURL = "https://ortodoxagudstjanster.se"
+
+// handleAtomFeed serves the upcoming services as an Atom 1.0 feed, one
+// entry per service.
+func (h *Handler) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	services, err := h.fetcher.GetAllServices(ctx)
+	if err != nil {
+		h.logFor(ctx).Error("fetching services", "err", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+	services = filterAndSort(services)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write([]byte(generateAtom(services, time.Now())))
+}
+
+func generateAtom(services []model.ChurchService, now time.Time) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	sb.WriteString(`<feed xmlns="http://www.w3.org/2005/Atom">` + "\n")
+	sb.WriteString("  <title>Ortodoxa Gudstjänster</title>\n")
+	fmt.Fprintf(&sb, "  <id>%s/</id>\n", siteURL)
+	fmt.Fprintf(&sb, "  <link href=\"%s/\"/>\n", siteURL)
+	fmt.Fprintf(&sb, "  <link rel=\"self\" href=\"%s/feed.atom\"/>\n", siteURL)
+	fmt.Fprintf(&sb, "  <updated>%s</updated>\n", now.UTC().Format(time.RFC3339))
+
+	for _, s := range services {
+		sb.WriteString("  <entry>\n")
+		fmt.Fprintf(&sb, "    <title>%s</title>\n", escapeXML(s.DisplayName()))
+		fmt.Fprintf(&sb, "    <id>urn:ortodoxa-gudstjanster:%s</id>\n", serviceID(s))
+		fmt.Fprintf(&sb, "    <updated>%s</updated>\n", serviceStart(s).Format(time.RFC3339))
+		fmt.Fprintf(&sb, "    <link href=\"%s\"/>\n", escapeXML(serviceLink(s)))
+		fmt.Fprintf(&sb, "    <author><name>%s</name></author>\n", escapeXML(parishGroup(s)))
+		fmt.Fprintf(&sb, "    <summary>%s</summary>\n", escapeXML(s.ServiceName))
+		sb.WriteString("  </entry>\n")
+	}
+
+	sb.WriteString("</feed>\n")
+	return sb.String()
+}
+
+// jsonFeed is a JSON Feed 1.1 document (https://jsonfeed.org/version/1.1).
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	DatePublished string `json:"date_published"`
+}
+
+// handleJSONFeed serves the upcoming services as a JSON Feed, one item per
+// service.
+func (h *Handler) handleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	services, err := h.fetcher.GetAllServices(ctx)
+	if err != nil {
+		h.logFor(ctx).Error("fetching services", "err", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+	services = filterAndSort(services)
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	json.NewEncoder(w).Encode(buildJSONFeed(services))
+}
+
+func buildJSONFeed(services []model.ChurchService) jsonFeed {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       "Ortodoxa Gudstjänster",
+		HomePageURL: siteURL + "/",
+		FeedURL:     siteURL + "/feed.json",
+		Language:    "sv",
+		Items:       make([]jsonFeedItem, 0, len(services)),
+	}
+	for _, s := range services {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            serviceID(s),
+			URL:           serviceLink(s),
+			Title:         s.DisplayName(),
+			ContentText:   s.ServiceName,
+			DatePublished: serviceStart(s).Format(time.RFC3339),
+		})
+	}
+	return feed
+}
+
+// escapeXML escapes text for use in XML character data and attribute values.
+func escapeXML(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// serviceID returns the Firestore document ID of a service, or the same
+// field hash that the ingest job uses to derive it.
+func serviceID(s model.ChurchService) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	timeStr := ""
+	if s.Time != nil {
+		timeStr = *s.Time
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", s.Source, s.Date, s.ServiceName, timeStr)))
+	return hex.EncodeToString(hash[:16])
+}
+
+// serviceStart returns when a service starts in Stockholm time: StartTime if
+// set, else the date plus the parsed start time, else midnight.
+func serviceStart(s model.ChurchService) time.Time {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		stockholm = time.UTC
+	}
+	if s.StartTime != nil {
+		return s.StartTime.In(stockholm)
+	}
+	clock := "000000"
+	if s.Time != nil {
+		if start := ical.ParseStartTime(*s.Time); start != "" {
+			clock = start
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02 150405", s.Date+" "+clock, stockholm)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// serviceLink returns the page a feed item should link to: the source page if
+// known, otherwise the service's event page.
+func serviceLink(s model.ChurchService) string {
+	if s.SourceURL != "" {
+		return s.SourceURL
+	}
+	return siteURL + "/event/" + serviceID(s)
+}
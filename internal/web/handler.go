@@ -8,15 +8,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"ortodoxa-gudstjanster/internal/calendar"
+	"ortodoxa-gudstjanster/internal/dateutil"
 	"ortodoxa-gudstjanster/internal/email"
+	"ortodoxa-gudstjanster/internal/firestore"
+	"ortodoxa-gudstjanster/internal/ical"
 	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
 )
 
 //go:embed templates/*
@@ -41,6 +54,7 @@ type rateLimiter struct {
 	limit     int
 	window    time.Duration
 	lastPrune time.Time
+	now       func() time.Time // replaceable in tests
 }
 
 func newRateLimiter(limit int, window time.Duration) *rateLimiter {
@@ -48,14 +62,22 @@ func newRateLimiter(limit int, window time.Duration) *rateLimiter {
 		requests: make(map[string][]time.Time),
 		limit:    limit,
 		window:   window,
+		now:      time.Now,
 	}
 }
 
+// trackedIPs returns the number of IP addresses with recorded submissions.
+func (rl *rateLimiter) trackedIPs() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.requests)
+}
+
 func (rl *rateLimiter) allow(ip string) bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.now()
 	cutoff := now.Add(-rl.window)
 
 	// Periodically prune expired IPs to prevent unbounded map growth
@@ -97,16 +119,72 @@ func (rl *rateLimiter) allow(ip string) bool {
 type Handler struct {
 	fetcher         ServiceFetcher
 	parishReloader  ParishReloader
-	smtp            *email.SMTPConfig
+	mail            email.Sender
+	feedbackTo      []string
 	rateLimiter     *rateLimiter
+	trustedProxies  int
+	logger          *slog.Logger
+	sources         []model.SourceMetadata
+	adminToken      string
+	published       store.Store // published event UIDs, for the cancellation feed
+
+	storeProbeMu        sync.Mutex
+	storeProbeErr       error // result of the last published store probe, see checkPublishedStore
+	storeProbeCheckedAt time.Time
+
+	sequencesMu        sync.Mutex
+	sequences          map[string]int // see publishedSequences
+	sequencesCheckedAt time.Time
+
+	generatedMu        sync.Mutex
+	generated          time.Time // time of the latest ingest batch, see dataGenerated
+	generatedCheckedAt time.Time
+
+	ingest           IngestReader // for /metrics
+	metricsMu        sync.Mutex
+	metrics          ingestMetrics // see ingestMetrics
+	metricsCheckedAt time.Time
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithFeedbackRateLimit sets how many feedback submissions one IP address
+// may make per window (default 3 per hour).
+func WithFeedbackRateLimit(limit int, window time.Duration) Option {
+	return func(h *Handler) {
+		h.rateLimiter = newRateLimiter(limit, window)
+	}
+}
+
+// WithTrustedProxies sets how many proxies in front of the server append to
+// X-Forwarded-For (default 1, the Cloud Run front end). The client IP is
+// taken that many entries from the right; 0 ignores the header.
+func WithTrustedProxies(n int) Option {
+	return func(h *Handler) {
+		h.trustedProxies = n
+	}
+}
+
+// WithLogger sets the logger for errors and warnings (default slog.Default()).
+func WithLogger(l *slog.Logger) Option {
+	return func(h *Handler) {
+		h.logger = l
+	}
 }
 
 // New creates a new Handler with the given service fetcher.
-func New(fetcher ServiceFetcher) *Handler {
-	return &Handler{
-		fetcher:     fetcher,
-		rateLimiter: newRateLimiter(3, time.Hour), // 3 submissions per hour per IP
+func New(fetcher ServiceFetcher, opts ...Option) *Handler {
+	h := &Handler{
+		fetcher:        fetcher,
+		rateLimiter:    newRateLimiter(3, time.Hour), // 3 submissions per hour per IP
+		trustedProxies: 1,
+		logger:         slog.Default(),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // SetParishReloader sets the parish reloader for the reload-parishes endpoint.
@@ -114,39 +192,77 @@ func (h *Handler) SetParishReloader(r ParishReloader) {
 	h.parishReloader = r
 }
 
-// SetSMTP configures SMTP for sending feedback emails.
-func (h *Handler) SetSMTP(config *email.SMTPConfig) {
-	h.smtp = config
+// SetMail configures sending feedback emails to the given recipients.
+func (h *Handler) SetMail(sender email.Sender, to []string) {
+	h.mail = sender
+	h.feedbackTo = to
+}
+
+// SetAdminToken sets the bearer token required by the /admin/ endpoints.
+// They are disabled while it is empty.
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// SetPublishedStore sets the store that remembers which events the calendar
+// has published, enabling the cancellation feed.
+func (h *Handler) SetPublishedStore(s store.Store) {
+	h.published = s
+}
+
+// SetIngestReader sets where /metrics reads the ingest job's records. The
+// endpoint serves no metrics without one.
+func (h *Handler) SetIngestReader(r IngestReader) {
+	h.ingest = r
+}
+
+// SetSources sets the registered sources listed at /sources.
+func (h *Handler) SetSources(sources []model.SourceMetadata) {
+	h.sources = sources
 }
 
-// RegisterRoutes registers all HTTP routes on the given mux.
+// RegisterRoutes registers all HTTP routes on the given mux, each with an
+// access log line per request.
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/", h.noCache(h.handleIndex))
-	mux.HandleFunc("/api/services", h.noCache(h.handleServices))
-	mux.HandleFunc("/api/last-updated", h.noCache(h.handleLastUpdated))
-	mux.HandleFunc("/services", redirect("/api/services"))
-	mux.HandleFunc("/last-updated", redirect("/api/last-updated"))
-	mux.HandleFunc("/calendar.ics", h.noCache(h.handleICS))
-	mux.HandleFunc("/api/parishes", h.handleParishesAPI)
-	mux.HandleFunc("/parishes", h.handleParishesPage)
-	mux.HandleFunc("/parish/", h.handleParish)
-	mux.HandleFunc("/event/", h.handleEvent)
-	mux.HandleFunc("/feedback", h.handleFeedback)
-	mux.HandleFunc("/health", h.handleHealth)
-	mux.HandleFunc("/reload-parishes", h.handleReloadParishes)
-	mux.HandleFunc("/favicon.svg", h.handleFavicon)
-	mux.HandleFunc("/favicon-48.png", h.handleFavicon48)
-	mux.HandleFunc("/icon-192.png", h.handleIcon192)
-	mux.HandleFunc("/icon-512.png", h.handleIcon512)
-	mux.HandleFunc("/apple-touch-icon.png", h.handleAppleTouchIcon)
-	mux.HandleFunc("/manifest.json", h.handleManifest)
-	mux.HandleFunc("/sw.js", h.handleServiceWorker)
-	mux.HandleFunc("/calendar", h.handleCalendar)
-	mux.HandleFunc("/about", h.handleAbout)
-	mux.HandleFunc("/privacy", h.handlePrivacy)
-	mux.HandleFunc("/robots.txt", h.handleRobots)
-	mux.HandleFunc("/sitemap.xml", h.handleSitemap)
-	mux.HandleFunc("/.well-known/assetlinks.json", h.handleAssetLinks)
+	handle := func(pattern string, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, h.logRequests(handler))
+	}
+
+	handle("/", h.noCache(h.handleIndex))
+	handle("/api/services", h.noCache(h.handleServices))
+	handle("/api/last-updated", h.noCache(h.handleLastUpdated))
+	handle("/next-per-source", h.noCache(h.handleNextPerSource))
+	handle("/sources", h.handleSources)
+	handle("/services", redirect("/api/services"))
+	handle("/last-updated", redirect("/api/last-updated"))
+	handle("/calendar.ics", h.noCache(h.handleICS))
+	handle("/calendar-cancellations.ics", h.noCache(h.handleCancellationsICS))
+	handle("/feed.atom", h.noCache(h.handleAtomFeed))
+	handle("/feed.json", h.noCache(h.handleJSONFeed))
+	handle("/api/parishes", h.handleParishesAPI)
+	handle("/parishes", h.handleParishesPage)
+	handle("/parish/", h.handleParish)
+	handle("/event/", h.handleEvent)
+	handle("/feedback", h.handleFeedback)
+	handle("/health", h.handleHealth)
+	handle("/healthz", h.noCache(h.handleHealthz))
+	handle("/health/ready", h.noCache(h.handleReady))
+	handle("/metrics", h.handleMetrics)
+	handle("/reload-parishes", h.handleReloadParishes)
+	handle("/admin/classify", h.requireAdmin(h.handleClassify))
+	handle("/favicon.svg", h.handleFavicon)
+	handle("/favicon-48.png", h.handleFavicon48)
+	handle("/icon-192.png", h.handleIcon192)
+	handle("/icon-512.png", h.handleIcon512)
+	handle("/apple-touch-icon.png", h.handleAppleTouchIcon)
+	handle("/manifest.json", h.handleManifest)
+	handle("/sw.js", h.handleServiceWorker)
+	handle("/calendar", h.handleCalendar)
+	handle("/about", h.handleAbout)
+	handle("/privacy", h.handlePrivacy)
+	handle("/robots.txt", h.handleRobots)
+	handle("/sitemap.xml", h.handleSitemap)
+	handle("/.well-known/assetlinks.json", h.handleAssetLinks)
 }
 
 func (h *Handler) noCache(next http.HandlerFunc) http.HandlerFunc {
@@ -164,6 +280,34 @@ func parseWithTheme(name string) (*template.Template, error) {
 	return template.ParseFS(templates, "templates/"+name, "templates/_theme.html")
 }
 
+// pageTemplates lists the page templates rendered through parseWithTheme, and
+// staticFiles the embedded files served as-is.
+var (
+	pageTemplates = []string{"404.html", "index.html", "parishes.html", "parish.html", "event.html", "feedback.html", "calendar.html", "about.html", "privacy.html"}
+	staticFiles   = []string{"favicon.svg", "manifest.json", "sw.js", "assetlinks.json"}
+)
+
+// ValidateTemplates checks that every embedded page template parses together
+// with the theme and that every static file is present, so a renamed or
+// broken template fails at startup instead of on the first request.
+func ValidateTemplates() error {
+	return validateTemplates(templates)
+}
+
+func validateTemplates(fsys fs.FS) error {
+	for _, name := range pageTemplates {
+		if _, err := template.ParseFS(fsys, "templates/"+name, "templates/_theme.html"); err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	for _, name := range staticFiles {
+		if _, err := fs.Stat(fsys, "templates/"+name); err != nil {
+			return fmt.Errorf("static file %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func render404(w http.ResponseWriter) {
 	tmpl, err := parseWithTheme("404.html")
 	if err != nil {
@@ -310,18 +454,164 @@ func buildEventJSONLD(services []model.ChurchService) string {
 }
 
 func (h *Handler) handleServices(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	services, err := h.fetcher.GetAllServices(ctx)
 	if err != nil {
+		h.logFor(ctx).Error("fetching services", "err", err)
 		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
 		return
 	}
 	services = filterAndSort(services)
+	services = excludeServiceTypes(services, r.URL.Query().Get("exclude_type"))
+
+	// lang= localizes the day of week; the stored value stays Swedish
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		for i := range services {
+			services[i].DayOfWeek = dateutil.LocalizeWeekday(services[i].DayOfWeek, lang)
+		}
+	}
+
+	h.setDataGenerated(ctx, w)
+
+	var body interface{} = services
+	if fields != nil {
+		projected, err := projectServices(services, fields)
+		if err != nil {
+			http.Error(w, "Failed to encode services", http.StatusInternalServerError)
+			return
+		}
+		body = projected
+	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(services)
+	enc := json.NewEncoder(w)
+	if r.URL.Query().Get("pretty") == "1" {
+		enc.SetIndent("", "  ")
+	}
+	enc.Encode(body)
+}
+
+// serviceFields is the set of JSON field names of model.ChurchService.
+var serviceFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(model.ChurchService{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// parseFields parses a comma-separated fields= list, rejecting names that
+// are not JSON fields of a service. It returns nil for an empty list.
+func parseFields(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !serviceFields[f] {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// projectServices returns the services as JSON objects holding only the
+// given fields.
+func projectServices(services []model.ChurchService, fields []string) ([]map[string]json.RawMessage, error) {
+	projected := make([]map[string]json.RawMessage, 0, len(services))
+	for _, s := range services {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		obj := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := all[f]; ok {
+				obj[f] = v
+			}
+		}
+		projected = append(projected, obj)
+	}
+	return projected, nil
+}
+
+// handleNextPerSource returns, for every registered source, its next
+// upcoming service, or null if it has none.
+func (h *Handler) handleNextPerSource(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	services, err := h.fetcher.GetAllServices(ctx)
+	if err != nil {
+		h.logFor(ctx).Error("fetching services", "err", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(nextPerSource(filterAndSort(services), h.sources, time.Now()))
+}
+
+// handleSources lists the registered sources and their metadata.
+func (h *Handler) handleSources(w http.ResponseWriter, r *http.Request) {
+	sources := h.sources
+	if sources == nil {
+		sources = []model.SourceMetadata{}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(sources)
+}
+
+// nextPerSource picks, per source, the first service that has not yet
+// started at now. Every source in sources is present, mapped to nil if it
+// has no upcoming service. services must be sorted as by filterAndSort.
+func nextPerSource(services []model.ChurchService, sources []model.SourceMetadata, now time.Time) map[string]*model.ChurchService {
+	next := make(map[string]*model.ChurchService)
+	for _, src := range sources {
+		next[src.Name] = nil
+	}
+
+	// Dates are Stockholm dates, so "today" must be too
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		stockholm = time.UTC
+	}
+	today := now.In(stockholm).Format("2006-01-02")
+	for _, s := range services {
+		if s.Date < today {
+			continue
+		}
+		// Services later today count until they start; all-day ones all day
+		if s.Date == today && s.Time != nil && ical.ParseStartTime(*s.Time) != "" && serviceStart(s).Before(now) {
+			continue
+		}
+		if next[s.Source] == nil {
+			svc := s
+			next[s.Source] = &svc
+		}
+	}
+	return next
 }
 
 func (h *Handler) handleICS(w http.ResponseWriter, r *http.Request) {
@@ -330,6 +620,7 @@ func (h *Handler) handleICS(w http.ResponseWriter, r *http.Request) {
 
 	services, err := h.fetcher.GetAllServices(ctx)
 	if err != nil {
+		h.logFor(ctx).Error("fetching services", "err", err)
 		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
 		return
 	}
@@ -341,6 +632,7 @@ func (h *Handler) handleICS(w http.ResponseWriter, r *http.Request) {
 	//   3. exclude= (oldest legacy blacklist, kept for oldest ICS links) — scoped to Stockholm
 	//   4. no params — default to Stockholm only
 	queryValues := r.URL.Query()
+	includeParam, extras := splitIncludeExtras(queryValues.Get("include"))
 	_, hasIncludeCounties := queryValues["includeCounties"]
 	_, hasIncludeParishes := queryValues["includeParishes"]
 	if hasIncludeCounties || hasIncludeParishes {
@@ -369,7 +661,7 @@ func (h *Handler) handleICS(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		services = filtered
-	} else if includeParam := queryValues.Get("include"); includeParam != "" {
+	} else if includeParam != "" {
 		// Legacy: include= parish whitelist
 		included := make(map[string]bool)
 		for _, source := range strings.Split(includeParam, ",") {
@@ -445,15 +737,167 @@ func (h *Handler) handleICS(w http.ResponseWriter, r *http.Request) {
 		services = filtered
 	}
 
+	// feasts_only=1: keep only services on Sundays and great feasts
+	if queryValues.Get("feasts_only") == "1" {
+		var filtered []model.ChurchService
+		for _, s := range services {
+			if isSundayOrFeast(s.Date, oldCalendarSources[s.Source]) {
+				filtered = append(filtered, s)
+			}
+		}
+		services = filtered
+	}
+
+	// certain_only=1: drop services whose date or time the OCR model guessed
+	if queryValues.Get("certain_only") == "1" {
+		var filtered []model.ChurchService
+		for _, s := range services {
+			if !s.Uncertain {
+				filtered = append(filtered, s)
+			}
+		}
+		services = filtered
+	}
+
+	services = excludeServiceTypes(services, queryValues.Get("exclude_type"))
+
+	services = h.withSourceLocations(services)
+
+	// Opt-in non-service entries, added after filtering since they belong to no parish or language
+	if extras["fasts"] {
+		now := time.Now()
+		services = append(services, calendar.FastServices(now.AddDate(0, 0, -7), now.AddDate(1, 0, 0))...)
+	}
+	if extras["saints"] {
+		now := time.Now()
+		services = append(services, calendar.CommemorationServices(now.AddDate(0, 0, -7), now.AddDate(1, 0, 0))...)
+	}
+
+	h.setDataGenerated(ctx, w)
 	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
 	w.Header().Set("Content-Disposition", "inline; filename=\"ortodoxa-gudstjanster.ics\"")
 
 	// Generate ICS content
-	ics := generateICS(services)
+	calName, calDesc := icsCalendarTitle(queryValues)
+	ics := generateICS(services, icsOptions{
+		calName:   calName,
+		calDesc:   calDesc,
+		lang:      queryValues.Get("lang"),
+		sources:   h.sourcesByName(),
+		omitNotes: queryValues.Get("notes") == "0",
+		sequences: h.publishedSequences(ctx),
+	})
 	w.Write([]byte(ics))
 }
 
-func generateICS(services []model.ChurchService) string {
+// withSourceLocations fills in the location of services that have none from
+// their source's metadata, so every calendar event carries a LOCATION.
+func (h *Handler) withSourceLocations(services []model.ChurchService) []model.ChurchService {
+	sources := h.sourcesByName()
+	for i, s := range services {
+		if s.Location != nil && *s.Location != "" {
+			continue
+		}
+		if src, ok := sources[s.Source]; ok && src.Location != "" {
+			loc := src.Location
+			services[i].Location = &loc
+		}
+	}
+	return services
+}
+
+// sourcesByName indexes the registered sources by name.
+func (h *Handler) sourcesByName() map[string]model.SourceMetadata {
+	sources := make(map[string]model.SourceMetadata, len(h.sources))
+	for _, src := range h.sources {
+		sources[src.Name] = src
+	}
+	return sources
+}
+
+// icsUID returns the stable UID of a service's calendar event, derived from
+// its source, date, name and time.
+func icsUID(s model.ChurchService) string {
+	timeStr := ""
+	if s.Time != nil {
+		timeStr = *s.Time
+	}
+	uidData := fmt.Sprintf("%s|%s|%s|%s", s.Source, s.Date, s.ServiceName, timeStr)
+	uidHash := sha256.Sum256([]byte(uidData))
+	return hex.EncodeToString(uidHash[:16]) + "@ortodoxa-gudstjanster"
+}
+
+// icsCalendarName is the name of the unfiltered calendar feed.
+const icsCalendarName = "Ortodoxa Gudstjänster"
+
+// icsCalendarTitle returns the calendar name and description for a feed with
+// the given query. A feed for a single parish or county is named after it;
+// the description lists the filters. Both are empty for an unfiltered feed.
+func icsCalendarTitle(q url.Values) (name, desc string) {
+	include, _ := splitIncludeExtras(q.Get("include"))
+	parishNames := splitCommaList(q.Get("includeParishes"))
+	if len(parishNames) == 0 {
+		parishNames = splitCommaList(include)
+	}
+	counties := splitCommaList(q.Get("includeCounties"))
+
+	switch {
+	case len(parishNames) == 1 && len(counties) == 0:
+		name = icsCalendarName + " – " + parishNames[0]
+	case len(counties) == 1 && len(parishNames) == 0:
+		name = icsCalendarName + " – " + counties[0]
+	}
+
+	var parts []string
+	if len(parishNames) > 0 {
+		parts = append(parts, "Församlingar: "+strings.Join(parishNames, ", "))
+	}
+	if len(counties) > 0 {
+		parts = append(parts, "Län: "+strings.Join(counties, ", "))
+	}
+	if langs := splitCommaList(q.Get("includeLang")); len(langs) > 0 {
+		parts = append(parts, "Språk: "+strings.Join(langs, ", "))
+	}
+	if types := splitCommaList(q.Get("exclude_type")); len(types) > 0 {
+		parts = append(parts, "Utan: "+strings.Join(types, ", "))
+	}
+	if q.Get("feasts_only") == "1" {
+		parts = append(parts, "Endast söndagar och stora högtider")
+	}
+	if len(parts) > 0 {
+		desc = strings.Join(parts, ". ")
+	}
+	return name, desc
+}
+
+// splitCommaList splits a comma-separated query value, dropping empty items.
+func splitCommaList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GenerateICS renders services as an iCalendar (RFC 5545) feed.
+func GenerateICS(services []model.ChurchService) string {
+	return generateICS(services, icsOptions{})
+}
+
+// icsOptions tailors the feed generateICS renders.
+type icsOptions struct {
+	calName   string                          // X-WR-CALNAME; defaults to icsCalendarName
+	calDesc   string                          // X-WR-CALDESC; left out if empty
+	lang      string                          // if set, descriptions state the day of week in this language
+	sources   map[string]model.SourceMetadata // source metadata by name, for GEO
+	omitNotes bool                            // leave the "Info:" notes line out of descriptions
+	sequences map[string]int                  // SEQUENCE by UID, for events revised since first published
+}
+
+// generateICS renders services as an iCalendar feed.
+func generateICS(services []model.ChurchService, opts icsOptions) string {
 	var sb strings.Builder
 
 	sb.WriteString("BEGIN:VCALENDAR\r\n")
@@ -461,23 +905,30 @@ func generateICS(services []model.ChurchService) string {
 	sb.WriteString("PRODID:-//Ortodoxa Gudstjänster//SV\r\n")
 	sb.WriteString("CALSCALE:GREGORIAN\r\n")
 	sb.WriteString("METHOD:PUBLISH\r\n")
-	sb.WriteString("X-WR-CALNAME:Ortodoxa Gudstjänster\r\n")
+	calName := opts.calName
+	if calName == "" {
+		calName = icsCalendarName
+	}
+	sb.WriteString(fmt.Sprintf("X-WR-CALNAME:%s\r\n", ical.Escape(calName)))
+	if opts.calDesc != "" {
+		sb.WriteString(fmt.Sprintf("X-WR-CALDESC:%s\r\n", ical.Escape(opts.calDesc)))
+	}
 	sb.WriteString("X-WR-TIMEZONE:Europe/Stockholm\r\n")
 
 	for _, s := range services {
 		sb.WriteString("BEGIN:VEVENT\r\n")
 
-		// Generate stable UID from service fields
-		timeStr := ""
-		if s.Time != nil {
-			timeStr = *s.Time
-		}
-		uidData := fmt.Sprintf("%s|%s|%s|%s", s.Source, s.Date, s.ServiceName, timeStr)
-		uidHash := sha256.Sum256([]byte(uidData))
-		uid := hex.EncodeToString(uidHash[:16]) + "@ortodoxa-gudstjanster"
+		uid := icsUID(s)
 		sb.WriteString(fmt.Sprintf("UID:%s\r\n", uid))
+		if seq := opts.sequences[uid]; seq > 0 {
+			sb.WriteString(fmt.Sprintf("SEQUENCE:%d\r\n", seq))
+		}
 
-		// Date and time
+		// Date and time; a time that cannot be parsed makes an all-day event
+		var startTime, endTime string
+		if s.Time != nil {
+			startTime, endTime = ical.ParseTimeRange(*s.Time)
+		}
 		if s.StartTime != nil {
 			dtstart := s.StartTime.Format("20060102T150405")
 			sb.WriteString(fmt.Sprintf("DTSTART;TZID=Europe/Stockholm:%s\r\n", dtstart))
@@ -485,38 +936,57 @@ func generateICS(services []model.ChurchService) string {
 				dtend := s.EndTime.Format("20060102T150405")
 				sb.WriteString(fmt.Sprintf("DTEND;TZID=Europe/Stockholm:%s\r\n", dtend))
 			} else {
-				sb.WriteString("DURATION:PT1H\r\n")
+				sb.WriteString(fmt.Sprintf("DURATION:%s\r\n", icsDuration(s)))
 			}
-		} else if s.Time != nil && *s.Time != "" {
-			if startTime := parseStartTime(*s.Time); startTime != "" {
-				dtstart := strings.ReplaceAll(s.Date, "-", "") + "T" + startTime
-				sb.WriteString(fmt.Sprintf("DTSTART;TZID=Europe/Stockholm:%s\r\n", dtstart))
-				sb.WriteString("DURATION:PT1H\r\n")
+		} else if startTime != "" {
+			date := strings.ReplaceAll(s.Date, "-", "")
+			sb.WriteString(fmt.Sprintf("DTSTART;TZID=Europe/Stockholm:%sT%s\r\n", date, startTime))
+			// A same-day range such as "18:00 - 20:00" gives the end time
+			if endTime > startTime {
+				sb.WriteString(fmt.Sprintf("DTEND;TZID=Europe/Stockholm:%sT%s\r\n", date, endTime))
+			} else {
+				sb.WriteString(fmt.Sprintf("DURATION:%s\r\n", icsDuration(s)))
 			}
 		} else {
 			// All-day event
 			dtstart := strings.ReplaceAll(s.Date, "-", "")
 			sb.WriteString(fmt.Sprintf("DTSTART;VALUE=DATE:%s\r\n", dtstart))
+			// Multi-day: DTEND is exclusive, so it is the day after the last day
+			if end, err := time.Parse("2006-01-02", s.EndDate); err == nil {
+				sb.WriteString(fmt.Sprintf("DTEND;VALUE=DATE:%s\r\n", end.AddDate(0, 0, 1).Format("20060102")))
+			}
 		}
+		sb.WriteString(icsRecurrence(s))
 
-		// Summary (use short title if available, else full service name)
-		summaryText := s.ServiceName
-		if s.Title != "" {
-			summaryText = s.Title
-		}
-		summary := escapeICS(summaryText)
+		// Summary (use short title if available, else simplified service name)
+		summary := ical.Escape(icsSummary(s))
 		sb.WriteString(fmt.Sprintf("SUMMARY:%s\r\n", summary))
 
 		// Location
 		if s.Location != nil && *s.Location != "" {
-			location := escapeICS(*s.Location)
+			location := ical.Escape(*s.Location)
 			sb.WriteString(fmt.Sprintf("LOCATION:%s\r\n", location))
+
+			// Pin the event on a map when it is held at its source's known address
+			if src, ok := opts.sources[s.Source]; ok && src.Location == *s.Location && (src.Lat != 0 || src.Lng != 0) {
+				sb.WriteString(fmt.Sprintf("GEO:%s;%s\r\n",
+					strconv.FormatFloat(src.Lat, 'f', -1, 64), strconv.FormatFloat(src.Lng, 'f', -1, 64)))
+			}
 		}
 
 		// Description with additional details
 		var desc []string
-		desc = append(desc, fmt.Sprintf("Församling: %s", parishGroup(s)))
+		if s.ServiceType == "" {
+			desc = append(desc, fmt.Sprintf("Församling: %s", parishGroup(s)))
+		}
 		desc = append(desc, fmt.Sprintf("Beskrivning: %s", s.ServiceName))
+		if opts.lang != "" && s.DayOfWeek != "" {
+			label := "Dag"
+			if strings.EqualFold(opts.lang, "en") {
+				label = "Day"
+			}
+			desc = append(desc, fmt.Sprintf("%s: %s", label, dateutil.LocalizeWeekday(s.DayOfWeek, opts.lang)))
+		}
 		if s.EventLanguage != nil && *s.EventLanguage != "" {
 			desc = append(desc, fmt.Sprintf("Språk: %s", *s.EventLanguage))
 		} else if s.ParishLanguage != nil && *s.ParishLanguage != "" {
@@ -525,7 +995,7 @@ func generateICS(services []model.ChurchService) string {
 		if s.Occasion != nil && *s.Occasion != "" {
 			desc = append(desc, fmt.Sprintf("Tillfälle: %s", *s.Occasion))
 		}
-		if s.Notes != nil && *s.Notes != "" {
+		if s.Notes != nil && *s.Notes != "" && !opts.omitNotes {
 			desc = append(desc, fmt.Sprintf("Info: %s", *s.Notes))
 		}
 		if s.SourceURL != "" {
@@ -533,11 +1003,11 @@ func generateICS(services []model.ChurchService) string {
 		} else if s.Source != "" {
 			desc = append(desc, fmt.Sprintf("Källa: %s", s.Source))
 		}
-		description := escapeICS(strings.Join(desc, "\n"))
+		description := ical.Escape(strings.Join(desc, "\n"))
 		sb.WriteString(fmt.Sprintf("DESCRIPTION:%s\r\n", description))
 
 		// Categories
-		sb.WriteString(fmt.Sprintf("CATEGORIES:%s\r\n", escapeICS(parishGroup(s))))
+		sb.WriteString(fmt.Sprintf("CATEGORIES:%s\r\n", ical.Escape(icsCategory(s))))
 
 		// Timestamp
 		now := time.Now().UTC().Format("20060102T150405Z")
@@ -550,81 +1020,287 @@ func generateICS(services []model.ChurchService) string {
 	return sb.String()
 }
 
-func firstWebsite(p ParishInfo) string {
-	if len(p.Websites) > 0 {
-		return p.Websites[0]
+// icsSummary returns the SUMMARY text for a service. Untitled services whose
+// name is a compound of "gudstjänst" (Morgongudstjänst, Aftongudstjänst, ...)
+// are collapsed to plain "Gudstjänst"; the full name stays in the description.
+func icsSummary(s model.ChurchService) string {
+	if s.Title == "" && strings.HasSuffix(strings.ToLower(s.ServiceName), "gudstjänst") {
+		return "Gudstjänst"
 	}
-	return ""
+	return s.DisplayName()
 }
 
-// parishGroup returns the parish name, or "Övrigt" for services without a parish.
-func parishGroup(s model.ChurchService) string {
-	if s.Parish == "" {
-		return "Övrigt"
+// icsCategory returns the CATEGORIES value: the parish for services, or the
+// entry type for calendar entries such as fasts and commemorations.
+func icsCategory(s model.ChurchService) string {
+	switch s.ServiceType {
+	case model.ServiceTypeFast:
+		return "Fasta"
+	case model.ServiceTypeCommemoration:
+		return "Helgonminne"
 	}
-	return s.Parish
+	return parishGroup(s)
 }
 
-func escapeICS(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, ";", "\\;")
-	s = strings.ReplaceAll(s, ",", "\\,")
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	return s
-}
-
-// parseStartTime extracts the start time from a time string and returns it in HHMMSS format.
-// Handles formats like "18:00", "1800", "18:00 - 20:00", "1800 - ca 2000", etc.
-func parseStartTime(timeStr string) string {
-	// Remove any range part (everything after " - " or " – ")
-	timeStr = strings.Split(timeStr, " - ")[0]
-	timeStr = strings.Split(timeStr, " – ")[0]
-	timeStr = strings.TrimSpace(timeStr)
-
-	// Try to parse HH:MM format
-	if parts := strings.Split(timeStr, ":"); len(parts) >= 2 {
-		hour := strings.TrimSpace(parts[0])
-		minute := strings.TrimSpace(parts[1])
-		// Take only first 2 chars of minute in case there's extra stuff
-		if len(minute) > 2 {
-			minute = minute[:2]
-		}
-		if len(hour) <= 2 && len(minute) == 2 {
-			h := 0
-			m := 0
-			fmt.Sscanf(hour, "%d", &h)
-			fmt.Sscanf(minute, "%d", &m)
-			if h >= 0 && h <= 23 && m >= 0 && m <= 59 {
-				return fmt.Sprintf("%02d%02d00", h, m)
-			}
+// Liturgical service types used to look up durations.
+const (
+	serviceLiturgy = "liturgy"
+	serviceVespers = "vespers"
+	serviceMatins  = "matins"
+	serviceVigil   = "vigil"
+	serviceMoleben = "moleben"
+)
+
+// serviceDurations is the assumed length of each liturgical service type,
+// used for the ICS DURATION of services without an end time. Entries can be
+// overridden at startup with SetServiceDurations.
+var serviceDurations = map[string]time.Duration{
+	serviceLiturgy: 120 * time.Minute,
+	serviceVespers: 60 * time.Minute,
+	serviceMatins:  60 * time.Minute,
+	serviceVigil:   180 * time.Minute,
+	serviceMoleben: 45 * time.Minute,
+}
+
+const defaultServiceDuration = time.Hour
+
+// ServiceDuration returns the assumed length of a service of the given type
+// ("liturgy", "vespers", "matins", "vigil" or "moleben"). Other types last
+// an hour.
+func ServiceDuration(serviceType string) time.Duration {
+	if d, ok := serviceDurations[serviceType]; ok {
+		return d
+	}
+	return defaultServiceDuration
+}
+
+// SetServiceDurations overrides service durations from a comma-separated
+// list of type=duration pairs, e.g. "vigil=2h30m,moleben=30m". It rejects
+// unknown types and non-positive durations, leaving the durations unchanged.
+// Must be called before serving requests.
+func SetServiceDurations(spec string) error {
+	overrides := make(map[string]time.Duration)
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		serviceType, value, ok := strings.Cut(pair, "=")
+		serviceType = strings.TrimSpace(serviceType)
+		if !ok {
+			return fmt.Errorf("%q: want type=duration", pair)
+		}
+		if _, known := serviceDurations[serviceType]; !known {
+			return fmt.Errorf("unknown service type %q", serviceType)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%q: invalid duration", pair)
 		}
+		overrides[serviceType] = d
 	}
+	for serviceType, d := range overrides {
+		serviceDurations[serviceType] = d
+	}
+	return nil
+}
 
-	// Try to parse HHMM format (4 digits)
-	if len(timeStr) >= 4 {
-		// Check if first 4 chars are digits
-		candidate := timeStr[:4]
-		isDigits := true
-		for _, c := range candidate {
-			if c < '0' || c > '9' {
-				isDigits = false
-				break
-			}
+// serviceTypeKeywords maps lowercase name fragments to service types. The
+// order matters: "Vaka med liturgi" is a vigil, not a liturgy.
+var serviceTypeKeywords = []struct {
+	keyword     string
+	serviceType string
+}{
+	{"vaka", serviceVigil},
+	{"vigil", serviceVigil},
+	{"moleben", serviceMoleben},
+	{"bönegudstjänst", serviceMoleben},
+	{"liturgi", serviceLiturgy},
+	{"liturgy", serviceLiturgy},
+	{"λειτουργία", serviceLiturgy},
+	{"morgongudstjänst", serviceMatins},
+	{"orthros", serviceMatins},
+	{"matins", serviceMatins},
+	{"vesper", serviceVespers},
+	{"aftongudstjänst", serviceVespers},
+}
+
+// liturgicalType returns the normalized service type of s: its ServiceType
+// if set, otherwise one derived from the service name, or "" if unknown.
+func liturgicalType(s model.ChurchService) string {
+	if s.ServiceType != "" {
+		return strings.ToLower(s.ServiceType)
+	}
+	serviceType, _ := classifyServiceName(s.ServiceName)
+	return serviceType
+}
+
+// classifyServiceName returns the service type for a service name and the
+// keyword that matched, or empty strings if no keyword matches.
+func classifyServiceName(name string) (serviceType, keyword string) {
+	name = strings.ToLower(name)
+	for _, k := range serviceTypeKeywords {
+		if strings.Contains(name, k.keyword) {
+			return k.serviceType, k.keyword
 		}
-		if isDigits {
-			h := 0
-			m := 0
-			fmt.Sscanf(candidate[:2], "%d", &h)
-			fmt.Sscanf(candidate[2:], "%d", &m)
-			if h >= 0 && h <= 23 && m >= 0 && m <= 59 {
-				return candidate + "00"
-			}
+	}
+	return "", ""
+}
+
+// excludeServiceTypes drops services whose liturgicalType is in the
+// comma-separated list, e.g. "vespers,matins". Services of unknown type are
+// kept.
+func excludeServiceTypes(services []model.ChurchService, param string) []model.ChurchService {
+	excluded := make(map[string]bool)
+	for _, t := range strings.Split(param, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			excluded[t] = true
+		}
+	}
+	if len(excluded) == 0 {
+		return services
+	}
+	var filtered []model.ChurchService
+	for _, s := range services {
+		if t := liturgicalType(s); t == "" || !excluded[t] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// icsWeekdays are the RRULE BYDAY codes, indexed by time.Weekday.
+var icsWeekdays = [7]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// icsRecurrence returns the RRULE and EXDATE lines for a weekly recurring
+// service, or "" for a one-off service or one without a DTSTART.
+func icsRecurrence(s model.ChurchService) string {
+	r := s.Recurring
+	if r == nil || len(r.Weekdays) == 0 {
+		return ""
+	}
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		stockholm = time.UTC
+	}
+
+	// EXDATE and UNTIL must match DTSTART's value type
+	clock := ""
+	if s.StartTime != nil {
+		clock = s.StartTime.In(stockholm).Format("150405")
+	} else if s.Time != nil && *s.Time != "" {
+		if clock = ical.ParseStartTime(*s.Time); clock == "" {
+			return ""
+		}
+	}
+
+	days := make([]string, len(r.Weekdays))
+	for i, wd := range r.Weekdays {
+		days[i] = icsWeekdays[wd]
+	}
+	rule := "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	if until, err := time.ParseInLocation("2006-01-02", r.Until, stockholm); err == nil {
+		if clock == "" {
+			rule += ";UNTIL=" + until.Format("20060102")
+		} else {
+			// A local DTSTART needs UNTIL in UTC; the end of the last day is inclusive
+			rule += ";UNTIL=" + until.AddDate(0, 0, 1).Add(-time.Second).UTC().Format("20060102T150405Z")
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("RRULE:%s\r\n", rule))
+	for _, d := range r.ExDates {
+		date := strings.ReplaceAll(d, "-", "")
+		if clock == "" {
+			sb.WriteString(fmt.Sprintf("EXDATE;VALUE=DATE:%s\r\n", date))
+		} else {
+			sb.WriteString(fmt.Sprintf("EXDATE;TZID=Europe/Stockholm:%sT%s\r\n", date, clock))
+		}
+	}
+	return sb.String()
+}
+
+// icsDuration returns the ICS DURATION value (e.g. "PT2H", "PT45M") for a
+// service without an end time.
+func icsDuration(s model.ChurchService) string {
+	d := ServiceDuration(liturgicalType(s))
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	switch {
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	default:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	}
+}
+
+// oldCalendarSources are the sources of parishes on the Julian calendar,
+// whose fixed feasts fall 13 days after the Revised Julian dates.
+var oldCalendarSources = map[string]bool{
+	"Sankt Sava":                             true,
+	"Kristi Förklarings Ortodoxa Församling": true,
+}
+
+// isSundayOrFeast reports whether a YYYY-MM-DD date is a Sunday or one of
+// the great feasts in calendar.Feasts, kept on the old calendar if julian
+// is set.
+func isSundayOrFeast(date string, julian bool) bool {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+	if d.Weekday() == time.Sunday {
+		return true
+	}
+	feastOn := calendar.FeastOn
+	if julian {
+		feastOn = calendar.FeastOnJulian
+	}
+	_, ok := feastOn(d)
+	return ok
+}
+
+// includeExtras are include= values that opt in to non-service entries
+// rather than naming a parish.
+var includeExtras = map[string]bool{
+	"fasts":  true,
+	"saints": true,
+}
+
+// splitIncludeExtras separates opt-in extras (e.g. "fasts") from the legacy
+// include= parish whitelist. It returns the remaining parish list in the same
+// comma-separated form, and the set of requested extras.
+func splitIncludeExtras(includeParam string) (string, map[string]bool) {
+	extras := make(map[string]bool)
+	var rest []string
+	for _, v := range strings.Split(includeParam, ",") {
+		v = strings.TrimSpace(v)
+		if includeExtras[v] {
+			extras[v] = true
+		} else if v != "" {
+			rest = append(rest, v)
 		}
 	}
+	return strings.Join(rest, ","), extras
+}
 
+func firstWebsite(p ParishInfo) string {
+	if len(p.Websites) > 0 {
+		return p.Websites[0]
+	}
 	return ""
 }
 
+// parishGroup returns the parish name, or "Övrigt" for services without a parish.
+func parishGroup(s model.ChurchService) string {
+	if s.Parish == "" {
+		return "Övrigt"
+	}
+	return s.Parish
+}
+
 func langCategory(s model.ChurchService) string {
 	el := ""
 	if s.EventLanguage != nil {
@@ -672,10 +1348,10 @@ func filterAndSort(services []model.ChurchService) []model.ChurchService {
 		timeI := ""
 		timeJ := ""
 		if future[i].Time != nil {
-			timeI = parseStartTime(*future[i].Time)
+			timeI = ical.ParseStartTime(*future[i].Time)
 		}
 		if future[j].Time != nil {
-			timeJ = parseStartTime(*future[j].Time)
+			timeJ = ical.ParseStartTime(*future[j].Time)
 		}
 		return timeI < timeJ
 	})
@@ -752,12 +1428,57 @@ func (h *Handler) handleLastUpdated(w http.ResponseWriter, r *http.Request) {
 
 	batchID, err := h.fetcher.GetLatestBatchID(ctx)
 	if err != nil {
+		h.logFor(ctx).Error("fetching latest batch ID", "err", err)
 		http.Error(w, "Failed to fetch last updated", http.StatusInternalServerError)
 		return
 	}
 
+	result := map[string]string{"batch_id": batchID}
+	if generated, ok := batchTime(batchID); ok {
+		result["generated_at"] = generated.Format(time.RFC3339)
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]string{"batch_id": batchID})
+	json.NewEncoder(w).Encode(result)
+}
+
+// batchTime parses a batch ID written by the ingest job into the time the
+// batch ran.
+func batchTime(batchID string) (time.Time, bool) {
+	t, err := time.Parse(firestore.BatchIDLayout, batchID)
+	return t, err == nil
+}
+
+// dataGeneratedTTL is how long the latest batch time is reused before
+// Firestore is asked again. Ingestion runs a few times a day.
+const dataGeneratedTTL = 5 * time.Minute
+
+// setDataGenerated sets the X-Data-Generated header to when the latest
+// ingest batch ran, so clients can tell how fresh the data is. The header is
+// left out if the batch time is unknown.
+func (h *Handler) setDataGenerated(ctx context.Context, w http.ResponseWriter) {
+	if generated, ok := h.dataGenerated(ctx); ok {
+		w.Header().Set("X-Data-Generated", generated.Format(time.RFC3339))
+	}
+}
+
+// dataGenerated returns the time of the latest ingest batch, looked up at
+// most once per dataGeneratedTTL. After a failed lookup the last known time
+// is used and the next request tries again.
+func (h *Handler) dataGenerated(ctx context.Context) (time.Time, bool) {
+	h.generatedMu.Lock()
+	defer h.generatedMu.Unlock()
+
+	if !h.generatedCheckedAt.IsZero() && time.Since(h.generatedCheckedAt) < dataGeneratedTTL {
+		return h.generated, !h.generated.IsZero()
+	}
+	batchID, err := h.fetcher.GetLatestBatchID(ctx)
+	if err != nil {
+		return h.generated, !h.generated.IsZero()
+	}
+	h.generated, _ = batchTime(batchID)
+	h.generatedCheckedAt = time.Now()
+	return h.generated, !h.generated.IsZero()
 }
 
 func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -777,11 +1498,11 @@ func (h *Handler) handleReloadParishes(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 	if err := h.parishReloader.ReloadParishes(ctx); err != nil {
-		log.Printf("ERROR: reloading parishes: %v", err)
+		h.logFor(ctx).Error("reloading parishes", "err", err)
 		http.Error(w, "Failed to reload parishes", http.StatusInternalServerError)
 		return
 	}
-	log.Printf("Parishes reloaded: %d parishes", len(parishes))
+	h.logFor(ctx).Info("parishes reloaded", "count", len(parishes))
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Reloaded %d parishes\n", len(parishes))
 }
@@ -1123,6 +1844,11 @@ func (h *Handler) handleEvent(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, data)
 }
 
+// maxFeedbackMessageRunes is the longest feedback message accepted.
+const maxFeedbackMessageRunes = 5000
+
+var stripNewlines = strings.NewReplacer("\r", "", "\n", "")
+
 func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		tmpl, err := parseWithTheme("feedback.html")
@@ -1146,7 +1872,13 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 			Timestamp int64  `json:"timestamp"` // Form load timestamp
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
+		// Check the raw body: the JSON decoder would silently replace invalid UTF-8
+		body, err := io.ReadAll(r.Body)
+		if err != nil || !utf8.Valid(body) {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(body, &feedback); err != nil {
 			http.Error(w, "Invalid request", http.StatusBadRequest)
 			return
 		}
@@ -1169,7 +1901,7 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Rate limiting check
-		clientIP := getClientIP(r)
+		clientIP := getClientIP(r, h.trustedProxies)
 		if !h.rateLimiter.allow(clientIP) {
 			http.Error(w, "För många förfrågningar. Försök igen senare.", http.StatusTooManyRequests)
 			return
@@ -1179,10 +1911,18 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Type and message are required", http.StatusBadRequest)
 			return
 		}
+		if utf8.RuneCountInString(feedback.Message) > maxFeedbackMessageRunes {
+			http.Error(w, fmt.Sprintf("Message is longer than %d characters", maxFeedbackMessageRunes), http.StatusBadRequest)
+			return
+		}
+
+		// Type ends up in the subject; keep both single-line
+		feedback.Type = stripNewlines.Replace(feedback.Type)
+		feedback.Email = stripNewlines.Replace(feedback.Email)
 
 		// Send email notification
 		if err := h.sendFeedbackEmail(feedback.Type, feedback.Email, feedback.Message); err != nil {
-			log.Printf("Failed to send feedback email: %v", err)
+			h.logFor(r.Context()).Error("sending feedback email", "err", err)
 			http.Error(w, "Failed to send feedback", http.StatusInternalServerError)
 			return
 		}
@@ -1226,31 +1966,47 @@ func (h *Handler) handlePrivacy(w http.ResponseWriter, r *http.Request) {
 
 func redirect(target string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		url := target
+		if r.URL.RawQuery != "" {
+			url += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, url, http.StatusMovedPermanently)
 	}
 }
 
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (set by proxies/load balancers)
-	// Use the last IP — on Cloud Run the proxy appends the real client IP
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		parts := strings.Split(xff, ",")
-		return strings.TrimSpace(parts[len(parts)-1])
-	}
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
+// getClientIP returns the address of the client that sent r. Each trusted
+// proxy appends the address it received the request from to X-Forwarded-For,
+// so the client is trustedProxies entries from the right; anything further
+// left was supplied by the client and may be spoofed. Malformed entries are
+// skipped. A proxy may instead set X-Real-IP. Both headers are ignored
+// without trusted proxies, since the client could have set them; then, or
+// without a usable header, the connection's RemoteAddr is used.
+func getClientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var ips []string
+		for _, part := range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
+			if ip := net.ParseIP(strings.TrimSpace(part)); ip != nil {
+				ips = append(ips, ip.String())
+			}
+		}
+		if len(ips) > 0 {
+			// A shorter chain was written entirely by trusted proxies
+			return ips[max(len(ips)-trustedProxies, 0)]
+		}
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+			return ip.String()
+		}
 	}
-	// Fall back to RemoteAddr
-	if idx := strings.LastIndex(r.RemoteAddr, ":"); idx != -1 {
-		return r.RemoteAddr[:idx]
+	// Fall back to RemoteAddr, which may be "host:port", "[v6]:port" or a bare host
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
 	}
-	return r.RemoteAddr
+	return strings.TrimSuffix(strings.TrimPrefix(r.RemoteAddr, "["), "]")
 }
 
 func (h *Handler) sendFeedbackEmail(feedbackType, senderEmail, message string) error {
-	if h.smtp == nil {
-		return fmt.Errorf("SMTP not configured")
+	if h.mail == nil {
+		return fmt.Errorf("email not configured")
 	}
 
 	typeLabels := map[string]string{
@@ -1265,14 +2021,25 @@ func (h *Handler) sendFeedbackEmail(feedbackType, senderEmail, message string) e
 		typeLabel = feedbackType
 	}
 
-	replyTo := senderEmail
-	if replyTo == "" {
-		replyTo = "ingen e-post angiven"
+	from := senderEmail
+	if from == "" {
+		from = "ingen e-post angiven"
 	}
 
-	subject := fmt.Sprintf("Feedback: %s", typeLabel)
-	body := fmt.Sprintf("Typ: %s\r\nFrån: %s\r\n\r\nMeddelande:\r\n%s", typeLabel, replyTo, email.NormalizeNewlines(message))
+	// Replies go to the submitter, if they left a usable address
+	var replyTo string
+	if addr, err := mail.ParseAddress(senderEmail); err == nil {
+		replyTo = addr.Address
+	}
 
-	return h.smtp.Send(subject, body)
+	esc := template.HTMLEscapeString
+	return h.mail.SendMessage(email.Message{
+		Subject: fmt.Sprintf("Feedback: %s", typeLabel),
+		Body:    fmt.Sprintf("Typ: %s\nFrån: %s\n\nMeddelande:\n%s", typeLabel, from, message),
+		HTML: fmt.Sprintf("<p><b>Typ:</b> %s<br>\n<b>Från:</b> %s</p>\n<p>%s</p>\n",
+			esc(typeLabel), esc(from), strings.ReplaceAll(esc(email.NormalizeNewlines(message)), "\r\n", "<br>\r\n")),
+		ReplyTo: replyTo,
+		To:      h.feedbackTo,
+	})
 }
 
@@ -3,15 +3,29 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"ortodoxa-gudstjanster/internal/calendar"
+	"ortodoxa-gudstjanster/internal/email"
+	"ortodoxa-gudstjanster/internal/firestore"
+	"ortodoxa-gudstjanster/internal/ical"
+	"ortodoxa-gudstjanster/internal/logging"
 	"ortodoxa-gudstjanster/internal/model"
+	"ortodoxa-gudstjanster/internal/store"
 	"ortodoxa-gudstjanster/internal/umap"
 )
 
@@ -28,6 +42,8 @@ type mockFetcher struct {
 	services []model.ChurchService
 	batchID  string
 	err      error
+
+	batchIDCalls int
 }
 
 func (m *mockFetcher) GetAllServices(ctx context.Context) ([]model.ChurchService, error) {
@@ -44,68 +60,12 @@ func (m *mockFetcher) GetServiceByID(ctx context.Context, id string) (*model.Chu
 }
 
 func (m *mockFetcher) GetLatestBatchID(ctx context.Context) (string, error) {
+	m.batchIDCalls++
 	return m.batchID, m.err
 }
 
 func ptr(s string) *string { return &s }
 
-// --- parseStartTime ---
-
-func TestParseStartTime(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"18:00", "180000"},
-		{"9:30", "093000"},
-		{"18:00 - 20:00", "180000"},
-		{"18:00 – 20:00", "180000"},
-		{"1800", "180000"},
-		{"1800 - ca 2000", "180000"},
-		{"08:30", "083000"},
-		{"", ""},
-		{"TBD", ""},
-		// Invalid values should be rejected
-		{"99:99", ""},
-		{"25:00", ""},
-		{"12:60", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := parseStartTime(tt.input)
-			if got != tt.want {
-				t.Errorf("parseStartTime(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
-// --- escapeICS ---
-
-func TestEscapeICS(t *testing.T) {
-	tests := []struct {
-		input string
-		want  string
-	}{
-		{"simple text", "simple text"},
-		{"semi;colon", "semi\\;colon"},
-		{"com,ma", "com\\,ma"},
-		{"new\nline", "new\\nline"},
-		{"back\\slash", "back\\\\slash"},
-		{"all;of,them\nhere\\now", "all\\;of\\,them\\nhere\\\\now"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := escapeICS(tt.input)
-			if got != tt.want {
-				t.Errorf("escapeICS(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
 // --- langCategory ---
 
 func TestLangCategory(t *testing.T) {
@@ -168,37 +128,117 @@ func TestGetClientIP(t *testing.T) {
 		name       string
 		xff        string
 		xri        string
+		proxies    int
 		remoteAddr string
 		want       string
 	}{
 		{
 			name:       "X-Forwarded-For single IP",
 			xff:        "1.2.3.4",
+			proxies:    1,
 			remoteAddr: "5.6.7.8:1234",
 			want:       "1.2.3.4",
 		},
 		{
 			name:       "X-Forwarded-For multiple IPs uses last",
 			xff:        "1.2.3.4, 10.0.0.1, 9.8.7.6",
+			proxies:    1,
 			remoteAddr: "5.6.7.8:1234",
 			want:       "9.8.7.6",
 		},
 		{
 			name:       "X-Real-IP fallback",
 			xri:        "1.2.3.4",
+			proxies:    1,
 			remoteAddr: "5.6.7.8:1234",
 			want:       "1.2.3.4",
 		},
 		{
 			name:       "RemoteAddr fallback",
+			proxies:    1,
 			remoteAddr: "5.6.7.8:1234",
 			want:       "5.6.7.8",
 		},
 		{
 			name:       "RemoteAddr without port",
+			proxies:    1,
 			remoteAddr: "5.6.7.8",
 			want:       "5.6.7.8",
 		},
+		{
+			name:       "spoofed leading IP is ignored",
+			xff:        "6.6.6.6, 1.2.3.4",
+			proxies:    1,
+			remoteAddr: "169.254.1.1:1234",
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "multi-proxy chain",
+			xff:        "6.6.6.6, 1.2.3.4, 10.0.0.1",
+			proxies:    2,
+			remoteAddr: "169.254.1.1:1234",
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "chain shorter than the proxy count",
+			xff:        "1.2.3.4",
+			proxies:    2,
+			remoteAddr: "169.254.1.1:1234",
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "malformed entries are skipped",
+			xff:        "1.2.3.4, not-an-ip, ",
+			proxies:    1,
+			remoteAddr: "5.6.7.8:1234",
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "X-Forwarded-For ignored without trusted proxies",
+			xff:        "1.2.3.4",
+			proxies:    0,
+			remoteAddr: "5.6.7.8:1234",
+			want:       "5.6.7.8",
+		},
+		{
+			name:       "X-Real-IP ignored without trusted proxies",
+			xri:        "1.2.3.4",
+			proxies:    0,
+			remoteAddr: "5.6.7.8:1234",
+			want:       "5.6.7.8",
+		},
+		{
+			name:       "malformed X-Forwarded-For falls back to RemoteAddr",
+			xff:        "garbage",
+			proxies:    1,
+			remoteAddr: "5.6.7.8:1234",
+			want:       "5.6.7.8",
+		},
+		{
+			name:       "IPv6 RemoteAddr",
+			proxies:    1,
+			remoteAddr: "[2001:db8::1]:443",
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "bare IPv6 RemoteAddr",
+			proxies:    1,
+			remoteAddr: "::1",
+			want:       "::1",
+		},
+		{
+			name:       "bracketed IPv6 RemoteAddr without port",
+			proxies:    1,
+			remoteAddr: "[2001:db8::1]",
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "IPv6 in X-Forwarded-For",
+			xff:        "2001:db8::2",
+			proxies:    1,
+			remoteAddr: "[2001:db8::1]:443",
+			want:       "2001:db8::2",
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,7 +252,7 @@ func TestGetClientIP(t *testing.T) {
 			}
 			r.RemoteAddr = tt.remoteAddr
 
-			got := getClientIP(r)
+			got := getClientIP(r, tt.proxies)
 			if got != tt.want {
 				t.Errorf("getClientIP() = %q, want %q", got, tt.want)
 			}
@@ -222,6 +262,41 @@ func TestGetClientIP(t *testing.T) {
 
 // --- rateLimiter ---
 
+func TestFeedbackRateLimitOption(t *testing.T) {
+	h := New(&mockFetcher{}, WithFeedbackRateLimit(1, time.Hour))
+	h.SetMail(&email.NoopSender{}, []string{"admin@example.com"})
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	h.rateLimiter.now = func() time.Time { return now }
+
+	post := func(ip string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"type":"other","message":"hej"}`))
+		r.RemoteAddr = ip + ":5678"
+		h.handleFeedback(w, r)
+		return w.Code
+	}
+
+	if code := post("1.2.3.4"); code != http.StatusOK {
+		t.Fatalf("first submission: status = %d, want 200", code)
+	}
+	if code := post("1.2.3.4"); code != http.StatusTooManyRequests {
+		t.Errorf("second submission within the window: status = %d, want 429", code)
+	}
+
+	now = now.Add(time.Hour + time.Second)
+
+	// The next submission sweeps the stale IP's entry
+	if code := post("5.6.7.8"); code != http.StatusOK {
+		t.Fatalf("other IP: status = %d, want 200", code)
+	}
+	if n := h.rateLimiter.trackedIPs(); n != 1 {
+		t.Errorf("tracked IPs = %d, want 1 after the stale entry is removed", n)
+	}
+	if code := post("1.2.3.4"); code != http.StatusOK {
+		t.Errorf("after the window: status = %d, want 200", code)
+	}
+}
+
 func TestRateLimiter(t *testing.T) {
 	rl := newRateLimiter(2, time.Hour)
 
@@ -326,7 +401,7 @@ func TestFilterAndSort(t *testing.T) {
 	}
 }
 
-// --- generateICS ---
+// --- GenerateICS ---
 
 func TestGenerateICS(t *testing.T) {
 	loc := "Stockholm"
@@ -343,7 +418,7 @@ func TestGenerateICS(t *testing.T) {
 		},
 	}
 
-	ics := generateICS(services)
+	ics := GenerateICS(services)
 
 	checks := []string{
 		"BEGIN:VCALENDAR",
@@ -353,7 +428,7 @@ func TestGenerateICS(t *testing.T) {
 		"SUMMARY:Helig Liturgi",
 		"LOCATION:Stockholm",
 		"DTSTART;TZID=Europe/Stockholm:20260308T100000",
-		"DURATION:PT1H",
+		"DURATION:PT2H",
 		"Församling: Test Parish",
 		"VERSION:2.0",
 	}
@@ -365,6 +440,74 @@ func TestGenerateICS(t *testing.T) {
 	}
 }
 
+func TestGenerateICSServiceDurations(t *testing.T) {
+	tests := []struct {
+		serviceName string
+		want        string
+	}{
+		{"Helig Liturgi", "DURATION:PT2H"},
+		{"Θεία Λειτουργία", "DURATION:PT2H"},
+		{"Vesper", "DURATION:PT1H"},
+		{"Aftongudstjänst", "DURATION:PT1H"},
+		{"Morgongudstjänst", "DURATION:PT1H"},
+		{"Helnattsvaka", "DURATION:PT3H"},
+		{"Vaka med liturgi", "DURATION:PT3H"},
+		{"Moleben till Guds Moder", "DURATION:PT45M"},
+		{"Kyrkkaffe", "DURATION:PT1H"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceName, func(t *testing.T) {
+			ics := GenerateICS([]model.ChurchService{
+				{Source: "Test", Date: "2026-03-08", ServiceName: tt.serviceName, Time: ptr("10:00")},
+			})
+			if !strings.Contains(ics, tt.want+"\r\n") {
+				t.Errorf("ICS for %q missing %q", tt.serviceName, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetServiceDurations(t *testing.T) {
+	orig := serviceDurations[serviceMatins]
+	t.Cleanup(func() { serviceDurations[serviceMatins] = orig })
+
+	if err := SetServiceDurations("matins = 1h30m, "); err != nil {
+		t.Fatalf("SetServiceDurations: %v", err)
+	}
+	if got := icsDuration(model.ChurchService{ServiceName: "Morgongudstjänst"}); got != "PT1H30M" {
+		t.Errorf("icsDuration = %q, want PT1H30M", got)
+	}
+	if got := ServiceDuration("kyrkkaffe"); got != time.Hour {
+		t.Errorf("ServiceDuration(kyrkkaffe) = %v, want the 1h default", got)
+	}
+
+	for _, spec := range []string{"matins=2h,kyrkkaffe=1h", "matins", "matins=0s", "matins=long"} {
+		if err := SetServiceDurations(spec); err == nil {
+			t.Errorf("SetServiceDurations(%q) should fail", spec)
+		}
+	}
+	if got := ServiceDuration(serviceMatins); got != 90*time.Minute {
+		t.Errorf("rejected specs changed matins to %v", got)
+	}
+}
+
+func TestGenerateICSTimeRange(t *testing.T) {
+	services := []model.ChurchService{
+		{Parish: "Test", Source: "Test", Date: "2026-03-08", ServiceName: "Vesper", Time: ptr("kl. 18.00 - ca 20.00")},
+	}
+
+	ics := GenerateICS(services)
+
+	if !strings.Contains(ics, "DTSTART;TZID=Europe/Stockholm:20260308T180000\r\n") ||
+		!strings.Contains(ics, "DTEND;TZID=Europe/Stockholm:20260308T200000\r\n") {
+		t.Errorf("time range should give DTSTART and DTEND:\n%s", ics)
+	}
+	if strings.Contains(ics, "DURATION:") {
+		t.Error("event with an end time should not have a DURATION")
+	}
+}
+
 func TestGenerateICSAllDayEvent(t *testing.T) {
 	services := []model.ChurchService{
 		{
@@ -375,11 +518,17 @@ func TestGenerateICSAllDayEvent(t *testing.T) {
 		},
 	}
 
-	ics := generateICS(services)
+	ics := GenerateICS(services)
 
 	if !strings.Contains(ics, "DTSTART;VALUE=DATE:20260308") {
 		t.Error("all-day event should use VALUE=DATE format")
 	}
+
+	// An unparseable time also makes an all-day event
+	services[0].Time = ptr("efter liturgin")
+	if ics := GenerateICS(services); !strings.Contains(ics, "DTSTART;VALUE=DATE:20260308") {
+		t.Errorf("service with unparseable time should be all-day:\n%s", ics)
+	}
 }
 
 func TestGenerateICSSummarySimplification(t *testing.T) {
@@ -407,8 +556,8 @@ func TestGenerateICSSummarySimplification(t *testing.T) {
 					Title:       tt.title,
 				},
 			}
-			ics := generateICS(services)
-			expected := "SUMMARY:" + escapeICS(tt.wantSummary)
+			ics := GenerateICS(services)
+			expected := "SUMMARY:" + ical.Escape(tt.wantSummary)
 			if !strings.Contains(ics, expected) {
 				t.Errorf("expected SUMMARY to contain %q, got ICS:\n%s", expected, ics)
 			}
@@ -433,6 +582,153 @@ func TestHandleHealth(t *testing.T) {
 	}
 }
 
+func TestHandleHealthz(t *testing.T) {
+	fresh := time.Now().UTC().Add(-3 * time.Hour).Format("20060102-150405")
+	stale := time.Now().UTC().Add(-3 * 24 * time.Hour).Format("20060102-150405")
+
+	tests := []struct {
+		name        string
+		fetcher     *mockFetcher
+		wantStatus  int
+		wantFailing string
+	}{
+		{"healthy", &mockFetcher{batchID: fresh}, http.StatusOK, ""},
+		{"all scrapers stale", &mockFetcher{batchID: stale}, http.StatusServiceUnavailable, "data: latest batch " + stale},
+		{"no batches", &mockFetcher{}, http.StatusServiceUnavailable, "data: no ingest batch"},
+		{"Firestore down", &mockFetcher{err: fmt.Errorf("unavailable")}, http.StatusServiceUnavailable, "data: fetching latest batch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := store.NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := New(tt.fetcher)
+			h.SetPublishedStore(st)
+
+			w := httptest.NewRecorder()
+			h.handleHealthz(w, httptest.NewRequest("GET", "/healthz", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var report healthReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if keys, _ := st.List(""); len(keys) != 0 {
+				t.Errorf("the probe should not write to the store, found %q", keys)
+			}
+			if tt.wantFailing == "" {
+				if report.Status != "ok" || len(report.Failing) != 0 {
+					t.Errorf("report = %+v, want ok", report)
+				}
+				return
+			}
+			if report.Status != "degraded" || len(report.Failing) != 1 || !strings.HasPrefix(report.Failing[0], tt.wantFailing) {
+				t.Errorf("report = %+v, want one failure starting %q", report, tt.wantFailing)
+			}
+		})
+	}
+}
+
+// failingStore is a store whose reads fail, as when GCS is unreachable.
+type failingStore struct {
+	store.Store
+}
+
+func (failingStore) List(prefix string) ([]string, error) {
+	return nil, fmt.Errorf("storage: bucket unreachable")
+}
+
+// countingStore counts the reads and listings made of a store.
+type countingStore struct {
+	store.Store
+	gets  int
+	lists int
+}
+
+func (s *countingStore) Get(key string) ([]byte, bool) {
+	s.gets++
+	return s.Store.Get(key)
+}
+
+func (s *countingStore) List(prefix string) ([]string, error) {
+	s.lists++
+	return s.Store.List(prefix)
+}
+
+func TestHandleReady(t *testing.T) {
+	stale := time.Now().UTC().Add(-3 * 24 * time.Hour).Format("20060102-150405")
+	healthy, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		fetcher     *mockFetcher
+		store       store.Store
+		wantStatus  int
+		wantFailing []string
+	}{
+		{"ready with stale data", &mockFetcher{batchID: stale}, healthy, http.StatusOK, nil},
+		{"ready without a store", &mockFetcher{batchID: stale}, nil, http.StatusOK, nil},
+		{"store probe fails", &mockFetcher{batchID: stale}, failingStore{}, http.StatusServiceUnavailable,
+			[]string{"store: listing probe: storage: bucket unreachable"}},
+		{"everything down", &mockFetcher{err: fmt.Errorf("unavailable")}, failingStore{}, http.StatusServiceUnavailable,
+			[]string{"firestore: unavailable", "store: listing probe: storage: bucket unreachable"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(tt.fetcher)
+			if tt.store != nil {
+				h.SetPublishedStore(tt.store)
+			}
+
+			w := httptest.NewRecorder()
+			h.handleReady(w, httptest.NewRequest("GET", "/health/ready", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var report healthReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if !reflect.DeepEqual(report.Failing, tt.wantFailing) {
+				t.Errorf("failing = %q, want %q", report.Failing, tt.wantFailing)
+			}
+		})
+	}
+}
+
+func TestHealthChecksShareStoreProbe(t *testing.T) {
+	local, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &countingStore{Store: local}
+	h := New(&mockFetcher{batchID: time.Now().UTC().Format("20060102-150405")})
+	h.SetPublishedStore(st)
+
+	for i := 0; i < 3; i++ {
+		h.handleHealthz(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+		h.handleReady(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/ready", nil))
+	}
+	if st.lists != 1 {
+		t.Errorf("store listed %d times, want 1 within storeProbeTTL", st.lists)
+	}
+
+	h.storeProbeCheckedAt = time.Now().Add(-storeProbeTTL)
+	h.handleReady(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/ready", nil))
+	if st.lists != 2 {
+		t.Errorf("store listed %d times, want 2 once the probe expired", st.lists)
+	}
+}
+
 func TestHandleServices(t *testing.T) {
 	today := time.Now().Format("2006-01-02")
 	fetcher := &mockFetcher{
@@ -521,92 +817,1113 @@ func TestHandleICSExcludeLangFilter(t *testing.T) {
 	}
 }
 
-func TestHandleIndexNotFound(t *testing.T) {
-	h := New(&mockFetcher{})
-	w := httptest.NewRecorder()
-	r := httptest.NewRequest("GET", "/nonexistent", nil)
+func TestGenerateICSMultiDayAllDayEvent(t *testing.T) {
+	services := []model.ChurchService{
+		{
+			Date:        "2026-02-23",
+			EndDate:     "2026-04-11",
+			ServiceName: "Stora fastan",
+			ServiceType: model.ServiceTypeFast,
+		},
+	}
 
-	h.handleIndex(w, r)
+	ics := GenerateICS(services)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	if !strings.Contains(ics, "DTSTART;VALUE=DATE:20260223") {
+		t.Error("multi-day event should start on its first day")
+	}
+	if !strings.Contains(ics, "DTEND;VALUE=DATE:20260412") {
+		t.Error("multi-day event DTEND should be the day after its last day")
+	}
+	if !strings.Contains(ics, "CATEGORIES:Fasta") {
+		t.Error("fast should be categorized as Fasta")
+	}
+	if strings.Contains(ics, "Församling:") {
+		t.Error("fast should not list a parish")
 	}
 }
 
-func TestHandleFeedbackGet(t *testing.T) {
-	h := New(&mockFetcher{})
+func TestHandleICSIncludeFasts(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	fetcher := &mockFetcher{
+		services: []model.ChurchService{
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: today, ServiceName: "A"},
+		},
+	}
+	h := New(fetcher)
+
+	// Without include=fasts there are no fasts
 	w := httptest.NewRecorder()
-	r := httptest.NewRequest("GET", "/feedback", nil)
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics", nil))
+	if strings.Contains(w.Body.String(), "CATEGORIES:Fasta") {
+		t.Error("fasts should be off by default")
+	}
 
-	h.handleFeedback(w, r)
+	// include=fasts adds fasts without being treated as a parish whitelist
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?include=fasts", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "CATEGORIES:Fasta") {
+		t.Error("include=fasts should add fasting periods")
+	}
+	if !strings.Contains(body, "CATEGORIES:St. Georgios Cathedral") {
+		t.Error("include=fasts alone should keep the default parish selection")
+	}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	// Combined with a legacy parish whitelist
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?include=Sankt+G%C3%B6ran,fasts", nil))
+	body = w.Body.String()
+	if strings.Contains(body, "CATEGORIES:St. Georgios Cathedral") {
+		t.Error("parish whitelist should still apply alongside fasts")
 	}
-	if !strings.Contains(w.Header().Get("Content-Type"), "text/html") {
-		t.Errorf("Content-Type = %q, want text/html", w.Header().Get("Content-Type"))
+	if !strings.Contains(body, "CATEGORIES:Fasta") {
+		t.Error("fasts should be included alongside a parish whitelist")
 	}
 }
 
-func TestHandleFeedbackPostHoneypot(t *testing.T) {
+func TestHandleICSIncludeSaints(t *testing.T) {
 	h := New(&mockFetcher{})
-	w := httptest.NewRecorder()
-	body := `{"type":"error","message":"test","website":"http://spam.com"}`
-	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
 
-	h.handleFeedback(w, r)
+	w := httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics", nil))
+	if strings.Contains(w.Body.String(), "CATEGORIES:Helgonminne") {
+		t.Error("commemorations should be off by default")
+	}
 
-	// Should silently accept (200) but not actually send
-	if w.Code != http.StatusOK {
-		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?include=saints", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "CATEGORIES:Helgonminne") {
+		t.Error("include=saints should add commemorations")
+	}
+	if !strings.Contains(body, "DTSTART;VALUE=DATE:") {
+		t.Error("commemorations should be all-day events")
+	}
+	if strings.Contains(body, "Församling:") {
+		t.Error("commemorations should not name a parish")
 	}
 }
 
-func TestHandleFeedbackPostMissingFields(t *testing.T) {
-	h := New(&mockFetcher{})
-	h.SetSMTP(nil) // no SMTP
-	w := httptest.NewRecorder()
-	body := `{"type":"","message":"","timestamp":1000}`
-	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
-	r.RemoteAddr = "1.2.3.4:5678"
-
-	h.handleFeedback(w, r)
+func TestHandleICSFeastsOnly(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// The next great feast that is not a Sunday, and an ordinary weekday
+	var feast, weekday string
+	for d := today; feast == "" || weekday == ""; d = d.AddDate(0, 0, 1) {
+		_, isFeast := calendar.FeastOn(d)
+		switch {
+		case d.Weekday() == time.Sunday:
+		case isFeast && feast == "":
+			feast = d.Format("2006-01-02")
+		case !isFeast && weekday == "":
+			weekday = d.Format("2006-01-02")
+		}
+	}
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	fetcher := &mockFetcher{
+		services: []model.ChurchService{
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: feast, ServiceName: "Feast Liturgy", Time: ptr("10:00")},
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: weekday, ServiceName: "Weekday Vespers", Time: ptr("18:00")},
+		},
 	}
-}
+	h := New(fetcher)
 
-func TestHandleFeedbackPostOversizedBody(t *testing.T) {
-	h := New(&mockFetcher{})
 	w := httptest.NewRecorder()
-	// Valid JSON with a very large message field (2MB)
-	bigMsg := strings.Repeat("a", 2*1024*1024)
-	body := fmt.Sprintf(`{"type":"error","message":"%s","timestamp":1000}`, bigMsg)
-	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
-	r.RemoteAddr = "1.2.3.4:5678"
-
-	h.handleFeedback(w, r)
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.%20Georgios%20Cathedral&feasts_only=1", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Feast Liturgy") {
+		t.Errorf("feasts_only should keep the service on %s", feast)
+	}
+	if strings.Contains(body, "Weekday Vespers") {
+		t.Errorf("feasts_only should drop the service on %s", weekday)
+	}
 
-	// Should be rejected — either 400 (bad request) or 413 (too large)
-	if w.Code == http.StatusOK || w.Code == http.StatusInternalServerError {
-		t.Errorf("oversized body should be rejected, got status %d", w.Code)
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.%20Georgios%20Cathedral", nil))
+	if !strings.Contains(w.Body.String(), "Weekday Vespers") {
+		t.Error("weekday services should be included without feasts_only")
 	}
 }
 
-func TestHandleFeedbackMethodNotAllowed(t *testing.T) {
-	h := New(&mockFetcher{})
-	w := httptest.NewRecorder()
-	r := httptest.NewRequest("PUT", "/feedback", nil)
-
-	h.handleFeedback(w, r)
-
-	if w.Code != http.StatusMethodNotAllowed {
-		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+func TestHandleICSFeastsOnlyOldCalendar(t *testing.T) {
+	// Transfiguration: August 6 on the Revised Julian calendar, August 19 on
+	// the old one. Neither is a Sunday in 2027.
+	fetcher := &mockFetcher{
+		services: []model.ChurchService{
+			{Parish: "Sankt Sava", Source: "Sankt Sava", Date: "2027-08-19", ServiceName: "Preobrazhenje Liturgy", Time: ptr("10:00")},
+			{Parish: "Sankt Sava", Source: "Sankt Sava", Date: "2027-08-06", ServiceName: "Friday Vespers", Time: ptr("18:00")},
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: "2027-08-06", ServiceName: "Metamorphosis Liturgy", Time: ptr("10:00")},
+		},
 	}
-}
+	h := New(fetcher)
 
-func TestHandleLastUpdated(t *testing.T) {
+	w := httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=Sankt%20Sava,St.%20Georgios%20Cathedral&feasts_only=1", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Preobrazhenje Liturgy") {
+		t.Error("feasts_only should keep Sankt Sava's service on the old-calendar Transfiguration")
+	}
+	if strings.Contains(body, "Friday Vespers") {
+		t.Error("feasts_only should drop Sankt Sava's service on the new-calendar date")
+	}
+	if !strings.Contains(body, "Metamorphosis Liturgy") {
+		t.Error("feasts_only should keep the new-calendar parish's service on August 6")
+	}
+}
+
+func TestHandleICSCertainOnly(t *testing.T) {
+	date := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	fetcher := &mockFetcher{
+		services: []model.ChurchService{
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: date, ServiceName: "Clear Liturgy", Time: ptr("10:00")},
+			{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: date, ServiceName: "Blurry Vespers", Time: ptr("18:00"), Uncertain: true},
+		},
+	}
+	h := New(fetcher)
+
+	w := httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.%20Georgios%20Cathedral&certain_only=1", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Clear Liturgy") || strings.Contains(body, "Blurry Vespers") {
+		t.Errorf("certain_only should drop only the uncertain service:\n%s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.%20Georgios%20Cathedral", nil))
+	if !strings.Contains(w.Body.String(), "Blurry Vespers") {
+		t.Error("uncertain services should be included by default")
+	}
+}
+
+func TestIsSundayOrFeast(t *testing.T) {
+	tests := []struct {
+		date   string
+		julian bool
+		want   bool
+	}{
+		{"2026-03-08", false, true},  // Sunday
+		{"2026-03-25", false, true},  // Annunciation, a Wednesday
+		{"2026-03-26", false, false}, // ordinary Thursday
+		{"not a date", false, false},
+		{"2026-04-07", true, true},  // Annunciation on the old calendar, a Tuesday
+		{"2026-03-25", true, false}, // not yet the Annunciation on the old calendar
+		{"2027-01-07", true, true},  // Nativity on the old calendar, a Thursday
+		{"2026-12-25", true, false},
+	}
+	for _, tt := range tests {
+		if got := isSundayOrFeast(tt.date, tt.julian); got != tt.want {
+			t.Errorf("isSundayOrFeast(%q, %v) = %v, want %v", tt.date, tt.julian, got, tt.want)
+		}
+	}
+}
+
+func TestHandleServicesPretty(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	h := New(&mockFetcher{services: []model.ChurchService{
+		{Source: "Test", Date: today, ServiceName: "Liturgi"},
+	}})
+
+	w := httptest.NewRecorder()
+	h.handleServices(w, httptest.NewRequest("GET", "/api/services", nil))
+	if strings.Contains(w.Body.String(), "\n  ") {
+		t.Errorf("default output should be compact:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.handleServices(w, httptest.NewRequest("GET", "/api/services?pretty=1", nil))
+	if !strings.HasPrefix(w.Body.String(), "[\n  {\n    \"") {
+		t.Errorf("pretty=1 output should be indented:\n%s", w.Body.String())
+	}
+}
+
+func TestHandleServicesFields(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	h := New(&mockFetcher{services: []model.ChurchService{
+		{Source: "Test", Date: today, ServiceName: "Liturgi", Time: ptr("10:00"), Location: ptr("Kyrkan")},
+	}})
+
+	w := httptest.NewRecorder()
+	h.handleServices(w, httptest.NewRequest("GET", "/api/services?fields=date,time,service_name,source", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	var result []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("parsing response: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("got %d services, want 1", len(result))
+	}
+	want := map[string]interface{}{"date": today, "time": "10:00", "service_name": "Liturgi", "source": "Test"}
+	if len(result[0]) != len(want) {
+		t.Errorf("got fields %v, want %v", result[0], want)
+	}
+	for k, v := range want {
+		if result[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, result[0][k], v)
+		}
+	}
+}
+
+func TestHandleServicesUnknownField(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	h.handleServices(w, httptest.NewRequest("GET", "/api/services?fields=date,Confidence", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(w.Body.String(), "Confidence") {
+		t.Errorf("error should name the unknown field, got %q", w.Body.String())
+	}
+}
+
+func TestHandleServicesLang(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Source: "Test", Date: today, DayOfWeek: "Söndag", ServiceName: "Liturgi"},
+	}}
+	h := New(fetcher)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "Söndag"},
+		{"?lang=sv", "Söndag"},
+		{"?lang=en", "Sunday"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.handleServices(w, httptest.NewRequest("GET", "/api/services"+tt.query, nil))
+
+		var services []model.ChurchService
+		if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+			t.Fatalf("%s: parsing response: %v", tt.query, err)
+		}
+		if len(services) != 1 || services[0].DayOfWeek != tt.want {
+			t.Errorf("%s: services = %+v, want day_of_week %q", tt.query, services, tt.want)
+		}
+	}
+
+	if fetcher.services[0].DayOfWeek != "Söndag" {
+		t.Error("localizing should not modify the fetched services")
+	}
+}
+
+func TestHandleServicesExcludeType(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Source: "A", Date: today, ServiceName: "Helig Liturgi", Time: ptr("10:00")},
+		{Source: "A", Date: today, ServiceName: "Vesper", Time: ptr("17:00")},
+		{Source: "B", Date: today, ServiceName: "Orthros", Time: ptr("08:30")},
+		{Source: "B", Date: today, ServiceName: "Akathist", Time: ptr("18:00")},
+	}}
+	h := New(fetcher)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "Orthros,Helig Liturgi,Vesper,Akathist"},
+		{"?exclude_type=vespers", "Orthros,Helig Liturgi,Akathist"},
+		{"?exclude_type=vespers,+Matins", "Helig Liturgi,Akathist"},
+		{"?exclude_type=unknown", "Orthros,Helig Liturgi,Vesper,Akathist"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.handleServices(w, httptest.NewRequest("GET", "/api/services"+tt.query, nil))
+
+		var services []model.ChurchService
+		if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+			t.Fatalf("%s: parsing response: %v", tt.query, err)
+		}
+		var names []string
+		for _, s := range services {
+			names = append(names, s.ServiceName)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("%s: services = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleICSExcludeType(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: tomorrow, ServiceName: "Helig Liturgi", Time: ptr("10:00")},
+		{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: tomorrow, ServiceName: "Vesper", Time: ptr("17:00")},
+		{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: tomorrow, ServiceName: "Orthros", Time: ptr("08:30")},
+	}}
+	h := New(fetcher)
+
+	w := httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.+Georgios+Cathedral&exclude_type=vespers,matins", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Helig Liturgi") {
+		t.Errorf("liturgy should be kept:\n%s", body)
+	}
+	if strings.Contains(body, "Vesper") || strings.Contains(body, "Orthros") {
+		t.Errorf("vespers and matins should be excluded:\n%s", body)
+	}
+}
+
+func TestHandleICSCalendarName(t *testing.T) {
+	h := New(&mockFetcher{})
+
+	tests := []struct {
+		query    string
+		wantName string
+		wantDesc string
+	}{
+		{"", "Ortodoxa Gudstjänster", ""},
+		{"includeParishes=Finska+ortodoxa+f%C3%B6rsamlingen", "Ortodoxa Gudstjänster – Finska ortodoxa församlingen", "Församlingar: Finska ortodoxa församlingen"},
+		{"include=Finska+ortodoxa+f%C3%B6rsamlingen,fasts", "Ortodoxa Gudstjänster – Finska ortodoxa församlingen", "Församlingar: Finska ortodoxa församlingen"},
+		{"includeParishes=A,B&exclude_type=vespers", "Ortodoxa Gudstjänster", "Församlingar: A\\, B. Utan: vespers"},
+		{"includeCounties=Stockholm", "Ortodoxa Gudstjänster – Stockholm", "Län: Stockholm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?"+tt.query, nil))
+			body := w.Body.String()
+			if !strings.Contains(body, "X-WR-CALNAME:"+tt.wantName+"\r\n") {
+				t.Errorf("want X-WR-CALNAME:%s in:\n%s", tt.wantName, body)
+			}
+			if tt.wantDesc == "" {
+				if strings.Contains(body, "X-WR-CALDESC") {
+					t.Errorf("unfiltered feed should have no X-WR-CALDESC:\n%s", body)
+				}
+			} else if !strings.Contains(body, "X-WR-CALDESC:"+tt.wantDesc+"\r\n") {
+				t.Errorf("want X-WR-CALDESC:%s in:\n%s", tt.wantDesc, body)
+			}
+		})
+	}
+}
+
+func TestGenerateICSLang(t *testing.T) {
+	services := []model.ChurchService{
+		{Source: "Test", Date: "2026-03-08", DayOfWeek: "Söndag", ServiceName: "Liturgi", Time: ptr("10:00")},
+	}
+
+	if ics := GenerateICS(services); strings.Contains(ics, "Dag:") || strings.Contains(ics, "Day:") {
+		t.Error("day of week should only be added when lang is set")
+	}
+	if ics := generateICS(services, icsOptions{lang: "en"}); !strings.Contains(ics, "\\nDay: Sunday") {
+		t.Errorf("lang=en should add the English day of week:\n%s", ics)
+	}
+	if ics := generateICS(services, icsOptions{lang: "sv"}); !strings.Contains(ics, "\\nDag: Söndag") {
+		t.Errorf("lang=sv should add the Swedish day of week:\n%s", ics)
+	}
+}
+
+func TestServicesRedirectKeepsQuery(t *testing.T) {
+	mux := http.NewServeMux()
+	New(&mockFetcher{}).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/services?lang=en", nil))
+	if loc := w.Header().Get("Location"); loc != "/api/services?lang=en" {
+		t.Errorf("Location = %q, want /api/services?lang=en", loc)
+	}
+}
+
+func TestHandleNextPerSource(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	nextWeek := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Parish: "St. Georgios Cathedral", Source: "Gomos", Date: nextWeek, ServiceName: "Later", Time: ptr("10:00")},
+		{Parish: "St. Georgios Cathedral", Source: "Gomos", Date: tomorrow, ServiceName: "Next", Time: ptr("10:00")},
+		{Parish: "St. Georgios Cathedral", Source: "Gomos uploads", Date: nextWeek, ServiceName: "Uploaded", Time: ptr("18:00")},
+		{Parish: "Sankt Göran", Source: "Sankt Göran", Date: yesterday, ServiceName: "Past", Time: ptr("10:00")},
+	}}
+	h := New(fetcher)
+	h.SetSources([]model.SourceMetadata{{Name: "Gomos"}, {Name: "Gomos uploads"}, {Name: "Sankt Göran"}})
+	w := httptest.NewRecorder()
+	h.handleNextPerSource(w, httptest.NewRequest("GET", "/next-per-source", nil))
+
+	var got map[string]*model.ChurchService
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("parsing response: %v\n%s", err, w.Body.String())
+	}
+
+	if len(got) != 3 {
+		t.Errorf("got %d entries, want one per source: %v", len(got), got)
+	}
+	if svc := got["Gomos"]; svc == nil || svc.ServiceName != "Next" {
+		t.Errorf("Gomos = %+v, want the service on %s", svc, tomorrow)
+	}
+	if svc := got["Gomos uploads"]; svc == nil || svc.ServiceName != "Uploaded" {
+		t.Errorf("Gomos uploads = %+v, want its own next service", svc)
+	}
+	svc, ok := got["Sankt Göran"]
+	if !ok {
+		t.Fatal("Sankt Göran missing; sources without upcoming services should map to null")
+	}
+	if svc != nil {
+		t.Errorf("Sankt Göran = %+v, want null", svc)
+	}
+	if !strings.Contains(w.Body.String(), `"Sankt Göran":null`) {
+		t.Errorf("response should contain an explicit null:\n%s", w.Body.String())
+	}
+}
+
+func TestHandleSources(t *testing.T) {
+	h := New(&mockFetcher{})
+
+	w := httptest.NewRecorder()
+	h.handleSources(w, httptest.NewRequest("GET", "/sources", nil))
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("without sources, body = %s, want []", body)
+	}
+
+	h.SetSources([]model.SourceMetadata{
+		{Name: "St. Georgios Cathedral", Location: "Birger Jarlsgatan 92, 114 20 Stockholm", Languages: []string{"Grekiska"}, SourceURL: "https://gomos.se/en/category/schedule/"},
+		{Name: "Uppståndelsen"},
+	})
+	w = httptest.NewRecorder()
+	h.handleSources(w, httptest.NewRequest("GET", "/sources", nil))
+
+	var got []model.SourceMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("parsing response: %v\n%s", err, w.Body.String())
+	}
+	if len(got) != 2 || got[0].SourceURL != "https://gomos.se/en/category/schedule/" || got[1].Name != "Uppståndelsen" {
+		t.Errorf("sources = %+v", got)
+	}
+	if strings.Contains(w.Body.String(), `"location":""`) {
+		t.Errorf("empty metadata fields should be omitted:\n%s", w.Body.String())
+	}
+}
+
+func TestHandleClassify(t *testing.T) {
+	h := New(&mockFetcher{})
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("/admin/classify?text=Liturgi", "secret"); w.Code != http.StatusNotFound {
+		t.Errorf("without an admin token configured, status = %d, want 404", w.Code)
+	}
+
+	h.SetAdminToken("secret")
+	for _, token := range []string{"", "wrong"} {
+		if w := get("/admin/classify?text=Liturgi", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, w.Code)
+		}
+	}
+	if w := get("/admin/classify", "secret"); w.Code != http.StatusBadRequest {
+		t.Errorf("without text, status = %d, want 400", w.Code)
+	}
+
+	tests := []struct {
+		text       string
+		wantType   string
+		normalized string
+	}{
+		{"Helig Liturgi", serviceLiturgy, "helig liturgi"},
+		{"Vaka med  liturgi", serviceVigil, "vaka med liturgi"},
+		{"Orthros", serviceMatins, "orthros"},
+		{"Aftongudstjänst", serviceVespers, "aftongudstjänst"},
+		{"Bönegudstjänst", serviceMoleben, "bönegudstjänst"},
+		{"Akathist", "", "akathist"},
+	}
+	for _, tt := range tests {
+		w := get("/admin/classify?text="+url.QueryEscape(tt.text), "secret")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d", tt.text, w.Code)
+		}
+		var got classifyResult
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%q: parsing response: %v", tt.text, err)
+		}
+		if got.Type != tt.wantType || got.Normalized != tt.normalized || got.Text != tt.text {
+			t.Errorf("%q: got %+v, want type %q normalized %q", tt.text, got, tt.wantType, tt.normalized)
+		}
+	}
+}
+
+func TestICSLocationFallsBackToSourceMetadata(t *testing.T) {
+	h := New(&mockFetcher{})
+	h.SetSources([]model.SourceMetadata{{Name: "Sankt Göran", Location: "Vanadisvägen 35"}})
+
+	services := h.withSourceLocations([]model.ChurchService{
+		{Source: "Sankt Göran", Date: "2026-03-08", ServiceName: "Liturgi"},
+		{Source: "Sankt Göran", Date: "2026-03-15", ServiceName: "Liturgi", Location: ptr("Elsewhere")},
+		{Source: "Unknown", Date: "2026-03-08", ServiceName: "Liturgi"},
+	})
+
+	if services[0].Location == nil || *services[0].Location != "Vanadisvägen 35" {
+		t.Errorf("missing location should come from metadata, got %v", services[0].Location)
+	}
+	if *services[1].Location != "Elsewhere" {
+		t.Errorf("existing location overwritten: %q", *services[1].Location)
+	}
+	if services[2].Location != nil {
+		t.Errorf("source without metadata got location %q", *services[2].Location)
+	}
+	if ics := generateICS(services, icsOptions{}); !strings.Contains(ics, "LOCATION:Vanadisvägen 35\r\n") {
+		t.Errorf("ICS missing fallback LOCATION:\n%s", ics)
+	}
+}
+
+func TestHandleCancellationsICS(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	liturgy := model.ChurchService{Source: "A", Parish: "A", Date: tomorrow, ServiceName: "Liturgi", Time: ptr("10:00")}
+	vespers := model.ChurchService{Source: "A", Parish: "A", Date: tomorrow, ServiceName: "Vesper", Time: ptr("17:00")}
+	fetcher := &mockFetcher{services: []model.ChurchService{liturgy, vespers}}
+	h := New(fetcher)
+
+	render := func() string {
+		w := httptest.NewRecorder()
+		h.handleCancellationsICS(w, httptest.NewRequest("GET", "/calendar-cancellations.ics", nil))
+		return w.Body.String()
+	}
+	record := func(services ...model.ChurchService) {
+		t.Helper()
+		if err := RecordPublished(h.published, services, time.Now()); err != nil {
+			t.Fatalf("RecordPublished: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.handleCancellationsICS(w, httptest.NewRequest("GET", "/calendar-cancellations.ics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("without a store, status = %d, want 404", w.Code)
+	}
+
+	st, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetPublishedStore(st)
+
+	if body := render(); !strings.Contains(body, "METHOD:CANCEL") || strings.Contains(body, "BEGIN:VEVENT") {
+		t.Errorf("nothing recorded yet should cancel nothing:\n%s", body)
+	}
+	record(liturgy, vespers)
+	if body := render(); strings.Contains(body, "BEGIN:VEVENT") {
+		t.Errorf("first ingest should cancel nothing:\n%s", body)
+	}
+
+	// Vespers disappears from the feed
+	record(liturgy)
+	body := render()
+	for _, want := range []string{
+		"UID:" + icsUID(vespers) + "\r\n",
+		"SEQUENCE:1\r\n",
+		"STATUS:CANCELLED\r\n",
+		"DTSTART;TZID=Europe/Stockholm:" + strings.ReplaceAll(tomorrow, "-", "") + "T170000\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, icsUID(liturgy)) {
+		t.Errorf("the liturgy is still published and should not be cancelled:\n%s", body)
+	}
+
+	// Rendering has no side effects, and later ingests keep announcing the
+	// cancellation without bumping SEQUENCE again
+	render()
+	record(liturgy)
+	if body := render(); strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "SEQUENCE:1\r\n") {
+		t.Errorf("second ingest after removal:\n%s", body)
+	}
+
+	// Vespers returns: the calendar feed publishes it with a SEQUENCE above
+	// the cancellation's, and the cancellation feed drops it
+	record(liturgy, vespers)
+	if body := render(); strings.Contains(body, "BEGIN:VEVENT") {
+		t.Errorf("returned event still cancelled:\n%s", body)
+	}
+	w = httptest.NewRecorder()
+	h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=A", nil))
+	ics := w.Body.String()
+	if !strings.Contains(ics, "UID:"+icsUID(vespers)+"\r\nSEQUENCE:2\r\n") {
+		t.Errorf("calendar feed should publish the returned event with SEQUENCE:2:\n%s", ics)
+	}
+	if !strings.Contains(ics, "UID:"+icsUID(liturgy)+"\r\nDTSTART") {
+		t.Errorf("calendar feed should publish the unchanged event without SEQUENCE:\n%s", ics)
+	}
+}
+
+func TestHandleICSCachesPublishedSequences(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	vespers := model.ChurchService{Source: "A", Parish: "A", Date: tomorrow, ServiceName: "Vesper", Time: ptr("17:00")}
+	local, err := store.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &countingStore{Store: local}
+	h := New(&mockFetcher{services: []model.ChurchService{vespers}})
+	h.SetPublishedStore(st)
+
+	// Vespers was cancelled once and has returned
+	for _, services := range [][]model.ChurchService{{vespers}, nil, {vespers}} {
+		if err := RecordPublished(st, services, time.Now()); err != nil {
+			t.Fatalf("RecordPublished: %v", err)
+		}
+	}
+	render := func() string {
+		w := httptest.NewRecorder()
+		h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=A", nil))
+		return w.Body.String()
+	}
+
+	st.gets = 0
+	for i := 0; i < 3; i++ {
+		if ics := render(); !strings.Contains(ics, "SEQUENCE:2\r\n") {
+			t.Fatalf("render %d: want SEQUENCE:2:\n%s", i, ics)
+		}
+	}
+	if st.gets != 1 {
+		t.Errorf("store read %d times for 3 feeds, want 1 within publishedSequencesTTL", st.gets)
+	}
+
+	// Cancelled and returned again: picked up once the cached SEQUENCEs expire
+	for _, services := range [][]model.ChurchService{nil, {vespers}} {
+		if err := RecordPublished(st, services, time.Now()); err != nil {
+			t.Fatalf("RecordPublished: %v", err)
+		}
+	}
+	h.sequencesCheckedAt = time.Now().Add(-publishedSequencesTTL)
+	if ics := render(); !strings.Contains(ics, "SEQUENCE:4\r\n") {
+		t.Errorf("want SEQUENCE:4 after the cache expired:\n%s", ics)
+	}
+}
+
+// unreadableStore is a store holding a key it cannot read, as when GCS
+// returns an error for an existing object.
+type unreadableStore struct {
+	store.Store
+	key    string
+	writes int
+}
+
+func (s *unreadableStore) Get(key string) ([]byte, bool) { return nil, false }
+
+func (s *unreadableStore) List(prefix string) ([]string, error) {
+	return []string{s.key}, nil
+}
+
+func (s *unreadableStore) SetJSON(key string, v interface{}) error {
+	s.writes++
+	return nil
+}
+
+func TestRecordPublishedKeepsUnreadableHistory(t *testing.T) {
+	st := &unreadableStore{key: publishedKey}
+	services := []model.ChurchService{{Source: "A", Date: time.Now().Format("2006-01-02"), ServiceName: "Liturgi"}}
+
+	if err := RecordPublished(st, services, time.Now()); err == nil {
+		t.Error("RecordPublished should fail when the stored events cannot be read")
+	}
+	if st.writes != 0 {
+		t.Errorf("RecordPublished wrote %d times over unreadable history, want 0", st.writes)
+	}
+
+	h := New(&mockFetcher{})
+	h.SetPublishedStore(st)
+	w := httptest.NewRecorder()
+	h.handleCancellationsICS(w, httptest.NewRequest("GET", "/calendar-cancellations.ics", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestICSGeo(t *testing.T) {
+	sources := map[string]model.SourceMetadata{
+		"St. Georgios Cathedral": {Name: "St. Georgios Cathedral", Location: "Birger Jarlsgatan 92", Lat: 59.3473, Lng: 18.0588},
+		"Helige Sergij":          {Name: "Helige Sergij", Location: "Solkraftsvägen 16A"},
+	}
+
+	tests := []struct {
+		name    string
+		service model.ChurchService
+		wantGeo string
+	}{
+		{"source with coordinates", model.ChurchService{Source: "St. Georgios Cathedral", Location: ptr("Birger Jarlsgatan 92")}, "GEO:59.3473;18.0588\r\n"},
+		{"held elsewhere", model.ChurchService{Source: "St. Georgios Cathedral", Location: ptr("Uppsala")}, ""},
+		{"source without coordinates", model.ChurchService{Source: "Helige Sergij", Location: ptr("Solkraftsvägen 16A")}, ""},
+		{"unknown source", model.ChurchService{Source: "Unknown", Location: ptr("Birger Jarlsgatan 92")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.service.Date = "2026-03-08"
+			tt.service.ServiceName = "Liturgi"
+			ics := generateICS([]model.ChurchService{tt.service}, icsOptions{sources: sources})
+			if tt.wantGeo != "" && !strings.Contains(ics, tt.wantGeo) {
+				t.Errorf("missing %q in:\n%s", tt.wantGeo, ics)
+			}
+			if tt.wantGeo == "" && strings.Contains(ics, "GEO:") {
+				t.Errorf("unexpected GEO in:\n%s", ics)
+			}
+		})
+	}
+}
+
+func TestICSRecurrence(t *testing.T) {
+	services := []model.ChurchService{{
+		Source:      "Sankt Sava",
+		Date:        "2026-03-08",
+		ServiceName: "Helig Liturgi",
+		Time:        ptr("09:30"),
+		Recurring: &model.RecurrenceRule{
+			Weekdays: []time.Weekday{time.Sunday},
+			Until:    "2026-08-30",
+			ExDates:  []string{"2026-04-12"},
+		},
+	}}
+
+	ics := generateICS(services, icsOptions{})
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("got %d VEVENTs, want 1", n)
+	}
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Stockholm:20260308T093000\r\n",
+		// End of 2026-08-30 in Stockholm (CEST) is 21:59:59 UTC
+		"RRULE:FREQ=WEEKLY;BYDAY=SU;UNTIL=20260830T215959Z\r\n",
+		"EXDATE;TZID=Europe/Stockholm:20260412T093000\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ICS missing %q:\n%s", want, ics)
+		}
+	}
+
+	services[0].Recurring = nil
+	if ics := generateICS(services, icsOptions{}); strings.Contains(ics, "RRULE") {
+		t.Errorf("one-off service should have no RRULE:\n%s", ics)
+	}
+}
+
+func TestHandleICSNotes(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", Date: tomorrow, ServiceName: "Liturgi", Time: ptr("10:00"),
+			Occasion: ptr("Söndagen"), Notes: ptr("Lång anteckning från affischen")},
+	}}
+	h := New(fetcher)
+
+	for _, tt := range []struct {
+		query     string
+		wantNotes bool
+	}{
+		{"", true},
+		{"notes=1", true},
+		{"notes=0", false},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.handleICS(w, httptest.NewRequest("GET", "/calendar.ics?includeParishes=St.+Georgios+Cathedral&"+tt.query, nil))
+			body := w.Body.String()
+			if got := strings.Contains(body, "Info: Lång anteckning"); got != tt.wantNotes {
+				t.Errorf("notes present = %v, want %v:\n%s", got, tt.wantNotes, body)
+			}
+			if !strings.Contains(body, "Tillfälle: Söndagen") {
+				t.Errorf("occasion should always be included:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestNextPerSourceSkipsStartedServices(t *testing.T) {
+	stockholm, _ := time.LoadLocation("Europe/Stockholm")
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, stockholm)
+	services := []model.ChurchService{
+		{Source: "A", Date: "2026-03-08", ServiceName: "Morning", Time: ptr("10:00")},
+		{Source: "A", Date: "2026-03-08", ServiceName: "Evening", Time: ptr("18:00")},
+		{Source: "B", Date: "2026-03-08", ServiceName: "All day"},
+	}
+
+	got := nextPerSource(services, nil, now)
+	if got["A"] == nil || got["A"].ServiceName != "Evening" {
+		t.Errorf("A = %+v, want Evening", got["A"])
+	}
+	if got["B"] == nil || got["B"].ServiceName != "All day" {
+		t.Errorf("B = %+v, want All day", got["B"])
+	}
+}
+
+func TestNextPerSourceUsesStockholmDate(t *testing.T) {
+	// 23:30 UTC on March 8 is already March 9 in Stockholm
+	now := time.Date(2026, 3, 8, 23, 30, 0, 0, time.UTC)
+	services := []model.ChurchService{
+		{Source: "A", Date: "2026-03-08", ServiceName: "Yesterday's fast"},
+		{Source: "A", Date: "2026-03-09", ServiceName: "Today's feast"},
+	}
+
+	got := nextPerSource(services, nil, now)
+	if got["A"] == nil || got["A"].ServiceName != "Today's feast" {
+		t.Errorf("A = %+v, want Today's feast", got["A"])
+	}
+}
+
+func TestDataGeneratedHeader(t *testing.T) {
+	fetcher := &mockFetcher{batchID: "20260308-041500"}
+	h := New(fetcher)
+
+	for _, tt := range []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+	}{
+		{"services", h.handleServices, "/api/services"},
+		{"ics", h.handleICS, "/calendar.ics"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest("GET", tt.path, nil))
+
+			header := w.Header().Get("X-Data-Generated")
+			generated, err := time.Parse(time.RFC3339, header)
+			if err != nil {
+				t.Fatalf("X-Data-Generated = %q: %v", header, err)
+			}
+			if want := time.Date(2026, 3, 8, 4, 15, 0, 0, time.UTC); !generated.Equal(want) {
+				t.Errorf("X-Data-Generated = %v, want %v", generated, want)
+			}
+		})
+	}
+}
+
+func TestDataGeneratedHeaderIsCached(t *testing.T) {
+	fetcher := &mockFetcher{batchID: "20260308-041500"}
+	h := New(fetcher)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.handleServices(w, httptest.NewRequest("GET", "/api/services", nil))
+		if w.Header().Get("X-Data-Generated") == "" {
+			t.Fatalf("request %d: missing X-Data-Generated header", i)
+		}
+	}
+	if fetcher.batchIDCalls != 1 {
+		t.Errorf("GetLatestBatchID called %d times, want 1", fetcher.batchIDCalls)
+	}
+}
+
+func TestDataGeneratedHeaderUnknownBatch(t *testing.T) {
+	h := New(&mockFetcher{batchID: "batch-123"})
+	w := httptest.NewRecorder()
+	h.handleServices(w, httptest.NewRequest("GET", "/api/services", nil))
+	if header := w.Header().Get("X-Data-Generated"); header != "" {
+		t.Errorf("X-Data-Generated = %q, want no header for an unparseable batch ID", header)
+	}
+}
+
+func TestHandleIndexNotFound(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/nonexistent", nil)
+
+	h.handleIndex(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleFeedbackGet(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/feedback", nil)
+
+	h.handleFeedback(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandleFeedbackPostHoneypot(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	body := `{"type":"error","message":"test","website":"http://spam.com"}`
+	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+
+	h.handleFeedback(w, r)
+
+	// Should silently accept (200) but not actually send
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandleFeedbackPostSendsEmail(t *testing.T) {
+	h := New(&mockFetcher{})
+	sender := &email.NoopSender{}
+	h.SetMail(sender, []string{"admin@example.com"})
+	w := httptest.NewRecorder()
+	body := `{"type":"new_parish","email":"a@example.com","message":"Rad ett\nRad två","timestamp":1000}`
+	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	r.RemoteAddr = "1.2.3.4:5678"
+
+	h.handleFeedback(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if len(sender.Sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(sender.Sent))
+	}
+	msg := sender.Sent[0]
+	if msg.Subject != "Feedback: Lägg till församling" || fmt.Sprint(msg.To) != "[admin@example.com]" {
+		t.Errorf("subject %q to %v", msg.Subject, msg.To)
+	}
+	if !strings.Contains(msg.Body, "Från: a@example.com") || !strings.Contains(msg.Body, "Rad ett\nRad två") {
+		t.Errorf("body = %q", msg.Body)
+	}
+	if msg.ReplyTo != "a@example.com" {
+		t.Errorf("ReplyTo = %q, want the submitter's address", msg.ReplyTo)
+	}
+	if !strings.Contains(msg.HTML, "Rad ett<br>") {
+		t.Errorf("HTML = %q, want line breaks", msg.HTML)
+	}
+}
+
+func TestSendFeedbackEmailReplyTo(t *testing.T) {
+	tests := []struct {
+		senderEmail string
+		wantReplyTo string
+	}{
+		{"a@example.com", "a@example.com"},
+		{"Anna <anna@example.com>", "anna@example.com"},
+		{"", ""},
+		{"inte en adress", ""},
+		{"a@example.com\r\nBcc: spam@example.com", ""},
+	}
+	for _, tt := range tests {
+		h := New(&mockFetcher{})
+		sender := &email.NoopSender{}
+		h.SetMail(sender, []string{"admin@example.com"})
+
+		if err := h.sendFeedbackEmail("error", tt.senderEmail, "<b>Fel</b> tid"); err != nil {
+			t.Fatalf("%q: sendFeedbackEmail: %v", tt.senderEmail, err)
+		}
+		msg := sender.Sent[0]
+		if msg.ReplyTo != tt.wantReplyTo {
+			t.Errorf("%q: ReplyTo = %q, want %q", tt.senderEmail, msg.ReplyTo, tt.wantReplyTo)
+		}
+		if !strings.Contains(msg.HTML, "&lt;b&gt;Fel&lt;/b&gt; tid") {
+			t.Errorf("%q: HTML should escape the message: %q", tt.senderEmail, msg.HTML)
+		}
+	}
+}
+
+func TestHandleFeedbackPostValidation(t *testing.T) {
+	post := func(h *Handler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+		r.RemoteAddr = "1.2.3.4:5678"
+		h.handleFeedback(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"at the length limit", `{"type":"other","message":"` + strings.Repeat("å", maxFeedbackMessageRunes) + `"}`, http.StatusOK},
+		{"over the length limit", `{"type":"other","message":"` + strings.Repeat("å", maxFeedbackMessageRunes+1) + `"}`, http.StatusBadRequest},
+		{"invalid UTF-8", "{\"type\":\"other\",\"message\":\"hej \xff\"}", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(&mockFetcher{})
+			sender := &email.NoopSender{}
+			h.SetMail(sender, []string{"admin@example.com"})
+
+			w := post(h, tt.body)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if sent := len(sender.Sent) > 0; sent != (tt.wantCode == http.StatusOK) {
+				t.Errorf("email sent = %v with status %d", sent, w.Code)
+			}
+		})
+	}
+
+	t.Run("CRLF injection", func(t *testing.T) {
+		h := New(&mockFetcher{})
+		sender := &email.NoopSender{}
+		h.SetMail(sender, []string{"admin@example.com"})
+
+		w := post(h, `{"type":"error\r\nBcc: spam@example.com","email":"a@example.com\r\nBcc: spam@example.com","message":"hej"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		msg := sender.Sent[0]
+		if strings.ContainsAny(msg.Subject, "\r\n") {
+			t.Errorf("subject = %q, want no line breaks", msg.Subject)
+		}
+		if !strings.Contains(msg.Body, "Från: a@example.comBcc: spam@example.com\n") || msg.ReplyTo != "" {
+			t.Errorf("email should be flattened and not used as Reply-To: body %q, reply-to %q", msg.Body, msg.ReplyTo)
+		}
+	})
+}
+
+func TestHandleFeedbackPostMissingFields(t *testing.T) {
+	h := New(&mockFetcher{})
+	h.SetMail(nil, nil) // no email
+	w := httptest.NewRecorder()
+	body := `{"type":"","message":"","timestamp":1000}`
+	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	r.RemoteAddr = "1.2.3.4:5678"
+
+	h.handleFeedback(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleFeedbackPostOversizedBody(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	// Valid JSON with a very large message field (2MB)
+	bigMsg := strings.Repeat("a", 2*1024*1024)
+	body := fmt.Sprintf(`{"type":"error","message":"%s","timestamp":1000}`, bigMsg)
+	r := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	r.RemoteAddr = "1.2.3.4:5678"
+
+	h.handleFeedback(w, r)
+
+	// Should be rejected — either 400 (bad request) or 413 (too large)
+	if w.Code == http.StatusOK || w.Code == http.StatusInternalServerError {
+		t.Errorf("oversized body should be rejected, got status %d", w.Code)
+	}
+}
+
+func TestHandleFeedbackMethodNotAllowed(t *testing.T) {
+	h := New(&mockFetcher{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PUT", "/feedback", nil)
+
+	h.handleFeedback(w, r)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleLastUpdated(t *testing.T) {
 	fetcher := &mockFetcher{batchID: "batch-123"}
 	h := New(fetcher)
 	w := httptest.NewRecorder()
@@ -625,6 +1942,23 @@ func TestHandleLastUpdated(t *testing.T) {
 	if result["batch_id"] != "batch-123" {
 		t.Errorf("batch_id = %q, want %q", result["batch_id"], "batch-123")
 	}
+	if _, ok := result["generated_at"]; ok {
+		t.Error("generated_at should be omitted for a batch ID that is not a timestamp")
+	}
+}
+
+func TestHandleLastUpdatedGeneratedAt(t *testing.T) {
+	h := New(&mockFetcher{batchID: "20260308-041500"})
+	w := httptest.NewRecorder()
+	h.handleLastUpdated(w, httptest.NewRequest("GET", "/api/last-updated", nil))
+
+	var result map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if result["generated_at"] != "2026-03-08T04:15:00Z" {
+		t.Errorf("generated_at = %q, want 2026-03-08T04:15:00Z", result["generated_at"])
+	}
 }
 
 func TestGenerateIcon(t *testing.T) {
@@ -723,3 +2057,328 @@ func TestDeduplicateServices(t *testing.T) {
 		t.Error("St. Ignatios event should not be deduplicated")
 	}
 }
+
+// --- metrics ---
+
+// mockIngestReader returns canned ingest records and counts lookups.
+type mockIngestReader struct {
+	summary   *firestore.BatchSummary
+	lastFetch map[string]time.Time
+	calls     int
+}
+
+func (m *mockIngestReader) GetLatestBatchSummary(ctx context.Context) (*firestore.BatchSummary, error) {
+	m.calls++
+	return m.summary, nil
+}
+
+func (m *mockIngestReader) GetLastIngestTime(ctx context.Context) (time.Time, map[string]time.Time, error) {
+	return time.Time{}, m.lastFetch, nil
+}
+
+func TestHandleMetrics(t *testing.T) {
+	ingest := &mockIngestReader{
+		summary: &firestore.BatchSummary{
+			BatchID: "20260308-040000",
+			Extracts: []firestore.ExtractCount{
+				{Source: "Metrics Test Parish", Result: "empty", Count: 1},
+				{Source: "Metrics Test Parish", Result: "success", Count: 3},
+			},
+		},
+		lastFetch: map[string]time.Time{
+			"Metrics Test Parish": time.Date(2026, time.March, 8, 4, 0, 0, 0, time.UTC),
+			"Another Parish":      time.Date(2026, time.March, 7, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	h := New(&mockFetcher{})
+	h.SetIngestReader(ingest)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.handleMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+		body := w.Body.String()
+		for _, want := range []string{
+			"# TYPE vision_extract_last_batch gauge\n",
+			`vision_extract_last_batch{source="Metrics Test Parish",result="success"} 3`,
+			`vision_extract_last_batch{source="Metrics Test Parish",result="empty"} 1`,
+			"# TYPE scraper_last_fetch_timestamp_seconds gauge\n" +
+				`scraper_last_fetch_timestamp_seconds{source="Another Parish"} 1772856000` + "\n" +
+				`scraper_last_fetch_timestamp_seconds{source="Metrics Test Parish"} 1772942400` + "\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("metrics output missing %q:\n%s", want, body)
+			}
+		}
+	}
+	if ingest.calls != 1 {
+		t.Errorf("ingest records read %d times, want 1 within the TTL", ingest.calls)
+	}
+
+	// Without an ingest reader there is nothing to serve
+	w := httptest.NewRecorder()
+	New(&mockFetcher{}).handleMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("without ingest reader: status %d, body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleAtomFeed(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{Source: "St. Georgios Cathedral", Date: future, ServiceName: "Helig Liturgi & Vesper", Time: ptr("10:00"), SourceURL: "https://example.com/a?x=1&y=2"},
+		{Source: "Sankt Göran", Date: future, ServiceName: "Vesper <kväll>", Time: ptr("18:00")},
+		{Source: "Sankt Göran", Date: "2020-01-01", ServiceName: "Past"},
+	}}
+	h := New(fetcher)
+	w := httptest.NewRecorder()
+	h.handleAtomFeed(w, httptest.NewRequest("GET", "/feed.atom", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var feed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Entries []struct {
+			Title   string `xml:"title"`
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+			Link    struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("parsing feed: %v\n%s", err, w.Body.String())
+	}
+
+	if len(feed.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(feed.Entries))
+	}
+	first := feed.Entries[0]
+	if first.Title != "Helig Liturgi & Vesper" {
+		t.Errorf("entry title = %q", first.Title)
+	}
+	if first.Link.Href != "https://example.com/a?x=1&y=2" {
+		t.Errorf("entry link = %q", first.Link.Href)
+	}
+	if !strings.HasPrefix(first.Updated, future+"T10:00:00") {
+		t.Errorf("entry updated = %q, want %sT10:00:00...", first.Updated, future)
+	}
+	if feed.Entries[1].Title != "Vesper <kväll>" {
+		t.Errorf("second entry title = %q", feed.Entries[1].Title)
+	}
+	if !strings.Contains(feed.Entries[1].Link.Href, "/event/") {
+		t.Errorf("entry without source URL should link to its event page, got %q", feed.Entries[1].Link.Href)
+	}
+}
+
+func TestHandleJSONFeed(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	fetcher := &mockFetcher{services: []model.ChurchService{
+		{ID: "abc123", Source: "St. Georgios Cathedral", Date: future, ServiceName: "Helig Liturgi", Title: "Liturgi", Time: ptr("10:00"), SourceURL: "https://example.com/schema"},
+		{Source: "Sankt Göran", Date: future, ServiceName: "Vesper", Time: ptr("18:00")},
+		{Source: "Sankt Göran", Date: "2020-01-01", ServiceName: "Past"},
+	}}
+	h := New(fetcher)
+	w := httptest.NewRecorder()
+	h.handleJSONFeed(w, httptest.NewRequest("GET", "/feed.json", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/feed+json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var feed struct {
+		Version string `json:"version"`
+		Items   []struct {
+			ID            string `json:"id"`
+			URL           string `json:"url"`
+			Title         string `json:"title"`
+			DatePublished string `json:"date_published"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("parsing feed: %v", err)
+	}
+
+	if feed.Version != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("version = %q", feed.Version)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+	first := feed.Items[0]
+	if first.ID != "abc123" || first.Title != "Liturgi" || first.URL != "https://example.com/schema" {
+		t.Errorf("items[0] = %+v", first)
+	}
+	if !strings.HasPrefix(first.DatePublished, future+"T10:00:00") {
+		t.Errorf("items[0].date_published = %q", first.DatePublished)
+	}
+	if len(feed.Items[1].ID) != 32 {
+		t.Errorf("items[1].id = %q, want a 32-character hash", feed.Items[1].ID)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	if err := ValidateTemplates(); err != nil {
+		t.Fatalf("embedded templates: %v", err)
+	}
+
+	// Copy the embedded files, then drop or break one at a time
+	files := fstest.MapFS{}
+	entries, err := templates.ReadDir("templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := templates.ReadFile("templates/" + e.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		files["templates/"+e.Name()] = &fstest.MapFile{Data: data}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(fstest.MapFS)
+		want   string
+	}{
+		{"missing template", func(m fstest.MapFS) { delete(m, "templates/event.html") }, "event.html"},
+		{"malformed template", func(m fstest.MapFS) { m["templates/about.html"] = &fstest.MapFile{Data: []byte("{{ .Broken ")} }, "about.html"},
+		{"missing static file", func(m fstest.MapFS) { delete(m, "templates/sw.js") }, "sw.js"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fstest.MapFS{}
+			for k, v := range files {
+				m[k] = v
+			}
+			tt.modify(m)
+			err := validateTemplates(m)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validateTemplates error = %v, want one mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	var logs strings.Builder
+	h := New(&mockFetcher{err: fmt.Errorf("firestore unavailable")}, WithLogger(logging.New(&logs, slog.LevelInfo)))
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := RequestID(mux)
+
+	req := httptest.NewRequest("GET", "/api/services", nil)
+	req.Header.Set("X-Request-ID", "trace-123")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-ID"); got != "trace-123" {
+		t.Errorf("X-Request-ID = %q, want trace-123", got)
+	}
+	var record map[string]any
+	if err := json.NewDecoder(strings.NewReader(logs.String())).Decode(&record); err != nil {
+		t.Fatalf("log output is not JSON: %v\n%s", err, logs.String())
+	}
+	if record["level"] != "ERROR" || record["msg"] != "fetching services" ||
+		record["request_id"] != "trace-123" || record["err"] != "firestore unavailable" {
+		t.Errorf("log record = %v, want the error with the request ID", record)
+	}
+
+	// Missing or implausible IDs are replaced with a generated one
+	for _, header := range []string{"", "forged\nline", strings.Repeat("x", maxRequestIDLen+1)} {
+		req := httptest.NewRequest("GET", "/api/services", nil)
+		req.Header.Set("X-Request-ID", header)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if got := w.Header().Get("X-Request-ID"); got == "" || got == header {
+			t.Errorf("X-Request-ID %q: response ID = %q, want a generated one", header, got)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs strings.Builder
+	h := New(&mockFetcher{}, WithLogger(logging.New(&logs, slog.LevelInfo)))
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	// Without a published events store the cancellation feed is a 404
+	req := httptest.NewRequest("GET", "/calendar-cancellations.ics", nil)
+	req.Header.Set("X-Request-ID", "trace-404")
+	w := httptest.NewRecorder()
+	RequestID(mux).ServeHTTP(w, req)
+
+	var record map[string]any
+	if err := json.NewDecoder(strings.NewReader(logs.String())).Decode(&record); err != nil {
+		t.Fatalf("log output is not JSON: %v\n%s", err, logs.String())
+	}
+	if record["msg"] != "request" || record["method"] != "GET" || record["path"] != "/calendar-cancellations.ics" ||
+		record["status"] != float64(w.Code) || record["request_id"] != "trace-404" {
+		t.Errorf("access log = %v, want GET /calendar-cancellations.ics with status %d", record, w.Code)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if _, ok := record["duration_ms"].(float64); !ok {
+		t.Errorf("access log should have a duration: %v", record)
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+var dtstampPattern = regexp.MustCompile(`(?m)^DTSTAMP:\d{8}T\d{6}Z\r$`)
+
+// TestICSGolden renders a fixed set of services covering the feed's features
+// and compares the result with testdata/golden.ics. Run with -update after an
+// intended format change.
+func TestICSGolden(t *testing.T) {
+	stockholm, _ := time.LoadLocation("Europe/Stockholm")
+	start := time.Date(2026, 3, 8, 10, 0, 0, 0, stockholm)
+	end := start.Add(90 * time.Minute)
+
+	services := []model.ChurchService{
+		{
+			Parish: "St. Georgios Cathedral", Source: "St. Georgios Cathedral", SourceURL: "https://gomos.se/en/category/schedule/",
+			Date: "2026-03-08", DayOfWeek: "Söndag", ServiceName: "Helig Liturgi", Title: "Liturgi",
+			Location: ptr("Birger Jarlsgatan 92, 114 20 Stockholm"), Time: ptr("10:00 - 11:30"), StartTime: &start, EndTime: &end,
+			Occasion: ptr("Ortodoxins söndag"), ParishLanguage: ptr("Grekiska"),
+		},
+		{
+			Parish: "Sankt Göran", Source: "Sankt Göran", Date: "2026-03-07", DayOfWeek: "Lördag",
+			ServiceName: "Vesper", Time: ptr("17:00"), Notes: ptr("Bikt efter vespern; kom i tid"), EventLanguage: ptr("Rumänska"),
+		},
+		{
+			Parish: "Kristi Förklarings Ortodoxa Församling", Source: "Kristi Förklarings Ortodoxa Församling",
+			Date: "2026-03-14", EndDate: "2026-03-15", DayOfWeek: "Lördag", ServiceName: "Församlingsläger",
+		},
+		{
+			Parish: "Sankt Sava", Source: "Sankt Sava", Date: "2026-03-08", DayOfWeek: "Söndag",
+			ServiceName: "Morgongudstjänst", Location: ptr("Bägerstavägen 68, 120 47 Enskede"), Time: ptr("08:00"),
+			Recurring: &model.RecurrenceRule{Weekdays: []time.Weekday{time.Sunday}, Until: "2026-04-26", ExDates: []string{"2026-04-12"}},
+		},
+	}
+	sources := map[string]model.SourceMetadata{
+		"St. Georgios Cathedral": {Name: "St. Georgios Cathedral", Location: "Birger Jarlsgatan 92, 114 20 Stockholm", Lat: 59.3473, Lng: 18.0588},
+	}
+
+	got := dtstampPattern.ReplaceAllString(generateICS(services, icsOptions{lang: "en", sources: sources}), "DTSTAMP:00000000T000000Z\r")
+
+	golden := filepath.Join("testdata", "golden.ics")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("ICS output differs from %s (run with -update if intended):\n%s", golden, got)
+	}
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxDataAge is how old the latest ingest batch may be before /healthz
+// reports the data as stale. Ingestion runs several times a day, so a day
+// without a batch means every scraper run has failed.
+const maxDataAge = 24 * time.Hour
+
+// healthProbeKey is listed to check that the published events store answers.
+// Nothing is stored under it, so the listing is a cheap read.
+const healthProbeKey = "health/probe"
+
+// storeProbeTTL is how long a store probe result is reused, so that uptime
+// monitors polling /healthz and /health/ready do not each reach GCS.
+const storeProbeTTL = time.Minute
+
+// healthReport is the JSON body of the health checks. Failing lists one
+// "subsystem: reason" line per failed check.
+type healthReport struct {
+	Status  string   `json:"status"`
+	Failing []string `json:"failing,omitempty"`
+}
+
+// handleHealthz is a health check for uptime monitors: 200 if recent data
+// has been ingested and the published events store (if any) answers,
+// otherwise 503 with the failing subsystems. /health stays a plain liveness
+// probe.
+func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	var failing []string
+	if err := h.checkDataFresh(ctx, time.Now()); err != nil {
+		failing = append(failing, "data: "+err.Error())
+	}
+	if err := h.checkPublishedStore(); err != nil {
+		failing = append(failing, "store: "+err.Error())
+	}
+	h.writeHealthReport(ctx, w, failing)
+}
+
+// handleReady is a readiness probe: 200 if Firestore answers and the
+// published events store (GCS, if configured) answers a listing,
+// otherwise 503 with the failing components. Unlike /healthz it does not
+// care how old the data is.
+func (h *Handler) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var failing []string
+	if _, err := h.fetcher.GetLatestBatchID(ctx); err != nil {
+		failing = append(failing, "firestore: "+err.Error())
+	}
+	if err := h.checkPublishedStore(); err != nil {
+		failing = append(failing, "store: "+err.Error())
+	}
+	h.writeHealthReport(ctx, w, failing)
+}
+
+// checkDataFresh reports an error unless the latest ingest batch, which holds
+// the data of every scraper that succeeded, ran within maxDataAge of now.
+func (h *Handler) checkDataFresh(ctx context.Context, now time.Time) error {
+	batchID, err := h.fetcher.GetLatestBatchID(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching latest batch: %w", err)
+	}
+	generated, ok := batchTime(batchID)
+	if !ok {
+		return fmt.Errorf("no ingest batch with a known time (latest %q)", batchID)
+	}
+	if age := now.Sub(generated); age > maxDataAge {
+		return fmt.Errorf("latest batch %s is %s old (max %s)", batchID, age.Round(time.Minute), maxDataAge)
+	}
+	return nil
+}
+
+// checkPublishedStore lists the probe key in the published events store,
+// reusing the result for storeProbeTTL. It passes when no store is configured.
+func (h *Handler) checkPublishedStore() error {
+	if h.published == nil {
+		return nil
+	}
+	h.storeProbeMu.Lock()
+	defer h.storeProbeMu.Unlock()
+
+	if !h.storeProbeCheckedAt.IsZero() && time.Since(h.storeProbeCheckedAt) < storeProbeTTL {
+		return h.storeProbeErr
+	}
+	h.storeProbeErr = nil
+	if _, err := h.published.List(healthProbeKey); err != nil {
+		h.storeProbeErr = fmt.Errorf("listing probe: %w", err)
+	}
+	h.storeProbeCheckedAt = time.Now()
+	return h.storeProbeErr
+}
+
+// writeHealthReport responds 200 if nothing failed and 503 otherwise.
+func (h *Handler) writeHealthReport(ctx context.Context, w http.ResponseWriter, failing []string) {
+	report := healthReport{Status: "ok", Failing: failing}
+	status := http.StatusOK
+	if len(failing) > 0 {
+		report.Status = "degraded"
+		status = http.StatusServiceUnavailable
+		h.logFor(ctx).Warn("health check failing", "failing", failing)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ortodoxa-gudstjanster/internal/firestore"
+)
+
+// IngestReader reads what the ingest job recorded, for /metrics.
+// *firestore.Client implements it.
+type IngestReader interface {
+	GetLatestBatchSummary(ctx context.Context) (*firestore.BatchSummary, error)
+	GetLastIngestTime(ctx context.Context) (time.Time, map[string]time.Time, error)
+}
+
+// ingestMetricsTTL is how long the ingest records behind /metrics are reused
+// before Firestore is asked again. Ingestion runs a few times a day.
+const ingestMetricsTTL = 5 * time.Minute
+
+// ingestMetrics is a snapshot of the ingest records served at /metrics.
+type ingestMetrics struct {
+	summary   *firestore.BatchSummary // nil before the first ingest run
+	lastFetch map[string]time.Time    // source → time of its latest stored batch
+}
+
+// handleMetrics serves, in the Prometheus text exposition format, what the
+// ingest job recorded: the latest run's vision extraction counts, from its
+// batch summary, and when each source's stored services were fetched.
+// Both happen in the ingest job, so they are read back rather than counted
+// here.
+func (h *Handler) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var sb strings.Builder
+
+	m := h.ingestMetrics(r.Context())
+	if m.summary != nil && len(m.summary.Extracts) > 0 {
+		sb.WriteString("# HELP vision_extract_last_batch Schedule extractions in the latest ingest run by source and result (success, error, empty).\n")
+		sb.WriteString("# TYPE vision_extract_last_batch gauge\n")
+		for _, c := range m.summary.Extracts {
+			fmt.Fprintf(&sb, "vision_extract_last_batch{source=\"%s\",result=\"%s\"} %d\n", escapeLabel(c.Source), escapeLabel(c.Result), c.Count)
+		}
+	}
+
+	if len(m.lastFetch) > 0 {
+		sb.WriteString("# HELP scraper_last_fetch_timestamp_seconds When the services stored for each source were fetched, as a Unix time.\n")
+		sb.WriteString("# TYPE scraper_last_fetch_timestamp_seconds gauge\n")
+		sources := make([]string, 0, len(m.lastFetch))
+		for source := range m.lastFetch {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			fmt.Fprintf(&sb, "scraper_last_fetch_timestamp_seconds{source=\"%s\"} %d\n", escapeLabel(source), m.lastFetch[source].Unix())
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// ingestMetrics returns the ingest records, looked up at most once per
+// ingestMetricsTTL. After a failed lookup the last snapshot is used and the
+// next request tries again.
+func (h *Handler) ingestMetrics(ctx context.Context) ingestMetrics {
+	if h.ingest == nil {
+		return ingestMetrics{}
+	}
+	h.metricsMu.Lock()
+	defer h.metricsMu.Unlock()
+
+	if !h.metricsCheckedAt.IsZero() && time.Since(h.metricsCheckedAt) < ingestMetricsTTL {
+		return h.metrics
+	}
+	summary, err := h.ingest.GetLatestBatchSummary(ctx)
+	if err != nil {
+		h.logFor(ctx).Error("reading batch summary for /metrics", "err", err)
+		return h.metrics
+	}
+	_, lastFetch, err := h.ingest.GetLastIngestTime(ctx)
+	if err != nil {
+		h.logFor(ctx).Error("reading ingest times for /metrics", "err", err)
+		return h.metrics
+	}
+	h.metrics = ingestMetrics{summary: summary, lastFetch: lastFetch}
+	h.metricsCheckedAt = time.Now()
+	return h.metrics
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// maxRequestIDLen bounds a client-supplied X-Request-ID.
+const maxRequestIDLen = 128
+
+// RequestID is middleware that tags each request with an ID, taken from the
+// X-Request-ID header when it is a plausible ID and generated otherwise. The ID
+// is echoed in the response header and added to the handlers' log records.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFrom returns the request ID stored by RequestID, or "" if none.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so a client
+// cannot forge log lines through the header.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// logFor returns the handler's logger, with the request ID from ctx if any.
+func (h *Handler) logFor(ctx context.Context) *slog.Logger {
+	if id := RequestIDFrom(ctx); id != "" {
+		return h.logger.With("request_id", id)
+	}
+	return h.logger
+}
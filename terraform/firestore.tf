@@ -13,7 +13,7 @@ resource "google_firestore_database" "main" {
   depends_on = [google_project_service.firestore]
 }
 
-# Composite index for efficient queries by source and date
+# Composite index for efficient queries by source and date (GetServices)
 resource "google_firestore_index" "services_source_date" {
   database   = google_firestore_database.main.name
   collection = "services"